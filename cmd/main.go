@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,6 +12,9 @@ import (
 	config "github.com/kiriksik/TestTaskEffectiveMobile/config"
 	_ "github.com/kiriksik/TestTaskEffectiveMobile/docs"
 	handler "github.com/kiriksik/TestTaskEffectiveMobile/internal/handlers"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+	service "github.com/kiriksik/TestTaskEffectiveMobile/internal/services"
+	"github.com/kiriksik/TestTaskEffectiveMobile/migrations"
 	_ "github.com/lib/pq"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
@@ -27,12 +32,65 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed loading enviroment: %s", err)
 	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "rotate-keys" {
+		runRotateKeys(os.Args[2:])
+		return
+	}
+
+	migrateOnStart := flag.Bool("migrate-on-start", false, "apply pending migrations before serving requests")
+	flag.Parse()
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		log.Fatalf("failed to load port")
 	}
 
-	cfg := config.InitializeApiConfig()
+	cfg, err := config.InitializeApiConfig()
+	if err != nil {
+		log.Fatalf("failed to initialize storage: %s", err)
+	}
+
+	if *migrateOnStart && cfg.DB != nil {
+		err = migrations.Run(context.Background(), cfg.DB, "up", os.Stdout)
+		if err != nil {
+			log.Fatalf("failed to apply migrations: %s", err)
+		}
+	}
+
+	if cfg.PIIKeys != nil && cfg.DB != nil {
+		indexed, err := repository.IndexPlaintextNames(context.Background(), cfg.DB, cfg.PIIKeys, 500)
+		if err != nil {
+			log.Fatalf("failed to index plaintext names after %d humans: %s", indexed, err)
+		}
+		if indexed > 0 {
+			log.Printf("indexed names of %d plaintext humans", indexed)
+		}
+	}
+
+	relay, err := config.NewOutboxRelay(cfg.Transactor)
+	if err != nil {
+		log.Fatalf("failed to configure outbox: %s", err)
+	}
+	go relay.Run(context.Background())
+	dispatcher, err := config.NewWebhookDispatcher(cfg.Transactor, cfg.WebhookTargets)
+	if err != nil {
+		log.Fatalf("failed to configure webhooks: %s", err)
+	}
+	go dispatcher.Run(context.Background())
+	imports := service.UserService{ApiConfig: cfg}
+	go imports.SweepStaleImports(context.Background())
+	if listener := config.NewEventListener(cfg); listener != nil {
+		go func() {
+			if err := listener.Run(context.Background()); err != nil {
+				log.Printf("event listener stopped: %s", err)
+			}
+		}()
+	}
 
 	serveMux := handler.InitializeMux(cfg)
 	serveMux.Handle("/swagger/", httpSwagger.Handler(
@@ -50,3 +108,50 @@ func main() {
 	}
 
 }
+
+// runMigrate handles "main migrate <command>".
+func runMigrate(args []string) {
+	if len(args) != 1 {
+		log.Fatalf("usage: main migrate %v", migrations.Commands)
+	}
+	db, err := config.ConnectDB()
+	if err != nil {
+		log.Fatalf("failed to connect to database: %s", err)
+	}
+	defer db.Close()
+
+	err = migrations.Run(context.Background(), db, args[0], os.Stdout)
+	if err != nil {
+		log.Fatalf("migrate %s failed: %s", args[0], err)
+	}
+}
+
+// runRotateKeys handles "main rotate-keys [-batch-size n]".
+func runRotateKeys(args []string) {
+	flags := flag.NewFlagSet("rotate-keys", flag.ExitOnError)
+	batchSize := flags.Int("batch-size", 500, "rows re-encrypted per transaction")
+	flags.Parse(args)
+
+	keys, err := config.LoadPIIKeyring()
+	if err != nil {
+		log.Fatalf("failed to load PII keys: %s", err)
+	}
+	if keys == nil {
+		log.Fatalf("PII keys are not configured")
+	}
+	db, err := config.ConnectDB()
+	if err != nil {
+		log.Fatalf("failed to connect to database: %s", err)
+	}
+	defer db.Close()
+
+	rotated, err := repository.RotateKeys(context.Background(), db, keys, *batchSize, os.Stdout)
+	if err != nil {
+		log.Fatalf("rotate-keys failed after %d humans: %s", rotated, err)
+	}
+	payloads, err := repository.RotateEventPayloads(context.Background(), db, keys, *batchSize, os.Stdout)
+	if err != nil {
+		log.Fatalf("rotate-keys failed after %d event payloads: %s", payloads, err)
+	}
+	fmt.Printf("rotated %d humans and %d event payloads to key %q\n", rotated, payloads, keys.CurrentKeyID())
+}
@@ -6,26 +6,90 @@ import (
 	"os"
 
 	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/feed"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/pii"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/webhooks"
 )
 
 type ApiConfig struct {
-	Queries *database.Queries
+	// DB is nil when running on in-memory storage.
+	DB         *sql.DB
+	Humans     repository.HumanRepository
+	Transactor repository.Transactor
+	// TenantKeys maps API keys to the tenant they authenticate. When it
+	// is empty the tenant is read from the X-Tenant-ID header.
+	TenantKeys map[string]string
+	// Events fans committed human events out to live subscribers.
+	Events *feed.Broker
+	// WebhookTargets decides which addresses webhook URLs may point at.
+	WebhookTargets *webhooks.TargetPolicy
+	// PIIKeys encrypt personal data; nil when it is stored in plaintext.
+	PIIKeys *pii.Keyring
 }
 
-func InitializeApiConfig() *ApiConfig {
-	apiCfg := &ApiConfig{
-		Queries: initializeDBQueries(),
+func InitializeApiConfig() (*ApiConfig, error) {
+	tenantKeys, err := loadTenantKeys()
+	if err != nil {
+		return nil, err
+	}
+	events, err := newEventBroker()
+	if err != nil {
+		return nil, err
+	}
+	webhookTargets, err := loadWebhookTargets()
+	if err != nil {
+		return nil, err
+	}
+	apiCfg := &ApiConfig{TenantKeys: tenantKeys, Events: events, WebhookTargets: webhookTargets}
+	if err := initializeStorage(apiCfg); err != nil {
+		return nil, err
 	}
-	return apiCfg
+	return apiCfg, nil
 }
 
-func initializeDBQueries() *database.Queries {
-	dbURL := os.Getenv("DB_URL")
-	db, err := sql.Open("postgres", dbURL)
+// initializeStorage picks the storage from STORAGE: "memory" keeps
+// everything in process memory, anything else uses Postgres at DB_URL.
+// Personal data is encrypted when PII keys are configured.
+func initializeStorage(apiCfg *ApiConfig) error {
+	keys, err := LoadPIIKeyring()
 	if err != nil {
-		log.Printf("error in db connection: %s", err)
+		return err
+	}
+	if os.Getenv("STORAGE") == "memory" {
+		log.Printf("using in-memory storage, data will not be persisted")
+		memory := repository.NewMemoryHumanRepository()
+		memory.SetOutboxListener(func(event database.Outbox) {
+			if keys != nil {
+				payload, err := repository.OpenEventPayload(keys, event.TenantID, event.Payload)
+				if err != nil {
+					log.Printf("feed: failed to open event %s: %s", event.EventID, err)
+					return
+				}
+				event.Payload = payload
+			}
+			apiCfg.Events.Publish(feed.FromOutbox(event))
+		})
+		memory.SetErasureListener(func(erasure database.NotifyHumanErasedParams) {
+			apiCfg.Events.Forget(erasure.TenantID, erasure.HumanID)
+		})
+		apiCfg.Humans = memory
+		apiCfg.Transactor = memory
+	} else {
+		db, err := ConnectDB()
+		if err != nil {
+			return err
+		}
+		apiCfg.DB = db
+		apiCfg.Humans = repository.NewSQLHumanRepository(db)
+		apiCfg.Transactor = repository.NewSQLTransactor(db)
+	}
+	if keys == nil {
+		log.Printf("PII keys are not configured, personal data will be stored in plaintext")
 		return nil
 	}
-	dbQueries := database.New(db)
-	return dbQueries
+	apiCfg.PIIKeys = keys
+	apiCfg.Humans = repository.NewEncryptedHumanRepository(apiCfg.Humans, keys)
+	apiCfg.Transactor = repository.NewEncryptedTransactor(apiCfg.Transactor, keys)
+	return nil
 }
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DBConfig holds connection pool and startup settings read from the
+// environment. Unset variables default to 25 open and 25 idle connections,
+// a 30m connection lifetime, a 5m idle time and 10 connection attempts
+// 500ms apart, doubling up to 10s.
+type DBConfig struct {
+	URL             string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	ConnectAttempts int
+	ConnectBackoff  time.Duration
+}
+
+func loadDBConfig() (DBConfig, error) {
+	cfg := DBConfig{
+		URL:             os.Getenv("DB_URL"),
+		ConnectAttempts: 10,
+		ConnectBackoff:  500 * time.Millisecond,
+	}
+	if cfg.URL == "" {
+		return DBConfig{}, fmt.Errorf("DB_URL is not set")
+	}
+
+	var err error
+	if cfg.MaxOpenConns, err = envInt("DB_MAX_OPEN_CONNS", 25); err != nil {
+		return DBConfig{}, err
+	}
+	if cfg.MaxIdleConns, err = envInt("DB_MAX_IDLE_CONNS", 25); err != nil {
+		return DBConfig{}, err
+	}
+	if cfg.ConnMaxLifetime, err = envDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute); err != nil {
+		return DBConfig{}, err
+	}
+	if cfg.ConnMaxIdleTime, err = envDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute); err != nil {
+		return DBConfig{}, err
+	}
+	if cfg.ConnectAttempts, err = envInt("DB_CONNECT_ATTEMPTS", cfg.ConnectAttempts); err != nil {
+		return DBConfig{}, err
+	}
+	if cfg.ConnectBackoff, err = envDuration("DB_CONNECT_BACKOFF", cfg.ConnectBackoff); err != nil {
+		return DBConfig{}, err
+	}
+	if cfg.ConnectAttempts < 1 {
+		cfg.ConnectAttempts = 1
+	}
+	return cfg, nil
+}
+
+// ConnectDB opens the Postgres pool described by the environment.
+func ConnectDB() (*sql.DB, error) {
+	cfg, err := loadDBConfig()
+	if err != nil {
+		return nil, err
+	}
+	return openDB(cfg)
+}
+
+// openDB opens the pool and waits until Postgres answers a ping, backing
+// off exponentially between attempts (capped at ten seconds).
+func openDB(cfg DBConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("error in db connection: %w", err)
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	backoff := cfg.ConnectBackoff
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err = db.PingContext(ctx)
+		cancel()
+		if err == nil {
+			return db, nil
+		}
+		if attempt == cfg.ConnectAttempts {
+			db.Close()
+			return nil, fmt.Errorf("database is unreachable after %d attempts: %w", attempt, err)
+		}
+		log.Printf("database is not ready (attempt %d/%d): %s, retrying in %s", attempt, cfg.ConnectAttempts, err, backoff)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, 10*time.Second)
+	}
+}
+
+func envInt(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, value, err)
+	}
+	return n, nil
+}
+
+func envDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, value, err)
+	}
+	return d, nil
+}
+
+func envBool(key string, fallback bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: %w", key, value, err)
+	}
+	return b, nil
+}
+
+func envString(key, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return fallback
+}
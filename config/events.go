@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/feed"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+)
+
+// newEventBroker builds the broker of the live event stream. It buffers
+// the last EVENTS_REPLAY_SIZE events for subscribers that reconnect.
+func newEventBroker() (*feed.Broker, error) {
+	size, err := envInt("EVENTS_REPLAY_SIZE", feed.DefaultReplaySize)
+	if err != nil {
+		return nil, err
+	}
+	if size < 0 {
+		return nil, fmt.Errorf("EVENTS_REPLAY_SIZE must not be negative")
+	}
+	return feed.NewBroker(size), nil
+}
+
+// NewEventListener returns the listener that feeds cfg.Events from Postgres
+// notifications, or nil on in-memory storage, where the repository feeds
+// the broker itself.
+func NewEventListener(cfg *ApiConfig) *feed.Listener {
+	if cfg.DB == nil {
+		return nil
+	}
+	var events repository.EventReader = database.New(cfg.DB)
+	if cfg.PIIKeys != nil {
+		events = repository.NewEncryptedEventReader(events, cfg.PIIKeys)
+	}
+	return feed.NewListener(os.Getenv("DB_URL"), events, cfg.Events)
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/natsbus"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// newNATSPublisher connects to NATS_URL and makes sure the NATS_STREAM
+// stream captures the events published under NATS_SUBJECT_PREFIX in
+// NATS_FORMAT, keeping them for NATS_MAX_AGE. NATS_URL "embedded" runs a
+// server inside the process that keeps its streams in NATS_STORE_DIR, for
+// development.
+func newNATSPublisher() (*natsbus.Publisher, error) {
+	url := strings.TrimSpace(os.Getenv("NATS_URL"))
+	if url == "" {
+		return nil, fmt.Errorf("NATS_URL is not set")
+	}
+	prefix := envString("NATS_SUBJECT_PREFIX", natsbus.DefaultSubjectPrefix)
+	format := envString("NATS_FORMAT", natsbus.FormatJSON)
+	stream := envString("NATS_STREAM", natsbus.DefaultStream)
+	maxAge, err := envDuration("NATS_MAX_AGE", 0)
+	if err != nil {
+		return nil, err
+	}
+	if maxAge < 0 {
+		return nil, fmt.Errorf("NATS_MAX_AGE must not be negative")
+	}
+
+	options := []nats.Option{nats.Name("humans"), nats.MaxReconnects(-1)}
+	var nc *nats.Conn
+	if url == "embedded" {
+		srv, serverErr := natsbus.RunEmbeddedServer(envString("NATS_STORE_DIR", os.TempDir()))
+		if serverErr != nil {
+			return nil, serverErr
+		}
+		nc, err = natsbus.Connect(srv, options...)
+	} else {
+		nc, err = nats.Connect(url, options...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return nil, err
+	}
+	publisher, err := natsbus.NewPublisher(js, prefix, format)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := natsbus.EnsureStream(ctx, js, stream, prefix, maxAge); err != nil {
+		return nil, fmt.Errorf("failed to set up stream %s: %w", stream, err)
+	}
+	return publisher, nil
+}
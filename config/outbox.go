@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/outbox"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+)
+
+// NewOutboxRelay builds the relay that publishes change events to the sink
+// picked by OUTBOX_SINK: "log" (the default) writes them to the log, "http"
+// posts them to OUTBOX_HTTP_URL, "nats" publishes them to NATS JetStream and
+// "none" publishes nothing. With "none" the relay still deletes events
+// after OUTBOX_RETENTION, delivered or not.
+func NewOutboxRelay(transactor repository.Transactor) (*outbox.Relay, error) {
+	var sink outbox.Sink
+	switch kind := strings.TrimSpace(os.Getenv("OUTBOX_SINK")); kind {
+	case "", "log":
+		sink = outbox.LogSink{}
+	case "http":
+		url := strings.TrimSpace(os.Getenv("OUTBOX_HTTP_URL"))
+		if url == "" {
+			return nil, fmt.Errorf("OUTBOX_HTTP_URL is not set")
+		}
+		sink = outbox.NewHTTPSink(url)
+	case "nats":
+		publisher, err := newNATSPublisher()
+		if err != nil {
+			return nil, err
+		}
+		sink = publisher
+	case "none":
+	default:
+		return nil, fmt.Errorf("unknown OUTBOX_SINK %q", kind)
+	}
+
+	relay := outbox.NewRelay(transactor, sink)
+	var err error
+	if relay.BatchSize, err = envInt("OUTBOX_BATCH_SIZE", relay.BatchSize); err != nil {
+		return nil, err
+	}
+	if relay.BatchSize < 1 {
+		return nil, fmt.Errorf("OUTBOX_BATCH_SIZE must be positive")
+	}
+	if relay.PollInterval, err = envDuration("OUTBOX_POLL_INTERVAL", relay.PollInterval); err != nil {
+		return nil, err
+	}
+	if relay.PollInterval <= 0 {
+		return nil, fmt.Errorf("OUTBOX_POLL_INTERVAL must be positive")
+	}
+	if relay.MaxBackoff, err = envDuration("OUTBOX_MAX_BACKOFF", relay.MaxBackoff); err != nil {
+		return nil, err
+	}
+	if relay.Retention, err = envDuration("OUTBOX_RETENTION", relay.Retention); err != nil {
+		return nil, err
+	}
+	return relay, nil
+}
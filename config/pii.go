@@ -0,0 +1,69 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/pii"
+)
+
+// piiKeyfile is the format of the file at PII_KEYFILE. Keys are base64
+// encoded 32 byte values.
+type piiKeyfile struct {
+	Current  string            `json:"current"`
+	Keys     map[string]string `json:"keys"`
+	IndexKey string            `json:"index_key"`
+}
+
+// LoadPIIKeyring reads the keys that encrypt personal data, from the JSON
+// file at PII_KEYFILE if set, otherwise from PII_KEYS, a comma separated
+// list of id=key pairs whose first entry is the current key, and
+// PII_INDEX_KEY. It returns nil when no keys are configured, in which case
+// personal data is stored in plaintext.
+func LoadPIIKeyring() (*pii.Keyring, error) {
+	var file piiKeyfile
+	if path := strings.TrimSpace(os.Getenv("PII_KEYFILE")); path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read PII_KEYFILE: %w", err)
+		}
+		if err := json.Unmarshal(raw, &file); err != nil {
+			return nil, fmt.Errorf("invalid PII_KEYFILE: %w", err)
+		}
+	} else {
+		raw := strings.TrimSpace(os.Getenv("PII_KEYS"))
+		if raw == "" {
+			return nil, nil
+		}
+		file.Keys = make(map[string]string)
+		for _, pair := range strings.Split(raw, ",") {
+			id, key, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || id == "" {
+				return nil, fmt.Errorf("invalid PII_KEYS entry %q, expected id=key", pair)
+			}
+			if file.Current == "" {
+				file.Current = id
+			}
+			file.Keys[id] = key
+		}
+		file.IndexKey = os.Getenv("PII_INDEX_KEY")
+	}
+
+	if file.IndexKey == "" {
+		return nil, errors.New("PII index key is not set")
+	}
+	indexKey, err := pii.ParseKey(file.IndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("PII index key: %w", err)
+	}
+	keys := make(map[string][]byte, len(file.Keys))
+	for id, encoded := range file.Keys {
+		if keys[id], err = pii.ParseKey(encoded); err != nil {
+			return nil, fmt.Errorf("PII key %q: %w", id, err)
+		}
+	}
+	return pii.NewKeyring(file.Current, keys, indexKey)
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/tenant"
+)
+
+// loadTenantKeys parses TENANT_API_KEYS, a comma separated list of
+// key=tenant pairs. An empty result means tenants are not authenticated
+// and are taken from the X-Tenant-ID header instead.
+func loadTenantKeys() (map[string]string, error) {
+	keys := make(map[string]string)
+	raw := strings.TrimSpace(os.Getenv("TENANT_API_KEYS"))
+	if raw == "" {
+		return keys, nil
+	}
+	for _, pair := range strings.Split(raw, ",") {
+		key, tenantID, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || key == "" || !tenant.ValidID(tenantID) {
+			return nil, fmt.Errorf("invalid TENANT_API_KEYS entry %q, expected key=tenant", pair)
+		}
+		keys[key] = tenantID
+	}
+	return keys, nil
+}
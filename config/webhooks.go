@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/webhooks"
+)
+
+// NewWebhookDispatcher builds the dispatcher that sends webhook deliveries
+// to the addresses targets allows, tuned by WEBHOOK_TIMEOUT,
+// WEBHOOK_MAX_ATTEMPTS, WEBHOOK_DISABLE_AFTER and WEBHOOK_RETENTION.
+func NewWebhookDispatcher(transactor repository.Transactor, targets *webhooks.TargetPolicy) (*webhooks.Dispatcher, error) {
+	dispatcher := webhooks.NewDispatcher(transactor)
+	dispatcher.Targets = targets
+	var err error
+	if dispatcher.Client.Timeout, err = envDuration("WEBHOOK_TIMEOUT", dispatcher.Client.Timeout); err != nil {
+		return nil, err
+	}
+	if dispatcher.MaxAttempts, err = envInt("WEBHOOK_MAX_ATTEMPTS", dispatcher.MaxAttempts); err != nil {
+		return nil, err
+	}
+	if dispatcher.MaxAttempts < 1 {
+		return nil, fmt.Errorf("WEBHOOK_MAX_ATTEMPTS must be positive")
+	}
+	if dispatcher.DisableAfter, err = envInt("WEBHOOK_DISABLE_AFTER", dispatcher.DisableAfter); err != nil {
+		return nil, err
+	}
+	if dispatcher.DisableAfter < 1 {
+		return nil, fmt.Errorf("WEBHOOK_DISABLE_AFTER must be positive")
+	}
+	if dispatcher.Retention, err = envDuration("WEBHOOK_RETENTION", dispatcher.Retention); err != nil {
+		return nil, err
+	}
+	return dispatcher, nil
+}
+
+// loadWebhookTargets reads WEBHOOK_ALLOW_PRIVATE_NETWORKS, which lets
+// webhooks reach loopback, private and link-local addresses. It is meant
+// for development; by default only public addresses are allowed.
+func loadWebhookTargets() (*webhooks.TargetPolicy, error) {
+	allow, err := envBool("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false)
+	if err != nil {
+		return nil, err
+	}
+	return &webhooks.TargetPolicy{AllowPrivateNetworks: allow}, nil
+}
@@ -15,9 +15,120 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/attributes": {
+            "get": {
+                "description": "Возвращает реестр пользовательских атрибутов тенанта",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attributes"
+                ],
+                "summary": "Получение описаний атрибутов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AttributeDefinitionResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/attributes/{name}": {
+            "put": {
+                "description": "Задаёт тип, обязательность, допустимые значения и правила проверки атрибута. Новые правила применяются к последующим изменениям людей",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attributes"
+                ],
+                "summary": "Создание или изменение атрибута",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя атрибута",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Описание атрибута",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AttributeDefinitionRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AttributeDefinitionResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет атрибут из реестра вместе с его значениями у всех людей",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attributes"
+                ],
+                "summary": "Удаление атрибута",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя атрибута",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AttributeDefinitionResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/api/humans": {
             "get": {
-                "description": "Возвращает всех человека",
+                "description": "Возвращает всех людей, подходящих под фильтры. Фильтр по атрибутам задаётся параметрами вида attr.\u003cимя\u003e=\u003cзначение\u003e",
                 "produces": [
                     "application/json"
                 ],
@@ -25,6 +136,80 @@ const docTemplate = `{
                     "humans"
                 ],
                 "summary": "Получение списка людей",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Создан не раньше (RFC 3339)",
+                        "name": "created_since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Создан раньше (RFC 3339)",
+                        "name": "created_until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Обновлён не раньше (RFC 3339)",
+                        "name": "updated_since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Обновлён раньше (RFC 3339)",
+                        "name": "updated_until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Теги",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "default": "any",
+                        "description": "Совпадение по любому или по всем тегам",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at",
+                            "updated_at",
+                            "-updated_at"
+                        ],
+                        "type": "string",
+                        "description": "Сортировка",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "contacts"
+                        ],
+                        "type": "string",
+                        "description": "Связанные ресурсы",
+                        "name": "expand",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -38,7 +223,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Создаёт человека по полям имени, фамилии и отчества(необязательно)",
+                "description": "Создаёт человека по полям имени, фамилии и отчества(необязательно). Имя и фамилия обязательны; каждое поле — до 100 латинских или кириллических букв, части можно соединять дефисом или апострофом. При ошибках в полях возвращает 422 со списком ошибок",
                 "consumes": [
                     "application/json"
                 ],
@@ -58,6 +243,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.HumanRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -66,6 +257,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.HumanResponse"
                         }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
                     }
                 }
             },
@@ -88,6 +285,12 @@ const docTemplate = `{
                         "name": "humanID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -100,112 +303,2330 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/humans/{humanID}": {
+        "/api/humans/duplicates": {
             "get": {
-                "description": "Возвращает данные человека по его ID",
+                "description": "Возвращает пары людей, которые вероятно являются одним человеком. Оценка учитывает сходство имени и фамилии, отчество, возраст, пол и страну. При шифровании персональных данных имена сравниваются после расшифровки, поэтому поиск доступен тенантам не больше 10000 людей, для больших возвращается 409",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "humans"
+                    "duplicates"
                 ],
-                "summary": "Получение человека по ID",
+                "summary": "Поиск дубликатов",
                 "parameters": [
+                    {
+                        "type": "number",
+                        "default": 0.6,
+                        "description": "Минимальная оценка от 0 до 1",
+                        "name": "min_score",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Максимальное количество пар",
+                        "name": "limit",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "ID человека",
-                        "name": "humanID",
-                        "in": "path",
-                        "required": true
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.HumanResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DuplicateCandidate"
+                            }
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Обновляет данные человека по его ID",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/api/humans/events": {
+            "get": {
+                "description": "Server-Sent Events: событие HumanCreated, HumanUpdated или HumanDeleted на каждое изменение человека, подходящего под фильтры списка. id события можно передать в Last-Event-ID при переподключении, чтобы получить пропущенные события из буфера последних событий. Если клиент не успевает читать, поток закрывается и клиенту нужно переподключиться",
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
                     "humans"
                 ],
-                "summary": "Обновление человека",
+                "summary": "Поток изменений людей",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID человека",
-                        "name": "humanID",
-                        "in": "path",
-                        "required": true
+                        "description": "ID последнего полученного события",
+                        "name": "Last-Event-ID",
+                        "in": "header"
                     },
                     {
-                        "description": "данные человека",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.HumanRequest"
-                        }
+                        "type": "string",
+                        "description": "Создан не раньше (RFC 3339)",
+                        "name": "created_since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Создан раньше (RFC 3339)",
+                        "name": "created_until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Обновлён не раньше (RFC 3339)",
+                        "name": "updated_since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Обновлён раньше (RFC 3339)",
+                        "name": "updated_until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Теги",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "default": "any",
+                        "description": "Совпадение по любому или по всем тегам",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.HumanResponse"
+                            "$ref": "#/definitions/models.HumanEvent"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.HumanRequest": {
-            "type": "object",
-            "properties": {
-                "name": {
-                    "type": "string"
-                },
-                "patronymic": {
-                    "type": "string"
-                },
-                "surname": {
-                    "type": "string"
+        },
+        "/api/humans/export": {
+            "get": {
+                "description": "Потоково выгружает людей, подходящих под фильтры списка, в CSV, NDJSON или XLSX. Фильтр по атрибутам задаётся параметрами вида attr.\u003cимя\u003e=\u003cзначение\u003e",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Выгрузка людей",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Формат",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Колонки через запятую: id, name, surname, patronymic, age, gender, country, created_at, updated_at, attributes",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Создан не раньше (RFC 3339)",
+                        "name": "created_since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Создан раньше (RFC 3339)",
+                        "name": "created_until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Обновлён не раньше (RFC 3339)",
+                        "name": "updated_since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Обновлён раньше (RFC 3339)",
+                        "name": "updated_until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Теги",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "default": "any",
+                        "description": "Совпадение по любому или по всем тегам",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at",
+                            "updated_at",
+                            "-updated_at"
+                        ],
+                        "type": "string",
+                        "description": "Сортировка",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
                 }
             }
         },
-        "models.HumanResponse": {
-            "type": "object",
-            "properties": {
-                "age": {
-                    "type": "integer"
-                },
-                "country": {
-                    "type": "string"
-                },
-                "gender": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "patronymic": {
-                    "type": "string"
-                },
-                "surname": {
+        "/api/humans/stats": {
+            "get": {
+                "description": "Возвращает распределение по полу и странам, средний возраст по странам и гистограмму возрастов. Принимает те же фильтры, что и список людей, включая attr.\u003cимя\u003e=\u003cзначение\u003e",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Статистика по людям",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Ширина возрастного интервала",
+                        "name": "bucket_width",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Создан не раньше (RFC 3339)",
+                        "name": "created_since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Создан раньше (RFC 3339)",
+                        "name": "created_until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Обновлён не раньше (RFC 3339)",
+                        "name": "updated_since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Обновлён раньше (RFC 3339)",
+                        "name": "updated_until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Теги",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "default": "any",
+                        "description": "Совпадение по любому или по всем тегам",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HumanStatsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/humans/{humanID}": {
+            "get": {
+                "description": "Возвращает данные человека по его ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Получение человека по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID человека",
+                        "name": "humanID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "contacts"
+                        ],
+                        "type": "string",
+                        "description": "Связанные ресурсы",
+                        "name": "expand",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HumanResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Обновляет данные человека по его ID. Поля проверяются так же, как при создании; при ошибках возвращает 422 со списком ошибок",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Обновление человека",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID человека",
+                        "name": "humanID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "данные человека",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.HumanRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HumanResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/humans/{humanID}/anonymize": {
+            "post": {
+                "description": "Необратимо заменяет имя, фамилию и отчество случайными токенами, удаляет контакты и атрибуты. Возраст, пол и страна сохраняются для статистики. Удаляются также прежние события outbox и доставки вебхуков человека и события в буфере повтора потока изменений. Уже отправленное наружу не удаляется: сообщения в потоке JetStream хранятся до истечения NATS_MAX_AGE, а полученное получателями вебхуков, HTTP-приёмником и подписчиками потока остаётся у них",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gdpr"
+                ],
+                "summary": "Анонимизация человека",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID человека",
+                        "name": "humanID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HumanResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/humans/{humanID}/contacts": {
+            "get": {
+                "description": "Возвращает все контакты человека",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "contacts"
+                ],
+                "summary": "Получение контактов человека",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID человека",
+                        "name": "humanID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ContactResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Добавляет человеку email, телефон в формате E.164 или почтовый адрес",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "contacts"
+                ],
+                "summary": "Добавление контакта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID человека",
+                        "name": "humanID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные контакта",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ContactRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ContactResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/humans/{humanID}/contacts/{contactID}": {
+            "get": {
+                "description": "Возвращает контакт человека по его ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "contacts"
+                ],
+                "summary": "Получение контакта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID человека",
+                        "name": "humanID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID контакта",
+                        "name": "contactID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ContactResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Заменяет данные контакта человека",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "contacts"
+                ],
+                "summary": "Обновление контакта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID человека",
+                        "name": "humanID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID контакта",
+                        "name": "contactID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные контакта",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ContactRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ContactResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет контакт человека по его ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "contacts"
+                ],
+                "summary": "Удаление контакта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID человека",
+                        "name": "humanID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID контакта",
+                        "name": "contactID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ContactResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/humans/{humanID}/export": {
+            "get": {
+                "description": "Возвращает все хранимые данные о человеке: запись, контакты, теги, атрибуты, связи, выведенные из имени данные, а также ещё хранимые события изменений и доставки вебхуков с их телами",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gdpr"
+                ],
+                "summary": "Выгрузка данных субъекта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID человека",
+                        "name": "humanID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SubjectDataExport"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/humans/{humanID}/family-tree": {
+            "get": {
+                "description": "Возвращает всех людей, связанных с человеком не более чем через depth связей, и связи между ними",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "relationships"
+                ],
+                "summary": "Семейное дерево",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID человека",
+                        "name": "humanID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 3,
+                        "description": "Глубина обхода",
+                        "name": "depth",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.FamilyTreeResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/humans/{humanID}/merge": {
+            "post": {
+                "description": "Сливает человека source_id в человека из пути: для каждого поля выбирается победитель, контакты, теги и связи переносятся, source_id удаляется",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "duplicates"
+                ],
+                "summary": "Слияние людей",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID человека, в которого выполняется слияние",
+                        "name": "humanID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Источник слияния",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MergeRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HumanResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/humans/{humanID}/relatives": {
+            "get": {
+                "description": "Возвращает родственников человека с типом связи",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "relationships"
+                ],
+                "summary": "Получение родственников",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID человека",
+                        "name": "humanID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RelativeResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Связывает человека с родственником. Тип связи описывает, кем родственник приходится человеку: parent, child, spouse или sibling",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "relationships"
+                ],
+                "summary": "Добавление родственной связи",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID человека",
+                        "name": "humanID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные связи",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RelationshipRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RelationshipResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/humans/{humanID}/relatives/{relativeID}": {
+            "delete": {
+                "description": "Удаляет связь заданного типа между человеком и родственником, а при bidirectional=true и обратную связь",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "relationships"
+                ],
+                "summary": "Удаление родственной связи",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID человека",
+                        "name": "humanID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID родственника",
+                        "name": "relativeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "parent",
+                            "child",
+                            "spouse",
+                            "sibling"
+                        ],
+                        "type": "string",
+                        "description": "Тип связи",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Удалить и обратную связь",
+                        "name": "bidirectional",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RelationshipResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/humans/{humanID}/tags": {
+            "post": {
+                "description": "Добавляет человеку теги. Новые теги создаются автоматически",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Добавление тегов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID человека",
+                        "name": "humanID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Теги",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TagsRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HumanResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/humans/{humanID}/tags/{tag}": {
+            "delete": {
+                "description": "Снимает тег с человека",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Удаление тега",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID человека",
+                        "name": "humanID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Тег",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HumanResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/humans:batch": {
+            "post": {
+                "description": "Выполняет до 1000 операций create, update и delete. Имена обогащаются одним пакетом. В режиме atomic любая ошибка отменяет весь пакет, в режиме best_effort операции применяются независимо. Возвращает 200, если все операции выполнены, иначе 207 с результатом каждой операции",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Пакетное изменение людей",
+                "parameters": [
+                    {
+                        "description": "Операции",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/imports": {
+            "post": {
+                "description": "Принимает CSV с заголовком (name, surname, patronymic) или NDJSON с объектами людей и запускает фоновую загрузку. Каждая строка создаётся так же, как через POST /api/humans, с проверкой и обогащением. Процесс выполняет не больше 4 импортов одновременно, следующие получают 429. Импорт, прерванный остановкой процесса, помечается как failed; возобновить его нельзя, файл нужно загрузить снова",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "imports"
+                ],
+                "summary": "Импорт людей из файла",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Формат файла, по умолчанию определяется по Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Содержимое файла",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportJobResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/imports/{importID}": {
+            "get": {
+                "description": "Возвращает статус импорта, счётчики строк и ошибки по строкам",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "imports"
+                ],
+                "summary": "Состояние импорта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID импорта",
+                        "name": "importID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportJobResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks": {
+            "get": {
+                "description": "Возвращает подписки тенанта без секретов",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Получение подписок на вебхуки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Подписывает URL на события людей тенанта. Каждая доставка подписывается HMAC-SHA256 секрета от \"\u003cX-Webhook-Timestamp\u003e.\u003cтело\u003e\" в заголовке X-Webhook-Signature. Если секрет не задан, он генерируется; секрет возвращается только в этом ответе",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Создание подписки на вебхуки",
+                "parameters": [
+                    {
+                        "description": "Подписка",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks/{webhookID}": {
+            "get": {
+                "description": "Возвращает подписку по её ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Получение подписки на вебхуки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет подписку вместе с журналом её доставок",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Удаление подписки на вебхуки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Меняет заданные поля подписки. enabled=true включает подписку, отключённую после ошибок доставки, и сбрасывает счётчик ошибок",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Изменение подписки на вебхуки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменения",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookUpdateRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks/{webhookID}/deliveries": {
+            "get": {
+                "description": "Возвращает последние доставки подписки, новые первыми",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Журнал доставок вебхука",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "succeeded",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Статус доставки",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Максимальное количество доставок",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDeliveryResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks/{webhookID}/deliveries/{deliveryID}": {
+            "get": {
+                "description": "Возвращает доставку вместе с журналом всех попыток",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Доставка вебхука",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID доставки",
+                        "name": "deliveryID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDeliveryResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks/{webhookID}/deliveries/{deliveryID}/redeliver": {
+            "post": {
+                "description": "Ставит доставку в очередь на немедленную отправку с новым запасом попыток, независимо от её статуса",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Повторная доставка вебхука",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID доставки",
+                        "name": "deliveryID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDeliveryResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.Address": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "country": {
+                    "description": "Country is an ISO 3166-1 alpha-2 code.",
+                    "type": "string"
+                },
+                "postal_code": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "street": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AgeBucket": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AttributeDefinitionRequest": {
+            "type": "object",
+            "properties": {
+                "enum": {
+                    "description": "Enum lists the allowed values. Empty means any value of the type.",
+                    "type": "array",
+                    "items": {}
+                },
+                "max_length": {
+                    "description": "MaxLength limits string values, in characters.",
+                    "type": "integer"
+                },
+                "maximum": {
+                    "type": "number"
+                },
+                "minimum": {
+                    "description": "Minimum and Maximum bound number and integer values, inclusive.",
+                    "type": "number"
+                },
+                "pattern": {
+                    "description": "Pattern is a regular expression string values must match.",
+                    "type": "string"
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "description": "Type is string, number, integer, boolean or date (YYYY-MM-DD).",
+                    "type": "string"
+                }
+            }
+        },
+        "models.AttributeDefinitionResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "enum": {
+                    "type": "array",
+                    "items": {}
+                },
+                "max_length": {
+                    "type": "integer"
+                },
+                "maximum": {
+                    "type": "number"
+                },
+                "minimum": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pattern": {
+                    "type": "string"
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BatchItemResult": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code is the machine-readable error code of a failed operation, as\nin Problem.",
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Errors lists the rejected fields of a human that failed validation.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldError"
+                    }
+                },
+                "human": {
+                    "$ref": "#/definitions/models.HumanResponse"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "op": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BatchOperation": {
+            "type": "object",
+            "properties": {
+                "human": {
+                    "$ref": "#/definitions/models.HumanRequest"
+                },
+                "id": {
+                    "description": "ID is the human to update or delete.",
+                    "type": "string"
+                },
+                "op": {
+                    "description": "Op is create, update or delete.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BatchRequest": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "description": "Mode is atomic, where any failure rolls the whole batch back, or\nbest_effort, where each operation is applied on its own.",
+                    "type": "string"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchOperation"
+                    }
+                }
+            }
+        },
+        "models.BatchResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchItemResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ContactRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "$ref": "#/definitions/models.Address"
+                },
+                "primary": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "description": "Type is email, phone or address.",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Value holds the email or the phone number in E.164. It is derived\nfrom Address for postal addresses.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ContactResponse": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "$ref": "#/definitions/models.Address"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "human_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "primary": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CountryStats": {
+            "type": "object",
+            "properties": {
+                "average_age": {
+                    "type": "number"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "country": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DuplicateCandidate": {
+            "type": "object",
+            "properties": {
+                "candidate": {
+                    "$ref": "#/definitions/models.HumanResponse"
+                },
+                "human": {
+                    "$ref": "#/definitions/models.HumanResponse"
+                },
+                "name_similarity": {
+                    "type": "number"
+                },
+                "reasons": {
+                    "description": "Reasons lists the signals that contributed to the score.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "score": {
+                    "description": "Score is between 0 and 1, higher is more likely a duplicate.",
+                    "type": "number"
+                }
+            }
+        },
+        "models.EnrichmentData": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "type": "integer"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "sources": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.FamilyMember": {
+            "type": "object",
+            "properties": {
+                "depth": {
+                    "type": "integer"
+                },
+                "human": {
+                    "$ref": "#/definitions/models.HumanResponse"
+                }
+            }
+        },
+        "models.FamilyTreeResponse": {
+            "type": "object",
+            "properties": {
+                "depth": {
+                    "type": "integer"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FamilyMember"
+                    }
+                },
+                "relationships": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RelationshipResponse"
+                    }
+                },
+                "root_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code is required, too_long, invalid_characters or invalid_format.",
+                    "type": "string"
+                },
+                "field": {
+                    "description": "Field is the JSON name of the field, such as \"surname\".",
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GenderCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "gender": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HumanEvent": {
+            "type": "object",
+            "properties": {
+                "human": {
+                    "description": "Human is the state after the change, or before it for deletions.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.HumanResponse"
+                        }
+                    ]
+                },
+                "id": {
+                    "type": "string"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HumanRequest": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "description": "Attributes are custom fields checked against the tenant's attribute\ndefinitions. On update, omitting them keeps the stored ones.",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "name": {
+                    "type": "string"
+                },
+                "patronymic": {
+                    "type": "string"
+                },
+                "surname": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HumanResponse": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "type": "integer"
+                },
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "contacts": {
+                    "description": "Contacts is only filled when requested with expand=contacts.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ContactResponse"
+                    }
+                },
+                "country": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "patronymic": {
+                    "type": "string"
+                },
+                "surname": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HumanStatsResponse": {
+            "type": "object",
+            "properties": {
+                "age_buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AgeBucket"
+                    }
+                },
+                "bucket_width": {
+                    "type": "integer"
+                },
+                "by_country": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CountryStats"
+                    }
+                },
+                "by_gender": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GenderCount"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ImportJobResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_rows": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportRowError"
+                    }
+                },
+                "failed_rows": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "processed_rows": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Status is pending, running, completed or failed.",
+                    "type": "string"
+                },
+                "total_rows": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ImportRowError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Errors lists the rejected fields of an invalid_fields row.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldError"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "row": {
+                    "description": "Row is the 1-based number of the data row, not counting a CSV header.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MergeRequest": {
+            "type": "object",
+            "properties": {
+                "prefer": {
+                    "description": "Prefer picks the winner per field: \"target\" or \"source\". Fields are\nname, surname, patronymic, age, gender, country and attributes. By\ndefault the target wins, except for an empty patronymic. Attributes\nare combined, with the winner's values taking precedence.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "source_id": {
+                    "description": "SourceID is the human merged into the target and then deleted.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code is a stable machine-readable error code, such as not_found or\ninvalid_fields.",
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Errors lists the rejected fields when Code is invalid_fields.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldError"
+                    }
+                },
+                "instance": {
+                    "description": "Instance is the path of the request that failed.",
+                    "type": "string"
+                },
+                "request_id": {
+                    "description": "RequestID is also returned in the X-Request-ID header and is logged\nwith the cause of the error.",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type identifies the kind of problem; it is derived from Code.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.RelationshipRequest": {
+            "type": "object",
+            "properties": {
+                "bidirectional": {
+                    "description": "Bidirectional also records the inverse relation from the relative's side.",
+                    "type": "boolean"
+                },
+                "relative_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type is what the relative is to the human: parent, child, spouse or sibling.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.RelationshipResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "human_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "relative_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RelativeResponse": {
+            "type": "object",
+            "properties": {
+                "human": {
+                    "$ref": "#/definitions/models.HumanResponse"
+                },
+                "relationship_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SubjectDataExport": {
+            "type": "object",
+            "properties": {
+                "enrichment": {
+                    "$ref": "#/definitions/models.EnrichmentData"
+                },
+                "events": {
+                    "description": "Events are the stored change events of the human, oldest first,\nwith the state each one carries.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SubjectEvent"
+                    }
+                },
+                "exported_at": {
+                    "type": "string"
+                },
+                "human": {
+                    "description": "Human includes the contacts, tags and custom attributes.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.HumanResponse"
+                        }
+                    ]
+                },
+                "relationships": {
+                    "description": "Relationships are the links in both directions.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RelationshipResponse"
+                    }
+                },
+                "webhook_deliveries": {
+                    "description": "WebhookDeliveries are the stored deliveries of those events to the\ntenant's webhooks, oldest first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookDeliveryResponse"
+                    }
+                }
+            }
+        },
+        "models.SubjectEvent": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TagsRequest": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "description": "Tags are lowercased; letters, digits and the characters \".\", \"_\",\n\":\" and \"-\" are allowed.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.WebhookAttemptResponse": {
+            "type": "object",
+            "properties": {
+                "attempted_at": {
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "response_status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WebhookDeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_response_status": {
+                    "type": "integer"
+                },
+                "log": {
+                    "description": "Log lists every attempt, oldest first. It is only returned for a\nsingle delivery.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookAttemptResponse"
+                    }
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "status": {
+                    "description": "Status is pending, succeeded or failed.",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookRequest": {
+            "type": "object",
+            "properties": {
+                "event_types": {
+                    "description": "EventTypes lists the events to deliver. Empty means every event.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "description": "Secret signs deliveries. A random secret is generated when empty.",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookResponse": {
+            "type": "object",
+            "properties": {
+                "consecutive_failures": {
+                    "description": "ConsecutiveFailures counts deliveries in a row that ran out of\nattempts.",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled_at": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "Secret is only returned when the subscription is created.",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "description": "Enabled re-enables a disabled subscription when true.",
+                    "type": "boolean"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
                     "type": "string"
                 }
             }
@@ -215,12 +2636,12 @@ const docTemplate = `{
 
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
-	Version:          "",
-	Host:             "",
-	BasePath:         "",
-	Schemes:          []string{},
-	Title:            "",
-	Description:      "",
+	Version:          "1.0",
+	Host:             "localhost:8080",
+	BasePath:         "/",
+	Schemes:          []string{"http"},
+	Title:            "Humans API",
+	Description:      "API для управления людьми",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
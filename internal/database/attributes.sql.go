@@ -0,0 +1,155 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: attributes.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+const deleteAttributeDefinition = `-- name: DeleteAttributeDefinition :one
+DELETE FROM attribute_definitions
+WHERE tenant_id = $1 AND name = $2
+RETURNING tenant_id, name, type, required, enum_values, pattern, max_length, minimum, maximum, created_at, updated_at
+`
+
+type DeleteAttributeDefinitionParams struct {
+	TenantID string `json:"tenant_id"`
+	Name     string `json:"name"`
+}
+
+func (q *Queries) DeleteAttributeDefinition(ctx context.Context, arg DeleteAttributeDefinitionParams) (AttributeDefinition, error) {
+	row := q.db.QueryRowContext(ctx, deleteAttributeDefinition, arg.TenantID, arg.Name)
+	var i AttributeDefinition
+	err := row.Scan(
+		&i.TenantID,
+		&i.Name,
+		&i.Type,
+		&i.Required,
+		&i.EnumValues,
+		&i.Pattern,
+		&i.MaxLength,
+		&i.Minimum,
+		&i.Maximum,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listAttributeDefinitions = `-- name: ListAttributeDefinitions :many
+SELECT tenant_id, name, type, required, enum_values, pattern, max_length, minimum, maximum, created_at, updated_at FROM attribute_definitions
+WHERE tenant_id = $1
+ORDER BY name
+`
+
+func (q *Queries) ListAttributeDefinitions(ctx context.Context, tenantID string) ([]AttributeDefinition, error) {
+	rows, err := q.db.QueryContext(ctx, listAttributeDefinitions, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AttributeDefinition
+	for rows.Next() {
+		var i AttributeDefinition
+		if err := rows.Scan(
+			&i.TenantID,
+			&i.Name,
+			&i.Type,
+			&i.Required,
+			&i.EnumValues,
+			&i.Pattern,
+			&i.MaxLength,
+			&i.Minimum,
+			&i.Maximum,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeHumanAttribute = `-- name: RemoveHumanAttribute :exec
+UPDATE humans
+SET attributes = attributes - $1::text
+WHERE tenant_id = $2 AND attributes ? $1::text
+`
+
+type RemoveHumanAttributeParams struct {
+	Name     string `json:"name"`
+	TenantID string `json:"tenant_id"`
+}
+
+func (q *Queries) RemoveHumanAttribute(ctx context.Context, arg RemoveHumanAttributeParams) error {
+	_, err := q.db.ExecContext(ctx, removeHumanAttribute, arg.Name, arg.TenantID)
+	return err
+}
+
+const upsertAttributeDefinition = `-- name: UpsertAttributeDefinition :one
+INSERT INTO attribute_definitions (tenant_id, name, type, required, enum_values, pattern, max_length, minimum, maximum, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+ON CONFLICT (tenant_id, name) DO UPDATE
+SET type = EXCLUDED.type,
+    required = EXCLUDED.required,
+    enum_values = EXCLUDED.enum_values,
+    pattern = EXCLUDED.pattern,
+    max_length = EXCLUDED.max_length,
+    minimum = EXCLUDED.minimum,
+    maximum = EXCLUDED.maximum,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING tenant_id, name, type, required, enum_values, pattern, max_length, minimum, maximum, created_at, updated_at
+`
+
+type UpsertAttributeDefinitionParams struct {
+	TenantID   string          `json:"tenant_id"`
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	Required   bool            `json:"required"`
+	EnumValues json.RawMessage `json:"enum_values"`
+	Pattern    sql.NullString  `json:"pattern"`
+	MaxLength  sql.NullInt32   `json:"max_length"`
+	Minimum    sql.NullFloat64 `json:"minimum"`
+	Maximum    sql.NullFloat64 `json:"maximum"`
+}
+
+func (q *Queries) UpsertAttributeDefinition(ctx context.Context, arg UpsertAttributeDefinitionParams) (AttributeDefinition, error) {
+	row := q.db.QueryRowContext(ctx, upsertAttributeDefinition,
+		arg.TenantID,
+		arg.Name,
+		arg.Type,
+		arg.Required,
+		arg.EnumValues,
+		arg.Pattern,
+		arg.MaxLength,
+		arg.Minimum,
+		arg.Maximum,
+	)
+	var i AttributeDefinition
+	err := row.Scan(
+		&i.TenantID,
+		&i.Name,
+		&i.Type,
+		&i.Required,
+		&i.EnumValues,
+		&i.Pattern,
+		&i.MaxLength,
+		&i.Minimum,
+		&i.Maximum,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,335 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: contacts.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const clearPrimaryContact = `-- name: ClearPrimaryContact :exec
+UPDATE human_contacts
+SET is_primary = false, updated_at = CURRENT_TIMESTAMP
+WHERE tenant_id = $1 AND human_id = $2 AND type = $3 AND is_primary AND id <> $4
+`
+
+type ClearPrimaryContactParams struct {
+	TenantID string    `json:"tenant_id"`
+	HumanID  uuid.UUID `json:"human_id"`
+	Type     string    `json:"type"`
+	ID       uuid.UUID `json:"id"`
+}
+
+func (q *Queries) ClearPrimaryContact(ctx context.Context, arg ClearPrimaryContactParams) error {
+	_, err := q.db.ExecContext(ctx, clearPrimaryContact,
+		arg.TenantID,
+		arg.HumanID,
+		arg.Type,
+		arg.ID,
+	)
+	return err
+}
+
+const createContact = `-- name: CreateContact :one
+INSERT INTO human_contacts (
+    id, tenant_id, human_id, type, value, is_primary,
+    street, city, region, postal_code, country, created_at, updated_at
+)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    CURRENT_TIMESTAMP,
+    CURRENT_TIMESTAMP
+) RETURNING id, tenant_id, human_id, type, value, is_primary, street, city, region, postal_code, country, created_at, updated_at
+`
+
+type CreateContactParams struct {
+	TenantID   string         `json:"tenant_id"`
+	HumanID    uuid.UUID      `json:"human_id"`
+	Type       string         `json:"type"`
+	Value      string         `json:"value"`
+	IsPrimary  bool           `json:"is_primary"`
+	Street     sql.NullString `json:"street"`
+	City       sql.NullString `json:"city"`
+	Region     sql.NullString `json:"region"`
+	PostalCode sql.NullString `json:"postal_code"`
+	Country    sql.NullString `json:"country"`
+}
+
+func (q *Queries) CreateContact(ctx context.Context, arg CreateContactParams) (HumanContact, error) {
+	row := q.db.QueryRowContext(ctx, createContact,
+		arg.TenantID,
+		arg.HumanID,
+		arg.Type,
+		arg.Value,
+		arg.IsPrimary,
+		arg.Street,
+		arg.City,
+		arg.Region,
+		arg.PostalCode,
+		arg.Country,
+	)
+	var i HumanContact
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.HumanID,
+		&i.Type,
+		&i.Value,
+		&i.IsPrimary,
+		&i.Street,
+		&i.City,
+		&i.Region,
+		&i.PostalCode,
+		&i.Country,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteContact = `-- name: DeleteContact :one
+DELETE FROM human_contacts
+WHERE tenant_id = $1 AND human_id = $2 AND id = $3
+RETURNING id, tenant_id, human_id, type, value, is_primary, street, city, region, postal_code, country, created_at, updated_at
+`
+
+type DeleteContactParams struct {
+	TenantID string    `json:"tenant_id"`
+	HumanID  uuid.UUID `json:"human_id"`
+	ID       uuid.UUID `json:"id"`
+}
+
+func (q *Queries) DeleteContact(ctx context.Context, arg DeleteContactParams) (HumanContact, error) {
+	row := q.db.QueryRowContext(ctx, deleteContact, arg.TenantID, arg.HumanID, arg.ID)
+	var i HumanContact
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.HumanID,
+		&i.Type,
+		&i.Value,
+		&i.IsPrimary,
+		&i.Street,
+		&i.City,
+		&i.Region,
+		&i.PostalCode,
+		&i.Country,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteContactsOfHuman = `-- name: DeleteContactsOfHuman :exec
+DELETE FROM human_contacts
+WHERE tenant_id = $1 AND human_id = $2
+`
+
+type DeleteContactsOfHumanParams struct {
+	TenantID string    `json:"tenant_id"`
+	HumanID  uuid.UUID `json:"human_id"`
+}
+
+func (q *Queries) DeleteContactsOfHuman(ctx context.Context, arg DeleteContactsOfHumanParams) error {
+	_, err := q.db.ExecContext(ctx, deleteContactsOfHuman, arg.TenantID, arg.HumanID)
+	return err
+}
+
+const getContact = `-- name: GetContact :one
+SELECT id, tenant_id, human_id, type, value, is_primary, street, city, region, postal_code, country, created_at, updated_at FROM human_contacts
+WHERE tenant_id = $1 AND human_id = $2 AND id = $3
+`
+
+type GetContactParams struct {
+	TenantID string    `json:"tenant_id"`
+	HumanID  uuid.UUID `json:"human_id"`
+	ID       uuid.UUID `json:"id"`
+}
+
+func (q *Queries) GetContact(ctx context.Context, arg GetContactParams) (HumanContact, error) {
+	row := q.db.QueryRowContext(ctx, getContact, arg.TenantID, arg.HumanID, arg.ID)
+	var i HumanContact
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.HumanID,
+		&i.Type,
+		&i.Value,
+		&i.IsPrimary,
+		&i.Street,
+		&i.City,
+		&i.Region,
+		&i.PostalCode,
+		&i.Country,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listContacts = `-- name: ListContacts :many
+SELECT id, tenant_id, human_id, type, value, is_primary, street, city, region, postal_code, country, created_at, updated_at FROM human_contacts
+WHERE tenant_id = $1 AND human_id = $2
+ORDER BY type, is_primary DESC, created_at, id
+`
+
+type ListContactsParams struct {
+	TenantID string    `json:"tenant_id"`
+	HumanID  uuid.UUID `json:"human_id"`
+}
+
+func (q *Queries) ListContacts(ctx context.Context, arg ListContactsParams) ([]HumanContact, error) {
+	rows, err := q.db.QueryContext(ctx, listContacts, arg.TenantID, arg.HumanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HumanContact
+	for rows.Next() {
+		var i HumanContact
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.HumanID,
+			&i.Type,
+			&i.Value,
+			&i.IsPrimary,
+			&i.Street,
+			&i.City,
+			&i.Region,
+			&i.PostalCode,
+			&i.Country,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listContactsByHumanIDs = `-- name: ListContactsByHumanIDs :many
+SELECT id, tenant_id, human_id, type, value, is_primary, street, city, region, postal_code, country, created_at, updated_at FROM human_contacts
+WHERE tenant_id = $1 AND human_id = ANY($2::uuid[])
+ORDER BY human_id, type, is_primary DESC, created_at, id
+`
+
+type ListContactsByHumanIDsParams struct {
+	TenantID string      `json:"tenant_id"`
+	HumanIds []uuid.UUID `json:"human_ids"`
+}
+
+func (q *Queries) ListContactsByHumanIDs(ctx context.Context, arg ListContactsByHumanIDsParams) ([]HumanContact, error) {
+	rows, err := q.db.QueryContext(ctx, listContactsByHumanIDs, arg.TenantID, pq.Array(arg.HumanIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HumanContact
+	for rows.Next() {
+		var i HumanContact
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.HumanID,
+			&i.Type,
+			&i.Value,
+			&i.IsPrimary,
+			&i.Street,
+			&i.City,
+			&i.Region,
+			&i.PostalCode,
+			&i.Country,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateContact = `-- name: UpdateContact :one
+UPDATE human_contacts
+SET type = $4, value = $5, is_primary = $6, street = $7, city = $8, region = $9,
+    postal_code = $10, country = $11, updated_at = CURRENT_TIMESTAMP
+WHERE tenant_id = $1 AND human_id = $2 AND id = $3
+RETURNING id, tenant_id, human_id, type, value, is_primary, street, city, region, postal_code, country, created_at, updated_at
+`
+
+type UpdateContactParams struct {
+	TenantID   string         `json:"tenant_id"`
+	HumanID    uuid.UUID      `json:"human_id"`
+	ID         uuid.UUID      `json:"id"`
+	Type       string         `json:"type"`
+	Value      string         `json:"value"`
+	IsPrimary  bool           `json:"is_primary"`
+	Street     sql.NullString `json:"street"`
+	City       sql.NullString `json:"city"`
+	Region     sql.NullString `json:"region"`
+	PostalCode sql.NullString `json:"postal_code"`
+	Country    sql.NullString `json:"country"`
+}
+
+func (q *Queries) UpdateContact(ctx context.Context, arg UpdateContactParams) (HumanContact, error) {
+	row := q.db.QueryRowContext(ctx, updateContact,
+		arg.TenantID,
+		arg.HumanID,
+		arg.ID,
+		arg.Type,
+		arg.Value,
+		arg.IsPrimary,
+		arg.Street,
+		arg.City,
+		arg.Region,
+		arg.PostalCode,
+		arg.Country,
+	)
+	var i HumanContact
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.HumanID,
+		&i.Type,
+		&i.Value,
+		&i.IsPrimary,
+		&i.Street,
+		&i.City,
+		&i.Region,
+		&i.PostalCode,
+		&i.Country,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// This file is maintained by hand. It reuses the generated ListHumans query
+// and must be kept in step with its arguments and the humans columns.
+
+// humansCursorFetchSize is the number of rows fetched from the cursor at a
+// time.
+const humansCursorFetchSize = 500
+
+// StreamHumans runs the ListHumans query through a server-side cursor and
+// passes the rows to fn one at a time, so memory use does not grow with the
+// result. Cursors only live inside a transaction, so q must be bound to one.
+func (q *Queries) StreamHumans(ctx context.Context, arg ListHumansParams, fn func(Human) error) error {
+	_, err := q.db.ExecContext(ctx, "DECLARE humans_cursor NO SCROLL CURSOR FOR "+listHumans,
+		arg.TenantID,
+		arg.CreatedSince,
+		arg.CreatedUntil,
+		arg.UpdatedSince,
+		arg.UpdatedUntil,
+		pq.Array(arg.Tags),
+		arg.MatchAllTags,
+		arg.Attributes,
+		arg.Sort,
+	)
+	if err != nil {
+		return err
+	}
+	defer q.db.ExecContext(ctx, "CLOSE humans_cursor")
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM humans_cursor", humansCursorFetchSize)
+	for {
+		fetched, err := q.fetchHumans(ctx, fetch, fn)
+		if err != nil {
+			return err
+		}
+		if fetched < humansCursorFetchSize {
+			return nil
+		}
+	}
+}
+
+func (q *Queries) fetchHumans(ctx context.Context, fetch string, fn func(Human) error) (int, error) {
+	rows, err := q.db.QueryContext(ctx, fetch)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	fetched := 0
+	for rows.Next() {
+		var i Human
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Surname,
+			&i.Patronymic,
+			&i.Age,
+			&i.Gender,
+			&i.Country,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TenantID,
+			&i.Attributes,
+			&i.NameIndex,
+			&i.PiiKeyID,
+			&i.PiiDek,
+		); err != nil {
+			return fetched, err
+		}
+		fetched++
+		if err := fn(i); err != nil {
+			return fetched, err
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return fetched, err
+	}
+	return fetched, rows.Err()
+}
@@ -0,0 +1,141 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: duplicates.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const copyHumanTags = `-- name: CopyHumanTags :exec
+INSERT INTO human_tags (tenant_id, human_id, tag_id, created_at)
+SELECT human_tags.tenant_id, $1, human_tags.tag_id, human_tags.created_at
+FROM human_tags
+WHERE human_tags.tenant_id = $2 AND human_tags.human_id = $3
+ON CONFLICT DO NOTHING
+`
+
+type CopyHumanTagsParams struct {
+	TargetID uuid.UUID `json:"target_id"`
+	TenantID string    `json:"tenant_id"`
+	SourceID uuid.UUID `json:"source_id"`
+}
+
+func (q *Queries) CopyHumanTags(ctx context.Context, arg CopyHumanTagsParams) error {
+	_, err := q.db.ExecContext(ctx, copyHumanTags, arg.TargetID, arg.TenantID, arg.SourceID)
+	return err
+}
+
+const listDuplicateCandidates = `-- name: ListDuplicateCandidates :many
+SELECT
+    a.id AS human_id,
+    b.id AS candidate_id,
+    similarity(lower(a.name || ' ' || a.surname), lower(b.name || ' ' || b.surname))::float8 AS name_similarity
+FROM humans a
+JOIN humans b ON b.tenant_id = a.tenant_id AND b.id > a.id
+WHERE a.tenant_id = $1
+    AND a.pii_key_id IS NULL AND b.pii_key_id IS NULL
+    AND lower(a.name || ' ' || a.surname) % lower(b.name || ' ' || b.surname)
+ORDER BY name_similarity DESC, a.id, b.id
+LIMIT $2
+`
+
+type ListDuplicateCandidatesParams struct {
+	TenantID string `json:"tenant_id"`
+	MaxPairs int32  `json:"max_pairs"`
+}
+
+type ListDuplicateCandidatesRow struct {
+	HumanID        uuid.UUID `json:"human_id"`
+	CandidateID    uuid.UUID `json:"candidate_id"`
+	NameSimilarity float64   `json:"name_similarity"`
+}
+
+// Pairs whose full names pass the pg_trgm similarity threshold, each pair
+// once with the smaller id first. Encrypted rows are compared in process
+// instead.
+func (q *Queries) ListDuplicateCandidates(ctx context.Context, arg ListDuplicateCandidatesParams) ([]ListDuplicateCandidatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDuplicateCandidates, arg.TenantID, arg.MaxPairs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDuplicateCandidatesRow
+	for rows.Next() {
+		var i ListDuplicateCandidatesRow
+		if err := rows.Scan(&i.HumanID, &i.CandidateID, &i.NameSimilarity); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const moveContacts = `-- name: MoveContacts :exec
+UPDATE human_contacts c
+SET human_id = $1,
+    is_primary = c.is_primary AND NOT EXISTS (
+        SELECT 1 FROM human_contacts t
+        WHERE t.tenant_id = c.tenant_id AND t.human_id = $1
+            AND t.type = c.type AND t.is_primary
+    ),
+    updated_at = CURRENT_TIMESTAMP
+WHERE c.tenant_id = $2 AND c.human_id = $3
+    AND NOT EXISTS (
+        SELECT 1 FROM human_contacts t
+        WHERE t.tenant_id = c.tenant_id AND t.human_id = $1
+            AND t.type = c.type AND t.value = c.value
+    )
+`
+
+type MoveContactsParams struct {
+	TargetID uuid.UUID `json:"target_id"`
+	TenantID string    `json:"tenant_id"`
+	SourceID uuid.UUID `json:"source_id"`
+}
+
+// Contacts the target already has stay behind and go away with the source.
+// A moved contact stays primary only if the target has no primary of its
+// type.
+func (q *Queries) MoveContacts(ctx context.Context, arg MoveContactsParams) error {
+	_, err := q.db.ExecContext(ctx, moveContacts, arg.TargetID, arg.TenantID, arg.SourceID)
+	return err
+}
+
+const moveRelationships = `-- name: MoveRelationships :exec
+UPDATE human_relationships r
+SET human_id = CASE WHEN r.human_id = $1 THEN $2 ELSE r.human_id END,
+    relative_id = CASE WHEN r.relative_id = $1 THEN $2 ELSE r.relative_id END
+WHERE r.tenant_id = $3
+    AND (r.human_id = $1 OR r.relative_id = $1)
+    AND r.human_id <> $2 AND r.relative_id <> $2
+    AND NOT EXISTS (
+        SELECT 1 FROM human_relationships t
+        WHERE t.tenant_id = r.tenant_id AND t.relation = r.relation
+            AND t.human_id = CASE WHEN r.human_id = $1 THEN $2 ELSE r.human_id END
+            AND t.relative_id = CASE WHEN r.relative_id = $1 THEN $2 ELSE r.relative_id END
+    )
+`
+
+type MoveRelationshipsParams struct {
+	SourceID uuid.UUID `json:"source_id"`
+	TargetID uuid.UUID `json:"target_id"`
+	TenantID string    `json:"tenant_id"`
+}
+
+// Links between the source and the target, and links the target already
+// has, stay behind and go away with the source.
+func (q *Queries) MoveRelationships(ctx context.Context, arg MoveRelationshipsParams) error {
+	_, err := q.db.ExecContext(ctx, moveRelationships, arg.SourceID, arg.TargetID, arg.TenantID)
+	return err
+}
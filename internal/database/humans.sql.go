@@ -8,12 +8,203 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const countHumansByAgeBucket = `-- name: CountHumansByAgeBucket :many
+SELECT ((humans.age / $1::int) * $1::int)::int AS bucket_start, count(*) AS total FROM humans
+WHERE humans.tenant_id = $2
+    AND ($3::timestamptz IS NULL OR humans.created_at >= $3)
+    AND ($4::timestamptz IS NULL OR humans.created_at < $4)
+    AND ($5::timestamptz IS NULL OR humans.updated_at >= $5)
+    AND ($6::timestamptz IS NULL OR humans.updated_at < $6)
+    AND human_tags_match(humans.tenant_id, humans.id, $7::text[], $8::bool)
+    AND humans.attributes @> $9::jsonb
+GROUP BY bucket_start
+ORDER BY bucket_start
+`
+
+type CountHumansByAgeBucketParams struct {
+	BucketWidth  int32           `json:"bucket_width"`
+	TenantID     string          `json:"tenant_id"`
+	CreatedSince sql.NullTime    `json:"created_since"`
+	CreatedUntil sql.NullTime    `json:"created_until"`
+	UpdatedSince sql.NullTime    `json:"updated_since"`
+	UpdatedUntil sql.NullTime    `json:"updated_until"`
+	Tags         []string        `json:"tags"`
+	MatchAllTags bool            `json:"match_all_tags"`
+	Attributes   json.RawMessage `json:"attributes"`
+}
+
+type CountHumansByAgeBucketRow struct {
+	BucketStart int32 `json:"bucket_start"`
+	Total       int64 `json:"total"`
+}
+
+func (q *Queries) CountHumansByAgeBucket(ctx context.Context, arg CountHumansByAgeBucketParams) ([]CountHumansByAgeBucketRow, error) {
+	rows, err := q.db.QueryContext(ctx, countHumansByAgeBucket,
+		arg.BucketWidth,
+		arg.TenantID,
+		arg.CreatedSince,
+		arg.CreatedUntil,
+		arg.UpdatedSince,
+		arg.UpdatedUntil,
+		pq.Array(arg.Tags),
+		arg.MatchAllTags,
+		arg.Attributes,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountHumansByAgeBucketRow
+	for rows.Next() {
+		var i CountHumansByAgeBucketRow
+		if err := rows.Scan(&i.BucketStart, &i.Total); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countHumansByCountry = `-- name: CountHumansByCountry :many
+SELECT humans.country, count(*) AS total, avg(humans.age)::float8 AS average_age FROM humans
+WHERE humans.tenant_id = $1
+    AND ($2::timestamptz IS NULL OR humans.created_at >= $2)
+    AND ($3::timestamptz IS NULL OR humans.created_at < $3)
+    AND ($4::timestamptz IS NULL OR humans.updated_at >= $4)
+    AND ($5::timestamptz IS NULL OR humans.updated_at < $5)
+    AND human_tags_match(humans.tenant_id, humans.id, $6::text[], $7::bool)
+    AND humans.attributes @> $8::jsonb
+GROUP BY humans.country
+ORDER BY total DESC, humans.country
+`
+
+type CountHumansByCountryParams struct {
+	TenantID     string          `json:"tenant_id"`
+	CreatedSince sql.NullTime    `json:"created_since"`
+	CreatedUntil sql.NullTime    `json:"created_until"`
+	UpdatedSince sql.NullTime    `json:"updated_since"`
+	UpdatedUntil sql.NullTime    `json:"updated_until"`
+	Tags         []string        `json:"tags"`
+	MatchAllTags bool            `json:"match_all_tags"`
+	Attributes   json.RawMessage `json:"attributes"`
+}
+
+type CountHumansByCountryRow struct {
+	Country    string  `json:"country"`
+	Total      int64   `json:"total"`
+	AverageAge float64 `json:"average_age"`
+}
+
+func (q *Queries) CountHumansByCountry(ctx context.Context, arg CountHumansByCountryParams) ([]CountHumansByCountryRow, error) {
+	rows, err := q.db.QueryContext(ctx, countHumansByCountry,
+		arg.TenantID,
+		arg.CreatedSince,
+		arg.CreatedUntil,
+		arg.UpdatedSince,
+		arg.UpdatedUntil,
+		pq.Array(arg.Tags),
+		arg.MatchAllTags,
+		arg.Attributes,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountHumansByCountryRow
+	for rows.Next() {
+		var i CountHumansByCountryRow
+		if err := rows.Scan(&i.Country, &i.Total, &i.AverageAge); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countHumansByGender = `-- name: CountHumansByGender :many
+SELECT humans.gender, count(*) AS total FROM humans
+WHERE humans.tenant_id = $1
+    AND ($2::timestamptz IS NULL OR humans.created_at >= $2)
+    AND ($3::timestamptz IS NULL OR humans.created_at < $3)
+    AND ($4::timestamptz IS NULL OR humans.updated_at >= $4)
+    AND ($5::timestamptz IS NULL OR humans.updated_at < $5)
+    AND human_tags_match(humans.tenant_id, humans.id, $6::text[], $7::bool)
+    AND humans.attributes @> $8::jsonb
+GROUP BY humans.gender
+ORDER BY humans.gender
+`
+
+type CountHumansByGenderParams struct {
+	TenantID     string          `json:"tenant_id"`
+	CreatedSince sql.NullTime    `json:"created_since"`
+	CreatedUntil sql.NullTime    `json:"created_until"`
+	UpdatedSince sql.NullTime    `json:"updated_since"`
+	UpdatedUntil sql.NullTime    `json:"updated_until"`
+	Tags         []string        `json:"tags"`
+	MatchAllTags bool            `json:"match_all_tags"`
+	Attributes   json.RawMessage `json:"attributes"`
+}
+
+type CountHumansByGenderRow struct {
+	Gender string `json:"gender"`
+	Total  int64  `json:"total"`
+}
+
+func (q *Queries) CountHumansByGender(ctx context.Context, arg CountHumansByGenderParams) ([]CountHumansByGenderRow, error) {
+	rows, err := q.db.QueryContext(ctx, countHumansByGender,
+		arg.TenantID,
+		arg.CreatedSince,
+		arg.CreatedUntil,
+		arg.UpdatedSince,
+		arg.UpdatedUntil,
+		pq.Array(arg.Tags),
+		arg.MatchAllTags,
+		arg.Attributes,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountHumansByGenderRow
+	for rows.Next() {
+		var i CountHumansByGenderRow
+		if err := rows.Scan(&i.Gender, &i.Total); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createHuman = `-- name: CreateHuman :one
-INSERT INTO humans (id, name, surname, patronymic, age, gender, country, created_at)
+INSERT INTO humans (
+    id, tenant_id, name, surname, patronymic, age, gender, country, attributes,
+    name_index, pii_key_id, pii_dek, created_at, updated_at
+)
 VALUES (
     gen_random_uuid(),
     $1,
@@ -22,27 +213,43 @@ VALUES (
     $4,
     $5,
     $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11,
+    CURRENT_TIMESTAMP,
     CURRENT_TIMESTAMP
-) RETURNING id, name, surname, patronymic, age, gender, country, created_at
+) RETURNING id, name, surname, patronymic, age, gender, country, created_at, updated_at, tenant_id, attributes, name_index, pii_key_id, pii_dek
 `
 
 type CreateHumanParams struct {
-	Name       string         `json:"name"`
-	Surname    string         `json:"surname"`
-	Patronymic sql.NullString `json:"patronymic"`
-	Age        int32          `json:"age"`
-	Gender     string         `json:"gender"`
-	Country    string         `json:"country"`
+	TenantID   string          `json:"tenant_id"`
+	Name       string          `json:"name"`
+	Surname    string          `json:"surname"`
+	Patronymic sql.NullString  `json:"patronymic"`
+	Age        int32           `json:"age"`
+	Gender     string          `json:"gender"`
+	Country    string          `json:"country"`
+	Attributes json.RawMessage `json:"attributes"`
+	NameIndex  []byte          `json:"name_index"`
+	PiiKeyID   sql.NullString  `json:"pii_key_id"`
+	PiiDek     []byte          `json:"pii_dek"`
 }
 
 func (q *Queries) CreateHuman(ctx context.Context, arg CreateHumanParams) (Human, error) {
 	row := q.db.QueryRowContext(ctx, createHuman,
+		arg.TenantID,
 		arg.Name,
 		arg.Surname,
 		arg.Patronymic,
 		arg.Age,
 		arg.Gender,
 		arg.Country,
+		arg.Attributes,
+		arg.NameIndex,
+		arg.PiiKeyID,
+		arg.PiiDek,
 	)
 	var i Human
 	err := row.Scan(
@@ -54,18 +261,29 @@ func (q *Queries) CreateHuman(ctx context.Context, arg CreateHumanParams) (Human
 		&i.Gender,
 		&i.Country,
 		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TenantID,
+		&i.Attributes,
+		&i.NameIndex,
+		&i.PiiKeyID,
+		&i.PiiDek,
 	)
 	return i, err
 }
 
 const deleteHuman = `-- name: DeleteHuman :one
 DELETE FROM humans
-WHERE id = $1
-RETURNING id, name, surname, patronymic, age, gender, country, created_at
+WHERE tenant_id = $1 AND id = $2
+RETURNING id, name, surname, patronymic, age, gender, country, created_at, updated_at, tenant_id, attributes, name_index, pii_key_id, pii_dek
 `
 
-func (q *Queries) DeleteHuman(ctx context.Context, id uuid.UUID) (Human, error) {
-	row := q.db.QueryRowContext(ctx, deleteHuman, id)
+type DeleteHumanParams struct {
+	TenantID string    `json:"tenant_id"`
+	ID       uuid.UUID `json:"id"`
+}
+
+func (q *Queries) DeleteHuman(ctx context.Context, arg DeleteHumanParams) (Human, error) {
+	row := q.db.QueryRowContext(ctx, deleteHuman, arg.TenantID, arg.ID)
 	var i Human
 	err := row.Scan(
 		&i.ID,
@@ -76,17 +294,28 @@ func (q *Queries) DeleteHuman(ctx context.Context, id uuid.UUID) (Human, error)
 		&i.Gender,
 		&i.Country,
 		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TenantID,
+		&i.Attributes,
+		&i.NameIndex,
+		&i.PiiKeyID,
+		&i.PiiDek,
 	)
 	return i, err
 }
 
 const getHumanByID = `-- name: GetHumanByID :one
-SELECT id, name, surname, patronymic, age, gender, country, created_at FROM humans 
-WHERE id = $1
+SELECT id, name, surname, patronymic, age, gender, country, created_at, updated_at, tenant_id, attributes, name_index, pii_key_id, pii_dek FROM humans
+WHERE tenant_id = $1 AND id = $2
 `
 
-func (q *Queries) GetHumanByID(ctx context.Context, id uuid.UUID) (Human, error) {
-	row := q.db.QueryRowContext(ctx, getHumanByID, id)
+type GetHumanByIDParams struct {
+	TenantID string    `json:"tenant_id"`
+	ID       uuid.UUID `json:"id"`
+}
+
+func (q *Queries) GetHumanByID(ctx context.Context, arg GetHumanByIDParams) (Human, error) {
+	row := q.db.QueryRowContext(ctx, getHumanByID, arg.TenantID, arg.ID)
 	var i Human
 	err := row.Scan(
 		&i.ID,
@@ -97,16 +326,106 @@ func (q *Queries) GetHumanByID(ctx context.Context, id uuid.UUID) (Human, error)
 		&i.Gender,
 		&i.Country,
 		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TenantID,
+		&i.Attributes,
+		&i.NameIndex,
+		&i.PiiKeyID,
+		&i.PiiDek,
 	)
 	return i, err
 }
 
-const getHumans = `-- name: GetHumans :many
-SELECT id, name, surname, patronymic, age, gender, country, created_at FROM humans
+const listHumans = `-- name: ListHumans :many
+SELECT id, name, surname, patronymic, age, gender, country, created_at, updated_at, tenant_id, attributes, name_index, pii_key_id, pii_dek FROM humans
+WHERE humans.tenant_id = $1
+    AND ($2::timestamptz IS NULL OR humans.created_at >= $2)
+    AND ($3::timestamptz IS NULL OR humans.created_at < $3)
+    AND ($4::timestamptz IS NULL OR humans.updated_at >= $4)
+    AND ($5::timestamptz IS NULL OR humans.updated_at < $5)
+    AND human_tags_match(humans.tenant_id, humans.id, $6::text[], $7::bool)
+    AND humans.attributes @> $8::jsonb
+ORDER BY
+    CASE WHEN $9::text = 'created_at' THEN humans.created_at END ASC,
+    CASE WHEN $9::text = '-created_at' THEN humans.created_at END DESC,
+    CASE WHEN $9::text = 'updated_at' THEN humans.updated_at END ASC,
+    CASE WHEN $9::text = '-updated_at' THEN humans.updated_at END DESC,
+    humans.id
+`
+
+type ListHumansParams struct {
+	TenantID     string          `json:"tenant_id"`
+	CreatedSince sql.NullTime    `json:"created_since"`
+	CreatedUntil sql.NullTime    `json:"created_until"`
+	UpdatedSince sql.NullTime    `json:"updated_since"`
+	UpdatedUntil sql.NullTime    `json:"updated_until"`
+	Tags         []string        `json:"tags"`
+	MatchAllTags bool            `json:"match_all_tags"`
+	Attributes   json.RawMessage `json:"attributes"`
+	Sort         string          `json:"sort"`
+}
+
+func (q *Queries) ListHumans(ctx context.Context, arg ListHumansParams) ([]Human, error) {
+	rows, err := q.db.QueryContext(ctx, listHumans,
+		arg.TenantID,
+		arg.CreatedSince,
+		arg.CreatedUntil,
+		arg.UpdatedSince,
+		arg.UpdatedUntil,
+		pq.Array(arg.Tags),
+		arg.MatchAllTags,
+		arg.Attributes,
+		arg.Sort,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Human
+	for rows.Next() {
+		var i Human
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Surname,
+			&i.Patronymic,
+			&i.Age,
+			&i.Gender,
+			&i.Country,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TenantID,
+			&i.Attributes,
+			&i.NameIndex,
+			&i.PiiKeyID,
+			&i.PiiDek,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHumansByIDs = `-- name: ListHumansByIDs :many
+SELECT id, name, surname, patronymic, age, gender, country, created_at, updated_at, tenant_id, attributes, name_index, pii_key_id, pii_dek FROM humans
+WHERE tenant_id = $1 AND id = ANY($2::uuid[])
+ORDER BY id
 `
 
-func (q *Queries) GetHumans(ctx context.Context) ([]Human, error) {
-	rows, err := q.db.QueryContext(ctx, getHumans)
+type ListHumansByIDsParams struct {
+	TenantID string      `json:"tenant_id"`
+	Ids      []uuid.UUID `json:"ids"`
+}
+
+func (q *Queries) ListHumansByIDs(ctx context.Context, arg ListHumansByIDsParams) ([]Human, error) {
+	rows, err := q.db.QueryContext(ctx, listHumansByIDs, arg.TenantID, pq.Array(arg.Ids))
 	if err != nil {
 		return nil, err
 	}
@@ -123,6 +442,12 @@ func (q *Queries) GetHumans(ctx context.Context) ([]Human, error) {
 			&i.Gender,
 			&i.Country,
 			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TenantID,
+			&i.Attributes,
+			&i.NameIndex,
+			&i.PiiKeyID,
+			&i.PiiDek,
 		); err != nil {
 			return nil, err
 		}
@@ -139,23 +464,30 @@ func (q *Queries) GetHumans(ctx context.Context) ([]Human, error) {
 
 const updateHuman = `-- name: UpdateHuman :one
 UPDATE humans
-SET name = $2, surname = $3, patronymic = $4, age = $5, gender = $6, country = $7
-WHERE id = $1
-RETURNING id, name, surname, patronymic, age, gender, country, created_at
+SET name = $3, surname = $4, patronymic = $5, age = $6, gender = $7, country = $8, attributes = $9,
+    name_index = $10, pii_key_id = $11, pii_dek = $12, updated_at = CURRENT_TIMESTAMP
+WHERE tenant_id = $1 AND id = $2
+RETURNING id, name, surname, patronymic, age, gender, country, created_at, updated_at, tenant_id, attributes, name_index, pii_key_id, pii_dek
 `
 
 type UpdateHumanParams struct {
-	ID         uuid.UUID      `json:"id"`
-	Name       string         `json:"name"`
-	Surname    string         `json:"surname"`
-	Patronymic sql.NullString `json:"patronymic"`
-	Age        int32          `json:"age"`
-	Gender     string         `json:"gender"`
-	Country    string         `json:"country"`
+	TenantID   string          `json:"tenant_id"`
+	ID         uuid.UUID       `json:"id"`
+	Name       string          `json:"name"`
+	Surname    string          `json:"surname"`
+	Patronymic sql.NullString  `json:"patronymic"`
+	Age        int32           `json:"age"`
+	Gender     string          `json:"gender"`
+	Country    string          `json:"country"`
+	Attributes json.RawMessage `json:"attributes"`
+	NameIndex  []byte          `json:"name_index"`
+	PiiKeyID   sql.NullString  `json:"pii_key_id"`
+	PiiDek     []byte          `json:"pii_dek"`
 }
 
 func (q *Queries) UpdateHuman(ctx context.Context, arg UpdateHumanParams) (Human, error) {
 	row := q.db.QueryRowContext(ctx, updateHuman,
+		arg.TenantID,
 		arg.ID,
 		arg.Name,
 		arg.Surname,
@@ -163,6 +495,10 @@ func (q *Queries) UpdateHuman(ctx context.Context, arg UpdateHumanParams) (Human
 		arg.Age,
 		arg.Gender,
 		arg.Country,
+		arg.Attributes,
+		arg.NameIndex,
+		arg.PiiKeyID,
+		arg.PiiDek,
 	)
 	var i Human
 	err := row.Scan(
@@ -174,6 +510,12 @@ func (q *Queries) UpdateHuman(ctx context.Context, arg UpdateHumanParams) (Human
 		&i.Gender,
 		&i.Country,
 		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TenantID,
+		&i.Attributes,
+		&i.NameIndex,
+		&i.PiiKeyID,
+		&i.PiiDek,
 	)
 	return i, err
 }
@@ -0,0 +1,208 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: imports.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createImportError = `-- name: CreateImportError :exec
+INSERT INTO import_job_errors (tenant_id, job_id, row_number, code, message, fields)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type CreateImportErrorParams struct {
+	TenantID  string          `json:"tenant_id"`
+	JobID     uuid.UUID       `json:"job_id"`
+	RowNumber int32           `json:"row_number"`
+	Code      string          `json:"code"`
+	Message   string          `json:"message"`
+	Fields    json.RawMessage `json:"fields"`
+}
+
+func (q *Queries) CreateImportError(ctx context.Context, arg CreateImportErrorParams) error {
+	_, err := q.db.ExecContext(ctx, createImportError,
+		arg.TenantID,
+		arg.JobID,
+		arg.RowNumber,
+		arg.Code,
+		arg.Message,
+		arg.Fields,
+	)
+	return err
+}
+
+const createImportJob = `-- name: CreateImportJob :one
+INSERT INTO import_jobs (id, tenant_id, format, status, total_rows, created_at, updated_at)
+VALUES (gen_random_uuid(), $1, $2, 'pending', $3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+RETURNING id, tenant_id, format, status, total_rows, processed_rows, created_rows, failed_rows, created_at, updated_at, finished_at
+`
+
+type CreateImportJobParams struct {
+	TenantID  string `json:"tenant_id"`
+	Format    string `json:"format"`
+	TotalRows int32  `json:"total_rows"`
+}
+
+func (q *Queries) CreateImportJob(ctx context.Context, arg CreateImportJobParams) (ImportJob, error) {
+	row := q.db.QueryRowContext(ctx, createImportJob, arg.TenantID, arg.Format, arg.TotalRows)
+	var i ImportJob
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Format,
+		&i.Status,
+		&i.TotalRows,
+		&i.ProcessedRows,
+		&i.CreatedRows,
+		&i.FailedRows,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const failStaleImportJobs = `-- name: FailStaleImportJobs :execrows
+UPDATE import_jobs
+SET status = 'failed', finished_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE status IN ('pending', 'running') AND updated_at < $1::timestamptz
+`
+
+// Jobs of every tenant that stopped saving progress, because the process
+// running them exited.
+func (q *Queries) FailStaleImportJobs(ctx context.Context, updatedBefore time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, failStaleImportJobs, updatedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getImportJob = `-- name: GetImportJob :one
+SELECT id, tenant_id, format, status, total_rows, processed_rows, created_rows, failed_rows, created_at, updated_at, finished_at FROM import_jobs
+WHERE tenant_id = $1 AND id = $2
+`
+
+type GetImportJobParams struct {
+	TenantID string    `json:"tenant_id"`
+	ID       uuid.UUID `json:"id"`
+}
+
+func (q *Queries) GetImportJob(ctx context.Context, arg GetImportJobParams) (ImportJob, error) {
+	row := q.db.QueryRowContext(ctx, getImportJob, arg.TenantID, arg.ID)
+	var i ImportJob
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Format,
+		&i.Status,
+		&i.TotalRows,
+		&i.ProcessedRows,
+		&i.CreatedRows,
+		&i.FailedRows,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const listImportErrors = `-- name: ListImportErrors :many
+SELECT tenant_id, job_id, row_number, code, message, fields FROM import_job_errors
+WHERE tenant_id = $1 AND job_id = $2
+ORDER BY row_number
+`
+
+type ListImportErrorsParams struct {
+	TenantID string    `json:"tenant_id"`
+	JobID    uuid.UUID `json:"job_id"`
+}
+
+func (q *Queries) ListImportErrors(ctx context.Context, arg ListImportErrorsParams) ([]ImportJobError, error) {
+	rows, err := q.db.QueryContext(ctx, listImportErrors, arg.TenantID, arg.JobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ImportJobError
+	for rows.Next() {
+		var i ImportJobError
+		if err := rows.Scan(
+			&i.TenantID,
+			&i.JobID,
+			&i.RowNumber,
+			&i.Code,
+			&i.Message,
+			&i.Fields,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateImportJobProgress = `-- name: UpdateImportJobProgress :one
+UPDATE import_jobs
+SET status = $3,
+    processed_rows = $4,
+    created_rows = $5,
+    failed_rows = $6,
+    finished_at = $7,
+    updated_at = CURRENT_TIMESTAMP
+WHERE tenant_id = $1 AND id = $2 AND status IN ('pending', 'running')
+RETURNING id, tenant_id, format, status, total_rows, processed_rows, created_rows, failed_rows, created_at, updated_at, finished_at
+`
+
+type UpdateImportJobProgressParams struct {
+	TenantID      string       `json:"tenant_id"`
+	ID            uuid.UUID    `json:"id"`
+	Status        string       `json:"status"`
+	ProcessedRows int32        `json:"processed_rows"`
+	CreatedRows   int32        `json:"created_rows"`
+	FailedRows    int32        `json:"failed_rows"`
+	FinishedAt    sql.NullTime `json:"finished_at"`
+}
+
+// Finished jobs are left alone, so a job failed as stale stays failed.
+func (q *Queries) UpdateImportJobProgress(ctx context.Context, arg UpdateImportJobProgressParams) (ImportJob, error) {
+	row := q.db.QueryRowContext(ctx, updateImportJobProgress,
+		arg.TenantID,
+		arg.ID,
+		arg.Status,
+		arg.ProcessedRows,
+		arg.CreatedRows,
+		arg.FailedRows,
+		arg.FinishedAt,
+	)
+	var i ImportJob
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Format,
+		&i.Status,
+		&i.TotalRows,
+		&i.ProcessedRows,
+		&i.CreatedRows,
+		&i.FailedRows,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,296 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: keys.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+const listHumansForKeyRotation = `-- name: ListHumansForKeyRotation :many
+SELECT id, name, surname, patronymic, age, gender, country, created_at, updated_at, tenant_id, attributes, name_index, pii_key_id, pii_dek FROM humans
+WHERE id > $1 AND (pii_key_id IS NULL OR pii_key_id <> $2::text)
+ORDER BY id
+LIMIT $3
+FOR UPDATE
+`
+
+type ListHumansForKeyRotationParams struct {
+	After        uuid.UUID `json:"after"`
+	CurrentKeyID string    `json:"current_key_id"`
+	BatchSize    int32     `json:"batch_size"`
+}
+
+// Rows of every tenant not yet under the current key, in id order from
+// after. They stay locked until the batch commits.
+func (q *Queries) ListHumansForKeyRotation(ctx context.Context, arg ListHumansForKeyRotationParams) ([]Human, error) {
+	rows, err := q.db.QueryContext(ctx, listHumansForKeyRotation, arg.After, arg.CurrentKeyID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Human
+	for rows.Next() {
+		var i Human
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Surname,
+			&i.Patronymic,
+			&i.Age,
+			&i.Gender,
+			&i.Country,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TenantID,
+			&i.Attributes,
+			&i.NameIndex,
+			&i.PiiKeyID,
+			&i.PiiDek,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOutboxEventsForKeyRotation = `-- name: ListOutboxEventsForKeyRotation :many
+SELECT id, event_id, tenant_id, human_id, event_type, payload, attempts, last_error, created_at, delivered_at FROM outbox
+WHERE id > $1 AND (payload->>'pii_key_id') IS DISTINCT FROM $2::text
+ORDER BY id
+LIMIT $3
+FOR UPDATE
+`
+
+type ListOutboxEventsForKeyRotationParams struct {
+	After        int64  `json:"after"`
+	CurrentKeyID string `json:"current_key_id"`
+	BatchSize    int32  `json:"batch_size"`
+}
+
+// Events of every tenant whose payload is not sealed under the current
+// key, in id order from after. They stay locked until the batch commits.
+func (q *Queries) ListOutboxEventsForKeyRotation(ctx context.Context, arg ListOutboxEventsForKeyRotationParams) ([]Outbox, error) {
+	rows, err := q.db.QueryContext(ctx, listOutboxEventsForKeyRotation, arg.After, arg.CurrentKeyID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Outbox
+	for rows.Next() {
+		var i Outbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.TenantID,
+			&i.HumanID,
+			&i.EventType,
+			&i.Payload,
+			&i.Attempts,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnindexedHumans = `-- name: ListUnindexedHumans :many
+SELECT id, name, surname, patronymic, age, gender, country, created_at, updated_at, tenant_id, attributes, name_index, pii_key_id, pii_dek FROM humans
+WHERE id > $1 AND pii_key_id IS NULL AND name_index IS NULL
+ORDER BY id
+LIMIT $2
+FOR UPDATE
+`
+
+type ListUnindexedHumansParams struct {
+	After     uuid.UUID `json:"after"`
+	BatchSize int32     `json:"batch_size"`
+}
+
+// Plaintext rows of every tenant without a name index, in id order from
+// after. They stay locked until the batch commits.
+func (q *Queries) ListUnindexedHumans(ctx context.Context, arg ListUnindexedHumansParams) ([]Human, error) {
+	rows, err := q.db.QueryContext(ctx, listUnindexedHumans, arg.After, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Human
+	for rows.Next() {
+		var i Human
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Surname,
+			&i.Patronymic,
+			&i.Age,
+			&i.Gender,
+			&i.Country,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TenantID,
+			&i.Attributes,
+			&i.NameIndex,
+			&i.PiiKeyID,
+			&i.PiiDek,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookDeliveriesForKeyRotation = `-- name: ListWebhookDeliveriesForKeyRotation :many
+SELECT id, tenant_id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_response_status, last_error, created_at, updated_at, human_id FROM webhook_deliveries
+WHERE id > $1 AND (payload->>'pii_key_id') IS DISTINCT FROM $2::text
+ORDER BY id
+LIMIT $3
+FOR UPDATE
+`
+
+type ListWebhookDeliveriesForKeyRotationParams struct {
+	After        uuid.UUID `json:"after"`
+	CurrentKeyID string    `json:"current_key_id"`
+	BatchSize    int32     `json:"batch_size"`
+}
+
+// Deliveries of every tenant whose payload is not sealed under the current
+// key, in id order from after. They stay locked until the batch commits.
+func (q *Queries) ListWebhookDeliveriesForKeyRotation(ctx context.Context, arg ListWebhookDeliveriesForKeyRotationParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookDeliveriesForKeyRotation, arg.After, arg.CurrentKeyID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.SubscriptionID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastResponseStatus,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.HumanID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setHumanNameIndex = `-- name: SetHumanNameIndex :exec
+UPDATE humans SET name_index = $2 WHERE id = $1
+`
+
+type SetHumanNameIndexParams struct {
+	ID        uuid.UUID `json:"id"`
+	NameIndex []byte    `json:"name_index"`
+}
+
+func (q *Queries) SetHumanNameIndex(ctx context.Context, arg SetHumanNameIndexParams) error {
+	_, err := q.db.ExecContext(ctx, setHumanNameIndex, arg.ID, arg.NameIndex)
+	return err
+}
+
+const updateHumanPII = `-- name: UpdateHumanPII :exec
+UPDATE humans
+SET name = $2, surname = $3, patronymic = $4, name_index = $5, pii_key_id = $6, pii_dek = $7
+WHERE id = $1
+`
+
+type UpdateHumanPIIParams struct {
+	ID         uuid.UUID      `json:"id"`
+	Name       string         `json:"name"`
+	Surname    string         `json:"surname"`
+	Patronymic sql.NullString `json:"patronymic"`
+	NameIndex  []byte         `json:"name_index"`
+	PiiKeyID   sql.NullString `json:"pii_key_id"`
+	PiiDek     []byte         `json:"pii_dek"`
+}
+
+func (q *Queries) UpdateHumanPII(ctx context.Context, arg UpdateHumanPIIParams) error {
+	_, err := q.db.ExecContext(ctx, updateHumanPII,
+		arg.ID,
+		arg.Name,
+		arg.Surname,
+		arg.Patronymic,
+		arg.NameIndex,
+		arg.PiiKeyID,
+		arg.PiiDek,
+	)
+	return err
+}
+
+const updateOutboxEventPayload = `-- name: UpdateOutboxEventPayload :exec
+UPDATE outbox SET payload = $2 WHERE id = $1
+`
+
+type UpdateOutboxEventPayloadParams struct {
+	ID      int64           `json:"id"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+func (q *Queries) UpdateOutboxEventPayload(ctx context.Context, arg UpdateOutboxEventPayloadParams) error {
+	_, err := q.db.ExecContext(ctx, updateOutboxEventPayload, arg.ID, arg.Payload)
+	return err
+}
+
+const updateWebhookDeliveryPayload = `-- name: UpdateWebhookDeliveryPayload :exec
+UPDATE webhook_deliveries SET payload = $2 WHERE id = $1
+`
+
+type UpdateWebhookDeliveryPayloadParams struct {
+	ID      uuid.UUID       `json:"id"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+func (q *Queries) UpdateWebhookDeliveryPayload(ctx context.Context, arg UpdateWebhookDeliveryPayloadParams) error {
+	_, err := q.db.ExecContext(ctx, updateWebhookDeliveryPayload, arg.ID, arg.Payload)
+	return err
+}
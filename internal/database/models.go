@@ -6,18 +6,153 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type AttributeDefinition struct {
+	TenantID   string          `json:"tenant_id"`
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	Required   bool            `json:"required"`
+	EnumValues json.RawMessage `json:"enum_values"`
+	Pattern    sql.NullString  `json:"pattern"`
+	MaxLength  sql.NullInt32   `json:"max_length"`
+	Minimum    sql.NullFloat64 `json:"minimum"`
+	Maximum    sql.NullFloat64 `json:"maximum"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
 type Human struct {
+	ID         uuid.UUID       `json:"id"`
+	Name       string          `json:"name"`
+	Surname    string          `json:"surname"`
+	Patronymic sql.NullString  `json:"patronymic"`
+	Age        int32           `json:"age"`
+	Gender     string          `json:"gender"`
+	Country    string          `json:"country"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	TenantID   string          `json:"tenant_id"`
+	Attributes json.RawMessage `json:"attributes"`
+	NameIndex  []byte          `json:"name_index"`
+	PiiKeyID   sql.NullString  `json:"pii_key_id"`
+	PiiDek     []byte          `json:"pii_dek"`
+}
+
+type HumanContact struct {
 	ID         uuid.UUID      `json:"id"`
-	Name       string         `json:"name"`
-	Surname    string         `json:"surname"`
-	Patronymic sql.NullString `json:"patronymic"`
-	Age        int32          `json:"age"`
-	Gender     string         `json:"gender"`
-	Country    string         `json:"country"`
+	TenantID   string         `json:"tenant_id"`
+	HumanID    uuid.UUID      `json:"human_id"`
+	Type       string         `json:"type"`
+	Value      string         `json:"value"`
+	IsPrimary  bool           `json:"is_primary"`
+	Street     sql.NullString `json:"street"`
+	City       sql.NullString `json:"city"`
+	Region     sql.NullString `json:"region"`
+	PostalCode sql.NullString `json:"postal_code"`
+	Country    sql.NullString `json:"country"`
 	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+type HumanRelationship struct {
+	ID         uuid.UUID `json:"id"`
+	TenantID   string    `json:"tenant_id"`
+	HumanID    uuid.UUID `json:"human_id"`
+	RelativeID uuid.UUID `json:"relative_id"`
+	Relation   string    `json:"relation"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type HumanTag struct {
+	TenantID  string    `json:"tenant_id"`
+	HumanID   uuid.UUID `json:"human_id"`
+	TagID     uuid.UUID `json:"tag_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ImportJob struct {
+	ID            uuid.UUID    `json:"id"`
+	TenantID      string       `json:"tenant_id"`
+	Format        string       `json:"format"`
+	Status        string       `json:"status"`
+	TotalRows     int32        `json:"total_rows"`
+	ProcessedRows int32        `json:"processed_rows"`
+	CreatedRows   int32        `json:"created_rows"`
+	FailedRows    int32        `json:"failed_rows"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+	FinishedAt    sql.NullTime `json:"finished_at"`
+}
+
+type ImportJobError struct {
+	TenantID  string          `json:"tenant_id"`
+	JobID     uuid.UUID       `json:"job_id"`
+	RowNumber int32           `json:"row_number"`
+	Code      string          `json:"code"`
+	Message   string          `json:"message"`
+	Fields    json.RawMessage `json:"fields"`
+}
+
+type Outbox struct {
+	ID          int64           `json:"id"`
+	EventID     uuid.UUID       `json:"event_id"`
+	TenantID    string          `json:"tenant_id"`
+	HumanID     uuid.UUID       `json:"human_id"`
+	EventType   string          `json:"event_type"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int32           `json:"attempts"`
+	LastError   sql.NullString  `json:"last_error"`
+	CreatedAt   time.Time       `json:"created_at"`
+	DeliveredAt sql.NullTime    `json:"delivered_at"`
+}
+
+type Tag struct {
+	ID        uuid.UUID `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID                 uuid.UUID       `json:"id"`
+	TenantID           string          `json:"tenant_id"`
+	SubscriptionID     uuid.UUID       `json:"subscription_id"`
+	EventID            uuid.UUID       `json:"event_id"`
+	EventType          string          `json:"event_type"`
+	Payload            json.RawMessage `json:"payload"`
+	Status             string          `json:"status"`
+	Attempts           int32           `json:"attempts"`
+	NextAttemptAt      time.Time       `json:"next_attempt_at"`
+	LastResponseStatus sql.NullInt32   `json:"last_response_status"`
+	LastError          sql.NullString  `json:"last_error"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+	HumanID            uuid.UUID       `json:"human_id"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ID             int64          `json:"id"`
+	DeliveryID     uuid.UUID      `json:"delivery_id"`
+	ResponseStatus sql.NullInt32  `json:"response_status"`
+	Error          sql.NullString `json:"error"`
+	DurationMs     int32          `json:"duration_ms"`
+	AttemptedAt    time.Time      `json:"attempted_at"`
+}
+
+type WebhookSubscription struct {
+	ID                  uuid.UUID    `json:"id"`
+	TenantID            string       `json:"tenant_id"`
+	Url                 string       `json:"url"`
+	EventTypes          []string     `json:"event_types"`
+	Secret              string       `json:"secret"`
+	Enabled             bool         `json:"enabled"`
+	ConsecutiveFailures int32        `json:"consecutive_failures"`
+	DisabledAt          sql.NullTime `json:"disabled_at"`
+	CreatedAt           time.Time    `json:"created_at"`
+	UpdatedAt           time.Time    `json:"updated_at"`
 }
@@ -0,0 +1,302 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: outbox.sql
+
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const claimOutboxEvents = `-- name: ClaimOutboxEvents :many
+SELECT id, event_id, tenant_id, human_id, event_type, payload, attempts, last_error, created_at, delivered_at FROM outbox
+WHERE delivered_at IS NULL
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED
+`
+
+// The oldest undelivered events of every tenant. Rows claimed by another
+// relay are skipped, claimed rows stay locked until the transaction ends.
+func (q *Queries) ClaimOutboxEvents(ctx context.Context, batchSize int32) ([]Outbox, error) {
+	rows, err := q.db.QueryContext(ctx, claimOutboxEvents, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Outbox
+	for rows.Next() {
+		var i Outbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.TenantID,
+			&i.HumanID,
+			&i.EventType,
+			&i.Payload,
+			&i.Attempts,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createOutboxEvent = `-- name: CreateOutboxEvent :one
+INSERT INTO outbox (event_id, tenant_id, human_id, event_type, payload, created_at)
+VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+RETURNING id, event_id, tenant_id, human_id, event_type, payload, attempts, last_error, created_at, delivered_at
+`
+
+type CreateOutboxEventParams struct {
+	EventID   uuid.UUID       `json:"event_id"`
+	TenantID  string          `json:"tenant_id"`
+	HumanID   uuid.UUID       `json:"human_id"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+func (q *Queries) CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error) {
+	row := q.db.QueryRowContext(ctx, createOutboxEvent,
+		arg.EventID,
+		arg.TenantID,
+		arg.HumanID,
+		arg.EventType,
+		arg.Payload,
+	)
+	var i Outbox
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.TenantID,
+		&i.HumanID,
+		&i.EventType,
+		&i.Payload,
+		&i.Attempts,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.DeliveredAt,
+	)
+	return i, err
+}
+
+const deleteDeliveredOutboxEvents = `-- name: DeleteDeliveredOutboxEvents :execrows
+DELETE FROM outbox
+WHERE delivered_at < $1::timestamptz
+`
+
+func (q *Queries) DeleteDeliveredOutboxEvents(ctx context.Context, deliveredBefore time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteDeliveredOutboxEvents, deliveredBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteOutboxEventsCreatedBefore = `-- name: DeleteOutboxEventsCreatedBefore :execrows
+DELETE FROM outbox
+WHERE created_at < $1::timestamptz
+`
+
+// Used when no sink publishes events: they are only kept for the event
+// stream and expire whether delivered or not.
+func (q *Queries) DeleteOutboxEventsCreatedBefore(ctx context.Context, createdBefore time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOutboxEventsCreatedBefore, createdBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteOutboxEventsOfHuman = `-- name: DeleteOutboxEventsOfHuman :execrows
+DELETE FROM outbox
+WHERE tenant_id = $1 AND human_id = $2
+`
+
+type DeleteOutboxEventsOfHumanParams struct {
+	TenantID string    `json:"tenant_id"`
+	HumanID  uuid.UUID `json:"human_id"`
+}
+
+func (q *Queries) DeleteOutboxEventsOfHuman(ctx context.Context, arg DeleteOutboxEventsOfHumanParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOutboxEventsOfHuman, arg.TenantID, arg.HumanID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getOutboxEvent = `-- name: GetOutboxEvent :one
+SELECT id, event_id, tenant_id, human_id, event_type, payload, attempts, last_error, created_at, delivered_at FROM outbox
+WHERE id = $1
+`
+
+func (q *Queries) GetOutboxEvent(ctx context.Context, id int64) (Outbox, error) {
+	row := q.db.QueryRowContext(ctx, getOutboxEvent, id)
+	var i Outbox
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.TenantID,
+		&i.HumanID,
+		&i.EventType,
+		&i.Payload,
+		&i.Attempts,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.DeliveredAt,
+	)
+	return i, err
+}
+
+const listOutboxEventsAfter = `-- name: ListOutboxEventsAfter :many
+SELECT id, event_id, tenant_id, human_id, event_type, payload, attempts, last_error, created_at, delivered_at FROM outbox
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListOutboxEventsAfterParams struct {
+	After   int64 `json:"after"`
+	MaxRows int32 `json:"max_rows"`
+}
+
+func (q *Queries) ListOutboxEventsAfter(ctx context.Context, arg ListOutboxEventsAfterParams) ([]Outbox, error) {
+	rows, err := q.db.QueryContext(ctx, listOutboxEventsAfter, arg.After, arg.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Outbox
+	for rows.Next() {
+		var i Outbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.TenantID,
+			&i.HumanID,
+			&i.EventType,
+			&i.Payload,
+			&i.Attempts,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOutboxEventsOfHuman = `-- name: ListOutboxEventsOfHuman :many
+SELECT id, event_id, tenant_id, human_id, event_type, payload, attempts, last_error, created_at, delivered_at FROM outbox
+WHERE tenant_id = $1 AND human_id = $2
+ORDER BY id
+`
+
+type ListOutboxEventsOfHumanParams struct {
+	TenantID string    `json:"tenant_id"`
+	HumanID  uuid.UUID `json:"human_id"`
+}
+
+func (q *Queries) ListOutboxEventsOfHuman(ctx context.Context, arg ListOutboxEventsOfHumanParams) ([]Outbox, error) {
+	rows, err := q.db.QueryContext(ctx, listOutboxEventsOfHuman, arg.TenantID, arg.HumanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Outbox
+	for rows.Next() {
+		var i Outbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.TenantID,
+			&i.HumanID,
+			&i.EventType,
+			&i.Payload,
+			&i.Attempts,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markOutboxEventDelivered = `-- name: MarkOutboxEventDelivered :exec
+UPDATE outbox
+SET attempts = attempts + 1, last_error = NULL, delivered_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) MarkOutboxEventDelivered(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, markOutboxEventDelivered, id)
+	return err
+}
+
+const notifyHumanErased = `-- name: NotifyHumanErased :exec
+SELECT pg_notify('human_erasures', json_build_object(
+    'tenant_id', $1::text,
+    'human_id', $2::uuid
+)::text)
+`
+
+type NotifyHumanErasedParams struct {
+	TenantID string    `json:"tenant_id"`
+	HumanID  uuid.UUID `json:"human_id"`
+}
+
+// Tells the API replicas, once the transaction commits, to drop the
+// human's events from their replay buffers.
+func (q *Queries) NotifyHumanErased(ctx context.Context, arg NotifyHumanErasedParams) error {
+	_, err := q.db.ExecContext(ctx, notifyHumanErased, arg.TenantID, arg.HumanID)
+	return err
+}
+
+const recordOutboxFailure = `-- name: RecordOutboxFailure :exec
+UPDATE outbox
+SET attempts = attempts + 1, last_error = $1::text
+WHERE id = $2
+`
+
+type RecordOutboxFailureParams struct {
+	LastError string `json:"last_error"`
+	ID        int64  `json:"id"`
+}
+
+func (q *Queries) RecordOutboxFailure(ctx context.Context, arg RecordOutboxFailureParams) error {
+	_, err := q.db.ExecContext(ctx, recordOutboxFailure, arg.LastError, arg.ID)
+	return err
+}
@@ -0,0 +1,329 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: relationships.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const createRelationship = `-- name: CreateRelationship :one
+INSERT INTO human_relationships (id, tenant_id, human_id, relative_id, relation, created_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    CURRENT_TIMESTAMP
+) RETURNING id, tenant_id, human_id, relative_id, relation, created_at
+`
+
+type CreateRelationshipParams struct {
+	TenantID   string    `json:"tenant_id"`
+	HumanID    uuid.UUID `json:"human_id"`
+	RelativeID uuid.UUID `json:"relative_id"`
+	Relation   string    `json:"relation"`
+}
+
+func (q *Queries) CreateRelationship(ctx context.Context, arg CreateRelationshipParams) (HumanRelationship, error) {
+	row := q.db.QueryRowContext(ctx, createRelationship,
+		arg.TenantID,
+		arg.HumanID,
+		arg.RelativeID,
+		arg.Relation,
+	)
+	var i HumanRelationship
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.HumanID,
+		&i.RelativeID,
+		&i.Relation,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteRelationship = `-- name: DeleteRelationship :one
+DELETE FROM human_relationships
+WHERE tenant_id = $1 AND human_id = $2 AND relative_id = $3 AND relation = $4
+RETURNING id, tenant_id, human_id, relative_id, relation, created_at
+`
+
+type DeleteRelationshipParams struct {
+	TenantID   string    `json:"tenant_id"`
+	HumanID    uuid.UUID `json:"human_id"`
+	RelativeID uuid.UUID `json:"relative_id"`
+	Relation   string    `json:"relation"`
+}
+
+func (q *Queries) DeleteRelationship(ctx context.Context, arg DeleteRelationshipParams) (HumanRelationship, error) {
+	row := q.db.QueryRowContext(ctx, deleteRelationship,
+		arg.TenantID,
+		arg.HumanID,
+		arg.RelativeID,
+		arg.Relation,
+	)
+	var i HumanRelationship
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.HumanID,
+		&i.RelativeID,
+		&i.Relation,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAncestorIDs = `-- name: ListAncestorIDs :many
+WITH RECURSIVE parent_links AS (
+    SELECT r.human_id AS child_id, r.relative_id AS parent_id
+    FROM human_relationships r
+    WHERE r.tenant_id = $1::text AND r.relation = 'parent'
+    UNION
+    SELECT r.relative_id AS child_id, r.human_id AS parent_id
+    FROM human_relationships r
+    WHERE r.tenant_id = $1::text AND r.relation = 'child'
+), ancestors AS (
+    SELECT parent_id FROM parent_links WHERE child_id = $2::uuid
+    UNION
+    SELECT parent_links.parent_id
+    FROM parent_links
+    JOIN ancestors ON parent_links.child_id = ancestors.parent_id
+)
+SELECT parent_id::uuid AS ancestor_id FROM ancestors
+`
+
+type ListAncestorIDsParams struct {
+	TenantID string    `json:"tenant_id"`
+	HumanID  uuid.UUID `json:"human_id"`
+}
+
+func (q *Queries) ListAncestorIDs(ctx context.Context, arg ListAncestorIDsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, listAncestorIDs, arg.TenantID, arg.HumanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var ancestor_id uuid.UUID
+		if err := rows.Scan(&ancestor_id); err != nil {
+			return nil, err
+		}
+		items = append(items, ancestor_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRelationshipsAmong = `-- name: ListRelationshipsAmong :many
+SELECT id, tenant_id, human_id, relative_id, relation, created_at FROM human_relationships
+WHERE tenant_id = $1
+    AND human_id = ANY($2::uuid[])
+    AND relative_id = ANY($2::uuid[])
+ORDER BY created_at, id
+`
+
+type ListRelationshipsAmongParams struct {
+	TenantID string      `json:"tenant_id"`
+	Ids      []uuid.UUID `json:"ids"`
+}
+
+func (q *Queries) ListRelationshipsAmong(ctx context.Context, arg ListRelationshipsAmongParams) ([]HumanRelationship, error) {
+	rows, err := q.db.QueryContext(ctx, listRelationshipsAmong, arg.TenantID, pq.Array(arg.Ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HumanRelationship
+	for rows.Next() {
+		var i HumanRelationship
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.HumanID,
+			&i.RelativeID,
+			&i.Relation,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRelationshipsOfHuman = `-- name: ListRelationshipsOfHuman :many
+SELECT id, tenant_id, human_id, relative_id, relation, created_at FROM human_relationships
+WHERE tenant_id = $1
+    AND (human_id = $2 OR relative_id = $2)
+ORDER BY created_at, id
+`
+
+type ListRelationshipsOfHumanParams struct {
+	TenantID string    `json:"tenant_id"`
+	HumanID  uuid.UUID `json:"human_id"`
+}
+
+func (q *Queries) ListRelationshipsOfHuman(ctx context.Context, arg ListRelationshipsOfHumanParams) ([]HumanRelationship, error) {
+	rows, err := q.db.QueryContext(ctx, listRelationshipsOfHuman, arg.TenantID, arg.HumanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HumanRelationship
+	for rows.Next() {
+		var i HumanRelationship
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.HumanID,
+			&i.RelativeID,
+			&i.Relation,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRelatives = `-- name: ListRelatives :many
+SELECT human_relationships.id, human_relationships.tenant_id, human_relationships.human_id, human_relationships.relative_id, human_relationships.relation, human_relationships.created_at, humans.id, humans.name, humans.surname, humans.patronymic, humans.age, humans.gender, humans.country, humans.created_at, humans.updated_at, humans.tenant_id, humans.attributes, humans.name_index, humans.pii_key_id, humans.pii_dek
+FROM human_relationships
+JOIN humans ON humans.tenant_id = human_relationships.tenant_id
+    AND humans.id = human_relationships.relative_id
+WHERE human_relationships.tenant_id = $1 AND human_relationships.human_id = $2
+ORDER BY human_relationships.created_at, human_relationships.id
+`
+
+type ListRelativesParams struct {
+	TenantID string    `json:"tenant_id"`
+	HumanID  uuid.UUID `json:"human_id"`
+}
+
+type ListRelativesRow struct {
+	HumanRelationship HumanRelationship `json:"human_relationship"`
+	Human             Human             `json:"human"`
+}
+
+func (q *Queries) ListRelatives(ctx context.Context, arg ListRelativesParams) ([]ListRelativesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listRelatives, arg.TenantID, arg.HumanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRelativesRow
+	for rows.Next() {
+		var i ListRelativesRow
+		if err := rows.Scan(
+			&i.HumanRelationship.ID,
+			&i.HumanRelationship.TenantID,
+			&i.HumanRelationship.HumanID,
+			&i.HumanRelationship.RelativeID,
+			&i.HumanRelationship.Relation,
+			&i.HumanRelationship.CreatedAt,
+			&i.Human.ID,
+			&i.Human.Name,
+			&i.Human.Surname,
+			&i.Human.Patronymic,
+			&i.Human.Age,
+			&i.Human.Gender,
+			&i.Human.Country,
+			&i.Human.CreatedAt,
+			&i.Human.UpdatedAt,
+			&i.Human.TenantID,
+			&i.Human.Attributes,
+			&i.Human.NameIndex,
+			&i.Human.PiiKeyID,
+			&i.Human.PiiDek,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const walkFamilyTree = `-- name: WalkFamilyTree :many
+WITH RECURSIVE tree AS (
+    SELECT $1::uuid AS human_id, 0 AS depth
+    UNION
+    SELECT
+        CASE WHEN r.human_id = tree.human_id THEN r.relative_id ELSE r.human_id END,
+        tree.depth + 1
+    FROM human_relationships r
+    JOIN tree ON r.human_id = tree.human_id OR r.relative_id = tree.human_id
+    WHERE r.tenant_id = $2::text AND tree.depth < $3::int
+)
+SELECT tree.human_id::uuid AS human_id, min(tree.depth)::int AS depth
+FROM tree
+GROUP BY tree.human_id
+ORDER BY depth, tree.human_id
+`
+
+type WalkFamilyTreeParams struct {
+	HumanID  uuid.UUID `json:"human_id"`
+	TenantID string    `json:"tenant_id"`
+	MaxDepth int32     `json:"max_depth"`
+}
+
+type WalkFamilyTreeRow struct {
+	HumanID uuid.UUID `json:"human_id"`
+	Depth   int32     `json:"depth"`
+}
+
+func (q *Queries) WalkFamilyTree(ctx context.Context, arg WalkFamilyTreeParams) ([]WalkFamilyTreeRow, error) {
+	rows, err := q.db.QueryContext(ctx, walkFamilyTree, arg.HumanID, arg.TenantID, arg.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WalkFamilyTreeRow
+	for rows.Next() {
+		var i WalkFamilyTreeRow
+		if err := rows.Scan(&i.HumanID, &i.Depth); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,119 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: tags.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const attachTag = `-- name: AttachTag :exec
+INSERT INTO human_tags (tenant_id, human_id, tag_id, created_at)
+VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+ON CONFLICT DO NOTHING
+`
+
+type AttachTagParams struct {
+	TenantID string    `json:"tenant_id"`
+	HumanID  uuid.UUID `json:"human_id"`
+	TagID    uuid.UUID `json:"tag_id"`
+}
+
+func (q *Queries) AttachTag(ctx context.Context, arg AttachTagParams) error {
+	_, err := q.db.ExecContext(ctx, attachTag, arg.TenantID, arg.HumanID, arg.TagID)
+	return err
+}
+
+const detachTag = `-- name: DetachTag :execrows
+DELETE FROM human_tags
+USING tags
+WHERE human_tags.tenant_id = $1
+    AND human_tags.human_id = $2
+    AND tags.tenant_id = human_tags.tenant_id
+    AND tags.id = human_tags.tag_id
+    AND tags.name = $3
+`
+
+type DetachTagParams struct {
+	TenantID string    `json:"tenant_id"`
+	HumanID  uuid.UUID `json:"human_id"`
+	Name     string    `json:"name"`
+}
+
+func (q *Queries) DetachTag(ctx context.Context, arg DetachTagParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, detachTag, arg.TenantID, arg.HumanID, arg.Name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listTagsByHumanIDs = `-- name: ListTagsByHumanIDs :many
+SELECT human_tags.human_id, tags.name
+FROM human_tags
+JOIN tags ON tags.tenant_id = human_tags.tenant_id AND tags.id = human_tags.tag_id
+WHERE human_tags.tenant_id = $1 AND human_tags.human_id = ANY($2::uuid[])
+ORDER BY human_tags.human_id, tags.name
+`
+
+type ListTagsByHumanIDsParams struct {
+	TenantID string      `json:"tenant_id"`
+	HumanIds []uuid.UUID `json:"human_ids"`
+}
+
+type ListTagsByHumanIDsRow struct {
+	HumanID uuid.UUID `json:"human_id"`
+	Name    string    `json:"name"`
+}
+
+func (q *Queries) ListTagsByHumanIDs(ctx context.Context, arg ListTagsByHumanIDsParams) ([]ListTagsByHumanIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTagsByHumanIDs, arg.TenantID, pq.Array(arg.HumanIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTagsByHumanIDsRow
+	for rows.Next() {
+		var i ListTagsByHumanIDsRow
+		if err := rows.Scan(&i.HumanID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertTag = `-- name: UpsertTag :one
+INSERT INTO tags (id, tenant_id, name, created_at)
+VALUES (gen_random_uuid(), $1, $2, CURRENT_TIMESTAMP)
+ON CONFLICT (tenant_id, name) DO UPDATE SET name = EXCLUDED.name
+RETURNING id, tenant_id, name, created_at
+`
+
+type UpsertTagParams struct {
+	TenantID string `json:"tenant_id"`
+	Name     string `json:"name"`
+}
+
+func (q *Queries) UpsertTag(ctx context.Context, arg UpsertTagParams) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, upsertTag, arg.TenantID, arg.Name)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,701 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: webhooks.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const claimWebhookDeliveries = `-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET next_attempt_at = $1::timestamptz
+FROM webhook_subscriptions
+WHERE webhook_subscriptions.id = webhook_deliveries.subscription_id
+    AND webhook_deliveries.id IN (
+        SELECT d.id FROM webhook_deliveries d
+        JOIN webhook_subscriptions s ON s.id = d.subscription_id
+        WHERE d.status = 'pending' AND d.next_attempt_at <= CURRENT_TIMESTAMP AND s.enabled
+        ORDER BY d.next_attempt_at
+        LIMIT $2
+        FOR UPDATE OF d SKIP LOCKED
+    )
+RETURNING webhook_deliveries.id, webhook_deliveries.tenant_id, webhook_deliveries.subscription_id, webhook_deliveries.event_id,
+    webhook_deliveries.event_type, webhook_deliveries.payload, webhook_deliveries.attempts,
+    webhook_subscriptions.url, webhook_subscriptions.secret
+`
+
+type ClaimWebhookDeliveriesParams struct {
+	LeaseUntil time.Time `json:"lease_until"`
+	BatchSize  int32     `json:"batch_size"`
+}
+
+type ClaimWebhookDeliveriesRow struct {
+	ID             uuid.UUID       `json:"id"`
+	TenantID       string          `json:"tenant_id"`
+	SubscriptionID uuid.UUID       `json:"subscription_id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Attempts       int32           `json:"attempts"`
+	Url            string          `json:"url"`
+	Secret         string          `json:"secret"`
+}
+
+// Due deliveries of enabled subscriptions, across tenants. Claimed rows are
+// leased by moving their next attempt to lease_until, so other dispatchers
+// skip them while the request is in flight.
+func (q *Queries) ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]ClaimWebhookDeliveriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, claimWebhookDeliveries, arg.LeaseUntil, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimWebhookDeliveriesRow
+	for rows.Next() {
+		var i ClaimWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.SubscriptionID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Attempts,
+			&i.Url,
+			&i.Secret,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (id, tenant_id, subscription_id, event_id, human_id, event_type, payload, created_at, updated_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+ON CONFLICT (subscription_id, event_id) DO NOTHING
+`
+
+type CreateWebhookDeliveryParams struct {
+	TenantID       string          `json:"tenant_id"`
+	SubscriptionID uuid.UUID       `json:"subscription_id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	HumanID        uuid.UUID       `json:"human_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, createWebhookDelivery,
+		arg.TenantID,
+		arg.SubscriptionID,
+		arg.EventID,
+		arg.HumanID,
+		arg.EventType,
+		arg.Payload,
+	)
+	return err
+}
+
+const createWebhookDeliveryAttempt = `-- name: CreateWebhookDeliveryAttempt :exec
+INSERT INTO webhook_delivery_attempts (delivery_id, response_status, error, duration_ms, attempted_at)
+VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+`
+
+type CreateWebhookDeliveryAttemptParams struct {
+	DeliveryID     uuid.UUID      `json:"delivery_id"`
+	ResponseStatus sql.NullInt32  `json:"response_status"`
+	Error          sql.NullString `json:"error"`
+	DurationMs     int32          `json:"duration_ms"`
+}
+
+func (q *Queries) CreateWebhookDeliveryAttempt(ctx context.Context, arg CreateWebhookDeliveryAttemptParams) error {
+	_, err := q.db.ExecContext(ctx, createWebhookDeliveryAttempt,
+		arg.DeliveryID,
+		arg.ResponseStatus,
+		arg.Error,
+		arg.DurationMs,
+	)
+	return err
+}
+
+const createWebhookSubscription = `-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (id, tenant_id, url, event_types, secret, created_at, updated_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+RETURNING id, tenant_id, url, event_types, secret, enabled, consecutive_failures, disabled_at, created_at, updated_at
+`
+
+type CreateWebhookSubscriptionParams struct {
+	TenantID   string   `json:"tenant_id"`
+	Url        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+}
+
+func (q *Queries) CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error) {
+	row := q.db.QueryRowContext(ctx, createWebhookSubscription,
+		arg.TenantID,
+		arg.Url,
+		pq.Array(arg.EventTypes),
+		arg.Secret,
+	)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Url,
+		pq.Array(&i.EventTypes),
+		&i.Secret,
+		&i.Enabled,
+		&i.ConsecutiveFailures,
+		&i.DisabledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteFinishedWebhookDeliveries = `-- name: DeleteFinishedWebhookDeliveries :execrows
+DELETE FROM webhook_deliveries
+WHERE status <> 'pending' AND updated_at < $1::timestamptz
+`
+
+func (q *Queries) DeleteFinishedWebhookDeliveries(ctx context.Context, updatedBefore time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFinishedWebhookDeliveries, updatedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteWebhookDeliveriesOfHuman = `-- name: DeleteWebhookDeliveriesOfHuman :execrows
+DELETE FROM webhook_deliveries
+WHERE tenant_id = $1 AND human_id = $2
+`
+
+type DeleteWebhookDeliveriesOfHumanParams struct {
+	TenantID string    `json:"tenant_id"`
+	HumanID  uuid.UUID `json:"human_id"`
+}
+
+// Pending and finished deliveries alike, with their attempt logs.
+func (q *Queries) DeleteWebhookDeliveriesOfHuman(ctx context.Context, arg DeleteWebhookDeliveriesOfHumanParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteWebhookDeliveriesOfHuman, arg.TenantID, arg.HumanID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteWebhookSubscription = `-- name: DeleteWebhookSubscription :one
+DELETE FROM webhook_subscriptions
+WHERE tenant_id = $1 AND id = $2
+RETURNING id, tenant_id, url, event_types, secret, enabled, consecutive_failures, disabled_at, created_at, updated_at
+`
+
+type DeleteWebhookSubscriptionParams struct {
+	TenantID string    `json:"tenant_id"`
+	ID       uuid.UUID `json:"id"`
+}
+
+func (q *Queries) DeleteWebhookSubscription(ctx context.Context, arg DeleteWebhookSubscriptionParams) (WebhookSubscription, error) {
+	row := q.db.QueryRowContext(ctx, deleteWebhookSubscription, arg.TenantID, arg.ID)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Url,
+		pq.Array(&i.EventTypes),
+		&i.Secret,
+		&i.Enabled,
+		&i.ConsecutiveFailures,
+		&i.DisabledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getWebhookDelivery = `-- name: GetWebhookDelivery :one
+SELECT id, tenant_id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_response_status, last_error, created_at, updated_at, human_id FROM webhook_deliveries
+WHERE tenant_id = $1 AND subscription_id = $2 AND id = $3
+`
+
+type GetWebhookDeliveryParams struct {
+	TenantID       string    `json:"tenant_id"`
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	ID             uuid.UUID `json:"id"`
+}
+
+func (q *Queries) GetWebhookDelivery(ctx context.Context, arg GetWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRowContext(ctx, getWebhookDelivery, arg.TenantID, arg.SubscriptionID, arg.ID)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.SubscriptionID,
+		&i.EventID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.LastResponseStatus,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.HumanID,
+	)
+	return i, err
+}
+
+const getWebhookSubscription = `-- name: GetWebhookSubscription :one
+SELECT id, tenant_id, url, event_types, secret, enabled, consecutive_failures, disabled_at, created_at, updated_at FROM webhook_subscriptions
+WHERE tenant_id = $1 AND id = $2
+`
+
+type GetWebhookSubscriptionParams struct {
+	TenantID string    `json:"tenant_id"`
+	ID       uuid.UUID `json:"id"`
+}
+
+func (q *Queries) GetWebhookSubscription(ctx context.Context, arg GetWebhookSubscriptionParams) (WebhookSubscription, error) {
+	row := q.db.QueryRowContext(ctx, getWebhookSubscription, arg.TenantID, arg.ID)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Url,
+		pq.Array(&i.EventTypes),
+		&i.Secret,
+		&i.Enabled,
+		&i.ConsecutiveFailures,
+		&i.DisabledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT id, tenant_id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_response_status, last_error, created_at, updated_at, human_id FROM webhook_deliveries
+WHERE tenant_id = $1
+    AND subscription_id = $2
+    AND ($3::text = '' OR status = $3::text)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type ListWebhookDeliveriesParams struct {
+	TenantID       string    `json:"tenant_id"`
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	Status         string    `json:"status"`
+	MaxRows        int32     `json:"max_rows"`
+}
+
+// The latest deliveries of a subscription, optionally only those in status.
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookDeliveries,
+		arg.TenantID,
+		arg.SubscriptionID,
+		arg.Status,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.SubscriptionID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastResponseStatus,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.HumanID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookDeliveriesOfHuman = `-- name: ListWebhookDeliveriesOfHuman :many
+SELECT id, tenant_id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_response_status, last_error, created_at, updated_at, human_id FROM webhook_deliveries
+WHERE tenant_id = $1 AND human_id = $2
+ORDER BY created_at, id
+`
+
+type ListWebhookDeliveriesOfHumanParams struct {
+	TenantID string    `json:"tenant_id"`
+	HumanID  uuid.UUID `json:"human_id"`
+}
+
+func (q *Queries) ListWebhookDeliveriesOfHuman(ctx context.Context, arg ListWebhookDeliveriesOfHumanParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookDeliveriesOfHuman, arg.TenantID, arg.HumanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.SubscriptionID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastResponseStatus,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.HumanID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookDeliveryAttempts = `-- name: ListWebhookDeliveryAttempts :many
+SELECT webhook_delivery_attempts.id, webhook_delivery_attempts.delivery_id, webhook_delivery_attempts.response_status, webhook_delivery_attempts.error, webhook_delivery_attempts.duration_ms, webhook_delivery_attempts.attempted_at FROM webhook_delivery_attempts
+JOIN webhook_deliveries ON webhook_deliveries.id = webhook_delivery_attempts.delivery_id
+WHERE webhook_deliveries.tenant_id = $1 AND webhook_delivery_attempts.delivery_id = $2
+ORDER BY webhook_delivery_attempts.id
+`
+
+type ListWebhookDeliveryAttemptsParams struct {
+	TenantID   string    `json:"tenant_id"`
+	DeliveryID uuid.UUID `json:"delivery_id"`
+}
+
+func (q *Queries) ListWebhookDeliveryAttempts(ctx context.Context, arg ListWebhookDeliveryAttemptsParams) ([]WebhookDeliveryAttempt, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookDeliveryAttempts, arg.TenantID, arg.DeliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDeliveryAttempt
+	for rows.Next() {
+		var i WebhookDeliveryAttempt
+		if err := rows.Scan(
+			&i.ID,
+			&i.DeliveryID,
+			&i.ResponseStatus,
+			&i.Error,
+			&i.DurationMs,
+			&i.AttemptedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookSubscriptions = `-- name: ListWebhookSubscriptions :many
+SELECT id, tenant_id, url, event_types, secret, enabled, consecutive_failures, disabled_at, created_at, updated_at FROM webhook_subscriptions
+WHERE tenant_id = $1
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListWebhookSubscriptions(ctx context.Context, tenantID string) ([]WebhookSubscription, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookSubscriptions, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookSubscription
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Url,
+			pq.Array(&i.EventTypes),
+			&i.Secret,
+			&i.Enabled,
+			&i.ConsecutiveFailures,
+			&i.DisabledAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookSubscriptionsForEvent = `-- name: ListWebhookSubscriptionsForEvent :many
+SELECT id, tenant_id, url, event_types, secret, enabled, consecutive_failures, disabled_at, created_at, updated_at FROM webhook_subscriptions
+WHERE tenant_id = $1
+    AND enabled
+    AND (cardinality(event_types) = 0 OR $2::text = ANY(event_types))
+ORDER BY created_at, id
+`
+
+type ListWebhookSubscriptionsForEventParams struct {
+	TenantID  string `json:"tenant_id"`
+	EventType string `json:"event_type"`
+}
+
+// Enabled subscriptions of the tenant whose filter accepts the event type.
+// An empty filter accepts every type.
+func (q *Queries) ListWebhookSubscriptionsForEvent(ctx context.Context, arg ListWebhookSubscriptionsForEventParams) ([]WebhookSubscription, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookSubscriptionsForEvent, arg.TenantID, arg.EventType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookSubscription
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Url,
+			pq.Array(&i.EventTypes),
+			&i.Secret,
+			&i.Enabled,
+			&i.ConsecutiveFailures,
+			&i.DisabledAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordWebhookSubscriptionFailure = `-- name: RecordWebhookSubscriptionFailure :one
+UPDATE webhook_subscriptions
+SET consecutive_failures = consecutive_failures + 1,
+    enabled = enabled AND consecutive_failures + 1 < $2::int,
+    disabled_at = CASE
+        WHEN enabled AND consecutive_failures + 1 >= $2::int THEN CURRENT_TIMESTAMP
+        ELSE disabled_at
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, tenant_id, url, event_types, secret, enabled, consecutive_failures, disabled_at, created_at, updated_at
+`
+
+type RecordWebhookSubscriptionFailureParams struct {
+	ID          uuid.UUID `json:"id"`
+	MaxFailures int32     `json:"max_failures"`
+}
+
+// Counts a delivery that ran out of attempts and disables the subscription
+// once max_failures of them happened in a row.
+func (q *Queries) RecordWebhookSubscriptionFailure(ctx context.Context, arg RecordWebhookSubscriptionFailureParams) (WebhookSubscription, error) {
+	row := q.db.QueryRowContext(ctx, recordWebhookSubscriptionFailure, arg.ID, arg.MaxFailures)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Url,
+		pq.Array(&i.EventTypes),
+		&i.Secret,
+		&i.Enabled,
+		&i.ConsecutiveFailures,
+		&i.DisabledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const redeliverWebhookDelivery = `-- name: RedeliverWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status = 'pending',
+    attempts = 0,
+    next_attempt_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE tenant_id = $1 AND subscription_id = $2 AND id = $3
+RETURNING id, tenant_id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_response_status, last_error, created_at, updated_at, human_id
+`
+
+type RedeliverWebhookDeliveryParams struct {
+	TenantID       string    `json:"tenant_id"`
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	ID             uuid.UUID `json:"id"`
+}
+
+// Schedules the delivery again right away with a fresh retry budget. Its
+// attempt log is kept.
+func (q *Queries) RedeliverWebhookDelivery(ctx context.Context, arg RedeliverWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRowContext(ctx, redeliverWebhookDelivery, arg.TenantID, arg.SubscriptionID, arg.ID)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.SubscriptionID,
+		&i.EventID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.LastResponseStatus,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.HumanID,
+	)
+	return i, err
+}
+
+const resetWebhookSubscriptionFailures = `-- name: ResetWebhookSubscriptionFailures :exec
+UPDATE webhook_subscriptions
+SET consecutive_failures = 0
+WHERE id = $1 AND consecutive_failures > 0
+`
+
+func (q *Queries) ResetWebhookSubscriptionFailures(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, resetWebhookSubscriptionFailures, id)
+	return err
+}
+
+const updateWebhookDeliveryResult = `-- name: UpdateWebhookDeliveryResult :exec
+UPDATE webhook_deliveries
+SET status = $2,
+    attempts = attempts + 1,
+    next_attempt_at = $5::timestamptz,
+    last_response_status = $3,
+    last_error = $4,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+type UpdateWebhookDeliveryResultParams struct {
+	ID                 uuid.UUID      `json:"id"`
+	Status             string         `json:"status"`
+	LastResponseStatus sql.NullInt32  `json:"last_response_status"`
+	LastError          sql.NullString `json:"last_error"`
+	NextAttemptAt      time.Time      `json:"next_attempt_at"`
+}
+
+func (q *Queries) UpdateWebhookDeliveryResult(ctx context.Context, arg UpdateWebhookDeliveryResultParams) error {
+	_, err := q.db.ExecContext(ctx, updateWebhookDeliveryResult,
+		arg.ID,
+		arg.Status,
+		arg.LastResponseStatus,
+		arg.LastError,
+		arg.NextAttemptAt,
+	)
+	return err
+}
+
+const updateWebhookSubscription = `-- name: UpdateWebhookSubscription :one
+UPDATE webhook_subscriptions
+SET url = $3,
+    event_types = $4,
+    secret = $5,
+    enabled = $6,
+    consecutive_failures = CASE WHEN $6 THEN 0 ELSE consecutive_failures END,
+    disabled_at = CASE WHEN $6 THEN NULL ELSE COALESCE(disabled_at, CURRENT_TIMESTAMP) END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE tenant_id = $1 AND id = $2
+RETURNING id, tenant_id, url, event_types, secret, enabled, consecutive_failures, disabled_at, created_at, updated_at
+`
+
+type UpdateWebhookSubscriptionParams struct {
+	TenantID   string    `json:"tenant_id"`
+	ID         uuid.UUID `json:"id"`
+	Url        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret"`
+	Enabled    bool      `json:"enabled"`
+}
+
+// Enabling a disabled subscription clears its failure count.
+func (q *Queries) UpdateWebhookSubscription(ctx context.Context, arg UpdateWebhookSubscriptionParams) (WebhookSubscription, error) {
+	row := q.db.QueryRowContext(ctx, updateWebhookSubscription,
+		arg.TenantID,
+		arg.ID,
+		arg.Url,
+		pq.Array(arg.EventTypes),
+		arg.Secret,
+		arg.Enabled,
+	)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Url,
+		pq.Array(&i.EventTypes),
+		&i.Secret,
+		&i.Enabled,
+		&i.ConsecutiveFailures,
+		&i.DisabledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,5 @@
+// Package eventspb holds the protobuf form of human events, generated from
+// proto/humans/v1/event.proto.
+package eventspb
+
+//go:generate protoc --proto_path=../../proto --go_out=. --go_opt=module=github.com/kiriksik/TestTaskEffectiveMobile/internal/eventspb humans/v1/event.proto
@@ -0,0 +1,132 @@
+// Package export encodes rows of values as CSV, NDJSON or XLSX while they
+// are being produced, without holding the whole table in memory.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatXLSX   = "xlsx"
+)
+
+// Writer encodes rows whose values follow the columns it was created with.
+// Close must be called to complete the output.
+type Writer interface {
+	WriteRow(values []any) error
+	Close() error
+}
+
+// ContentType returns the media type of format, and false for unknown
+// formats.
+func ContentType(format string) (string, bool) {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8", true
+	case FormatNDJSON:
+		return "application/x-ndjson", true
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", true
+	}
+	return "", false
+}
+
+// NewWriter returns a writer for format. CSV and XLSX output starts with a
+// header row of the column names.
+func NewWriter(format string, w io.Writer, columns []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatNDJSON:
+		return &ndjsonWriter{w: w, columns: columns}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w, columns)
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	writer := &csvWriter{w: csv.NewWriter(w), record: make([]string, len(columns))}
+	if err := writer.w.Write(columns); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (c *csvWriter) WriteRow(values []any) error {
+	for i, value := range values {
+		c.record[i] = formatCell(value)
+	}
+	return c.w.Write(c.record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// ndjsonWriter writes one object per row, keeping the column order.
+type ndjsonWriter struct {
+	w       io.Writer
+	columns []string
+	buf     []byte
+}
+
+func (n *ndjsonWriter) WriteRow(values []any) error {
+	n.buf = append(n.buf[:0], '{')
+	for i, value := range values {
+		if i > 0 {
+			n.buf = append(n.buf, ',')
+		}
+		key, err := json.Marshal(n.columns[i])
+		if err != nil {
+			return err
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		n.buf = append(append(append(n.buf, key...), ':'), encoded...)
+	}
+	n.buf = append(n.buf, '}', '\n')
+	_, err := n.w.Write(n.buf)
+	return err
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
+
+// formatCell renders a value for the text based cell formats.
+func formatCell(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	}
+}
@@ -0,0 +1,129 @@
+// Package feed fans human change events out to live subscribers, such as
+// the server-sent events stream. Events come from the outbox: through
+// Postgres notifications, so that every replica sees every event, or
+// straight from the in-memory repository.
+package feed
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+)
+
+const (
+	DefaultReplaySize = 1000
+
+	subscriberBuffer = 64
+)
+
+// Event is a published human event. ID is the id of its outbox row.
+type Event struct {
+	ID       int64
+	TenantID string
+	HumanID  uuid.UUID
+	Type     string
+	Payload  json.RawMessage
+}
+
+func FromOutbox(row database.Outbox) Event {
+	return Event{ID: row.ID, TenantID: row.TenantID, HumanID: row.HumanID, Type: row.EventType, Payload: row.Payload}
+}
+
+// Broker delivers events to subscribers and keeps the latest ones so that
+// a subscriber that reconnects can catch up.
+type Broker struct {
+	mu          sync.Mutex
+	replay      []Event
+	replaySize  int
+	subscribers map[*Subscription]struct{}
+}
+
+func NewBroker(replaySize int) *Broker {
+	return &Broker{replaySize: replaySize, subscribers: make(map[*Subscription]struct{})}
+}
+
+// Subscription receives the events its match function accepts. C is
+// closed when the subscriber falls too far behind; it should then
+// resubscribe from the last event it saw.
+type Subscription struct {
+	// Replay holds the buffered events after the one the subscriber
+	// resumed from.
+	Replay []Event
+	C      <-chan Event
+	ch     chan Event
+	match  func(Event) bool
+	broker *Broker
+}
+
+// Publish delivers event to the subscribers. Events already seen, as
+// after a listener reconnects, are ignored.
+func (b *Broker) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if slices.ContainsFunc(b.replay, func(e Event) bool { return e.ID == event.ID }) {
+		return
+	}
+	if len(b.replay) == b.replaySize && b.replaySize > 0 {
+		b.replay = slices.Delete(b.replay, 0, 1)
+	}
+	if b.replaySize > 0 {
+		b.replay = append(b.replay, event)
+	}
+	for sub := range b.subscribers {
+		if !sub.match(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			delete(b.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Forget drops the buffered events of a human, so that they are no longer
+// replayed. Subscribers that already received them keep them.
+func (b *Broker) Forget(tenantID string, humanID uuid.UUID) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.replay = slices.DeleteFunc(b.replay, func(e Event) bool {
+		return e.TenantID == tenantID && e.HumanID == humanID
+	})
+}
+
+// Subscribe registers a subscriber. With a lastEventID it also returns the
+// buffered matching events published after that one, in publishing order,
+// which is the commit order on every replica. An id no longer buffered
+// replays the buffered events with greater ids.
+func (b *Broker) Subscribe(lastEventID int64, resume bool, match func(Event) bool) *Subscription {
+	ch := make(chan Event, subscriberBuffer)
+	sub := &Subscription{C: ch, ch: ch, match: match, broker: b}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if resume {
+		start := slices.IndexFunc(b.replay, func(e Event) bool { return e.ID == lastEventID })
+		for i, event := range b.replay {
+			if (start >= 0 && i > start) || (start < 0 && event.ID > lastEventID) {
+				if match(event) {
+					sub.Replay = append(sub.Replay, event)
+				}
+			}
+		}
+	}
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	if _, ok := s.broker.subscribers[s]; ok {
+		delete(s.broker.subscribers, s)
+		close(s.ch)
+	}
+}
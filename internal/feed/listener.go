@@ -0,0 +1,131 @@
+package feed
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+	"github.com/lib/pq"
+)
+
+const (
+	// Channel is the Postgres notification channel the outbox trigger
+	// announces events on.
+	Channel = "human_events"
+	// ErasureChannel is the channel NotifyHumanErased announces the humans
+	// whose events must be forgotten on.
+	ErasureChannel = "human_erasures"
+)
+
+const listenerPingInterval = 90 * time.Second
+
+// Listener publishes the outbox events announced by Postgres to a broker.
+type Listener struct {
+	url    string
+	events repository.EventReader
+	broker *Broker
+	// lastID is the greatest event id seen, used to catch up on events
+	// announced while the connection was down.
+	lastID int64
+}
+
+// NewListener returns a listener reading the announced events through
+// events.
+func NewListener(url string, events repository.EventReader, broker *Broker) *Listener {
+	return &Listener{url: url, events: events, broker: broker}
+}
+
+// Run listens until ctx is cancelled, reconnecting as needed.
+func (l *Listener) Run(ctx context.Context) error {
+	listener := pq.NewListener(l.url, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("feed: listener: %s", err)
+		}
+	})
+	defer listener.Close()
+	for _, channel := range []string{Channel, ErasureChannel} {
+		if err := listener.Listen(channel); err != nil {
+			return err
+		}
+	}
+	l.catchUp(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n := <-listener.Notify:
+			if n == nil {
+				// The connection was reestablished and notifications
+				// sent meanwhile are lost.
+				l.catchUp(ctx)
+				continue
+			}
+			if n.Channel == ErasureChannel {
+				l.forget(n.Extra)
+				continue
+			}
+			id, err := strconv.ParseInt(n.Extra, 10, 64)
+			if err != nil {
+				log.Printf("feed: bad notification %q", n.Extra)
+				continue
+			}
+			l.publish(ctx, id)
+		case <-time.After(listenerPingInterval):
+			if err := listener.Ping(); err != nil {
+				log.Printf("feed: ping failed: %s", err)
+			}
+		}
+	}
+}
+
+func (l *Listener) publish(ctx context.Context, id int64) {
+	row, err := l.events.GetOutboxEvent(ctx, id)
+	if err != nil {
+		// Delivered events may already have been cleaned up.
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("feed: failed to read event %d: %s", id, err)
+		}
+		return
+	}
+	l.broker.Publish(FromOutbox(row))
+	l.lastID = max(l.lastID, id)
+}
+
+// forget drops the buffered events of the human an erasure notification
+// names. An erasure announced while the connection was down is missed, and
+// the events stay buffered until newer ones push them out.
+func (l *Listener) forget(payload string) {
+	var erasure database.NotifyHumanErasedParams
+	if err := json.Unmarshal([]byte(payload), &erasure); err != nil {
+		log.Printf("feed: bad erasure notification %q", payload)
+		return
+	}
+	l.broker.Forget(erasure.TenantID, erasure.HumanID)
+}
+
+// catchUp publishes the events committed after the last one seen. On the
+// first connection there is nothing to catch up on.
+func (l *Listener) catchUp(ctx context.Context) {
+	if l.lastID == 0 {
+		return
+	}
+	rows, err := l.events.ListOutboxEventsAfter(ctx, database.ListOutboxEventsAfterParams{
+		After:   l.lastID,
+		MaxRows: int32(max(l.broker.replaySize, 1)),
+	})
+	if err != nil {
+		log.Printf("feed: failed to catch up: %s", err)
+		return
+	}
+	for _, row := range rows {
+		l.broker.Publish(FromOutbox(row))
+		l.lastID = max(l.lastID, row.ID)
+	}
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	service "github.com/kiriksik/TestTaskEffectiveMobile/internal/services"
+)
+
+// @Summary Получение описаний атрибутов
+// @Description	Возвращает реестр пользовательских атрибутов тенанта
+// @Tags	attributes
+// @Produce	json
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {array} models.AttributeDefinitionResponse
+// @Router /api/attributes [get]
+func (ah *ApiHandler) getAttributeDefinitions(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+
+	defs, err := humanService.GetAttributeDefinitions(req.Context())
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, defs)
+}
+
+// @Summary Создание или изменение атрибута
+// @Description	Задаёт тип, обязательность, допустимые значения и правила проверки атрибута. Новые правила применяются к последующим изменениям людей
+// @Tags	attributes
+// @Accept	json
+// @Produce	json
+// @Param	name path string true "Имя атрибута"
+// @Param	request body models.AttributeDefinitionRequest true "Описание атрибута"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {object} models.AttributeDefinitionResponse
+// @Router /api/attributes/{name} [put]
+func (ah *ApiHandler) putAttributeDefinition(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	var reqBodyData models.AttributeDefinitionRequest
+	name := req.PathValue("name")
+	if name == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing name")
+		return
+	}
+
+	if !decodeJSONBody(rw, req, &reqBodyData) {
+		return
+	}
+
+	def, err := humanService.PutAttributeDefinition(req.Context(), name, &reqBodyData)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, def)
+}
+
+// @Summary Удаление атрибута
+// @Description	Удаляет атрибут из реестра вместе с его значениями у всех людей
+// @Tags	attributes
+// @Produce	json
+// @Param	name path string true "Имя атрибута"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {object} models.AttributeDefinitionResponse
+// @Router /api/attributes/{name} [delete]
+func (ah *ApiHandler) deleteAttributeDefinition(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	name := req.PathValue("name")
+	if name == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing name")
+		return
+	}
+
+	def, err := humanService.DeleteAttributeDefinition(req.Context(), name)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, def)
+}
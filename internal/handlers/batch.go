@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/requestid"
+	service "github.com/kiriksik/TestTaskEffectiveMobile/internal/services"
+)
+
+// @Summary Пакетное изменение людей
+// @Description	Выполняет до 1000 операций create, update и delete. Имена обогащаются одним пакетом. В режиме atomic любая ошибка отменяет весь пакет, в режиме best_effort операции применяются независимо. Возвращает 200, если все операции выполнены, иначе 207 с результатом каждой операции
+// @Tags	humans
+// @Accept	json
+// @Produce	json
+// @Param	request body models.BatchRequest true "Операции"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {object} models.BatchResponse
+// @Success	207 {object} models.BatchResponse
+// @Router /api/humans:batch [post]
+func (ah *ApiHandler) batchHumans(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	var reqBodyData models.BatchRequest
+
+	if !decodeJSONBody(rw, req, &reqBodyData) {
+		return
+	}
+
+	results, err := humanService.BatchHumans(req.Context(), &reqBodyData)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	response := models.BatchResponse{Mode: reqBodyData.Mode, Results: make([]models.BatchItemResult, len(results))}
+	for i, result := range results {
+		item := models.BatchItemResult{Index: i, Op: result.Op, Human: result.Human}
+		if result.Err != nil {
+			item.Status = httpStatusFromError(result.Err)
+			item.Code = service.KindOf(result.Err).String()
+			item.Error = service.PublicMessage(result.Err)
+			if item.Error != result.Err.Error() {
+				log.Printf("request %s: batch operation %d: %s", requestid.FromContext(req.Context()), i, result.Err)
+			}
+			item.Errors = service.FieldErrorsOf(result.Err)
+			response.Failed++
+		} else {
+			item.Status = http.StatusOK
+			if result.Op == "create" {
+				item.Status = http.StatusCreated
+			}
+			response.Succeeded++
+		}
+		response.Results[i] = item
+	}
+
+	status := http.StatusOK
+	if response.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	respondWithJson(rw, status, response)
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	service "github.com/kiriksik/TestTaskEffectiveMobile/internal/services"
+)
+
+// @Summary Добавление контакта
+// @Description	Добавляет человеку email, телефон в формате E.164 или почтовый адрес
+// @Tags	contacts
+// @Accept	json
+// @Produce	json
+// @Param	humanID path string true "ID человека"
+// @Param	request body models.ContactRequest true "Данные контакта"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	201 {object} models.ContactResponse
+// @Router /api/humans/{humanID}/contacts [post]
+func (ah *ApiHandler) createContact(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	var reqBodyData models.ContactRequest
+	humanID := req.PathValue("humanID")
+	if humanID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	if !decodeJSONBody(rw, req, &reqBodyData) {
+		return
+	}
+
+	contact, err := humanService.CreateContact(req.Context(), humanID, &reqBodyData)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusCreated, contact)
+}
+
+// @Summary Получение контактов человека
+// @Description	Возвращает все контакты человека
+// @Tags	contacts
+// @Produce	json
+// @Param	humanID path string true "ID человека"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {array} models.ContactResponse
+// @Router /api/humans/{humanID}/contacts [get]
+func (ah *ApiHandler) getContacts(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	humanID := req.PathValue("humanID")
+	if humanID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	contacts, err := humanService.GetContacts(req.Context(), humanID)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, contacts)
+}
+
+// @Summary Получение контакта
+// @Description	Возвращает контакт человека по его ID
+// @Tags	contacts
+// @Produce	json
+// @Param	humanID path string true "ID человека"
+// @Param	contactID path string true "ID контакта"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {object} models.ContactResponse
+// @Router /api/humans/{humanID}/contacts/{contactID} [get]
+func (ah *ApiHandler) getContact(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	humanID := req.PathValue("humanID")
+	contactID := req.PathValue("contactID")
+	if humanID == "" || contactID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	contact, err := humanService.GetContact(req.Context(), humanID, contactID)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, contact)
+}
+
+// @Summary Обновление контакта
+// @Description	Заменяет данные контакта человека
+// @Tags	contacts
+// @Accept	json
+// @Produce	json
+// @Param	humanID path string true "ID человека"
+// @Param	contactID path string true "ID контакта"
+// @Param	request body models.ContactRequest true "Данные контакта"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {object} models.ContactResponse
+// @Router /api/humans/{humanID}/contacts/{contactID} [put]
+func (ah *ApiHandler) updateContact(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	var reqBodyData models.ContactRequest
+	humanID := req.PathValue("humanID")
+	contactID := req.PathValue("contactID")
+	if humanID == "" || contactID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	if !decodeJSONBody(rw, req, &reqBodyData) {
+		return
+	}
+
+	contact, err := humanService.UpdateContact(req.Context(), humanID, contactID, &reqBodyData)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, contact)
+}
+
+// @Summary Удаление контакта
+// @Description	Удаляет контакт человека по его ID
+// @Tags	contacts
+// @Produce	json
+// @Param	humanID path string true "ID человека"
+// @Param	contactID path string true "ID контакта"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {object} models.ContactResponse
+// @Router /api/humans/{humanID}/contacts/{contactID} [delete]
+func (ah *ApiHandler) deleteContact(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	humanID := req.PathValue("humanID")
+	contactID := req.PathValue("contactID")
+	if humanID == "" || contactID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	contact, err := humanService.DeleteContact(req.Context(), humanID, contactID)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, contact)
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// maxJSONBodyBytes bounds the JSON bodies handlers accept.
+const maxJSONBodyBytes = 1 << 20
+
+const invalidJSONCode = "invalid_json"
+
+// decodeJSONBody strictly decodes the application/json body of req into
+// dst: the body must hold exactly one JSON value of at most
+// maxJSONBodyBytes with no fields dst does not know. Otherwise it responds
+// with 415, 413 or 400, pointing at the position of a malformed value, and
+// returns false.
+func decodeJSONBody(rw http.ResponseWriter, req *http.Request, dst any) bool {
+	defer req.Body.Close()
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		respondWithError(rw, req, http.StatusUnsupportedMediaType, "request body must be application/json")
+		return false
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(rw, req.Body, maxJSONBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondWithError(rw, req, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body must be at most %d bytes", tooLarge.Limit))
+			return false
+		}
+		respondWithError(rw, req, http.StatusBadRequest, "failed to read request body")
+		return false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		respondWithProblem(rw, req, newProblem(http.StatusBadRequest, invalidJSONCode, describeJSONError(body, reflect.TypeOf(dst), err)))
+		return false
+	}
+	end := decoder.InputOffset()
+	if trailing := bytes.TrimLeft(body[end:], " \t\r\n"); len(trailing) > 0 {
+		offset := int64(len(body) - len(trailing))
+		respondWithProblem(rw, req, newProblem(http.StatusBadRequest, invalidJSONCode,
+			fmt.Sprintf("request body has data after the JSON value at %s", jsonPosition(body, offset))))
+		return false
+	}
+	return true
+}
+
+// describeJSONError explains why body could not be decoded into a value of
+// type t.
+func describeJSONError(body []byte, t reflect.Type, err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return "request body is empty"
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return fmt.Sprintf("request body ends inside a JSON value at %s", jsonPosition(body, int64(len(body))))
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("malformed JSON at %s: %s", jsonPosition(body, syntaxErr.Offset), syntaxErr)
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return fmt.Sprintf("request body must be %s, got %s", jsonTypeName(typeErr.Type), typeErr.Value)
+		}
+		return fmt.Sprintf("field %q must be %s, got %s at %s", typeErr.Field, jsonTypeName(typeErr.Type), typeErr.Value, jsonPosition(body, typeErr.Offset))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// The decoder has no error type for unknown fields and reports them
+		// only after reading the whole value.
+		field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		if offset, ok := unknownFieldOffset(body, t); ok {
+			return fmt.Sprintf("unknown field %s at %s", field, jsonPosition(body, offset))
+		}
+		return fmt.Sprintf("unknown field %s", field)
+	default:
+		return fmt.Sprintf("malformed JSON: %s", err)
+	}
+}
+
+// unknownFieldOffset returns the offset of the first object key in body
+// that no field of the struct it is decoded into accepts, walking body
+// along t the way the decoder does.
+func unknownFieldOffset(body []byte, t reflect.Type) (int64, bool) {
+	scan := fieldScan{body: body, decoder: json.NewDecoder(bytes.NewReader(body))}
+	offset, found, err := scan.value(t)
+	return offset, found && err == nil
+}
+
+var jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+type fieldScan struct {
+	body    []byte
+	decoder *json.Decoder
+}
+
+// value reads the next value, decoded into t or into any when t is nil,
+// and reports the offset of the first unknown key in it.
+func (s *fieldScan) value(t reflect.Type) (int64, bool, error) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t != nil && reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		// Such values decode their own fields.
+		t = nil
+	}
+	token, err := s.decoder.Token()
+	if err != nil {
+		return 0, false, err
+	}
+	switch token {
+	case json.Delim('{'):
+		for s.decoder.More() {
+			start := s.nextTokenOffset()
+			key, err := s.decoder.Token()
+			if err != nil {
+				return 0, false, err
+			}
+			var elem reflect.Type
+			switch {
+			case t == nil:
+			case t.Kind() == reflect.Map:
+				elem = t.Elem()
+			case t.Kind() == reflect.Struct:
+				var ok bool
+				if elem, ok = structFieldType(t, key.(string)); !ok {
+					return start, true, nil
+				}
+			}
+			if offset, found, err := s.value(elem); found || err != nil {
+				return offset, found, err
+			}
+		}
+	case json.Delim('['):
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+		for s.decoder.More() {
+			if offset, found, err := s.value(elem); found || err != nil {
+				return offset, found, err
+			}
+		}
+	default:
+		return 0, false, nil
+	}
+	// The closing delimiter.
+	_, err = s.decoder.Token()
+	return 0, false, err
+}
+
+// nextTokenOffset returns the offset of the token the decoder reads next.
+func (s *fieldScan) nextTokenOffset() int64 {
+	offset := s.decoder.InputOffset()
+	for offset < int64(len(s.body)) && strings.IndexByte(" \t\r\n,:", s.body[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// structFieldType returns the type of the field of struct t that the
+// decoder fills from key, matching names the way it does.
+func structFieldType(t reflect.Type, key string) (reflect.Type, bool) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if fieldType, ok := structFieldType(embedded, key); ok {
+					return fieldType, true
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if strings.EqualFold(name, key) {
+			return field.Type, true
+		}
+	}
+	return nil, false
+}
+
+// jsonPosition describes a byte offset into body as a 1-based line and
+// column.
+func jsonPosition(body []byte, offset int64) string {
+	offset = min(max(offset, 0), int64(len(body)))
+	before := body[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return fmt.Sprintf("line %d, column %d", line, column)
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Pointer:
+		return jsonTypeName(t.Elem())
+	default:
+		return "a " + t.String()
+	}
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+)
+
+func TestDecodeJSONBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		dst         any
+		status      int
+		code        string
+		detail      string
+	}{
+		{
+			name:        "valid",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"name":"Ivan","surname":"Petrov","attributes":{"x":1}}`,
+			dst:         &models.HumanRequest{},
+			status:      http.StatusOK,
+		},
+		{
+			name:        "not json",
+			contentType: "text/plain",
+			body:        `{"name":"Ivan"}`,
+			dst:         &models.HumanRequest{},
+			status:      http.StatusUnsupportedMediaType,
+			code:        "unsupported_media_type",
+			detail:      "request body must be application/json",
+		},
+		{
+			name:        "too large",
+			contentType: "application/json",
+			body:        `{"name":"` + strings.Repeat("a", maxJSONBodyBytes) + `"}`,
+			dst:         &models.HumanRequest{},
+			status:      http.StatusRequestEntityTooLarge,
+			code:        "request_entity_too_large",
+			detail:      "request body must be at most 1048576 bytes",
+		},
+		{
+			name:        "trailing data",
+			contentType: "application/json",
+			body:        "{\"name\":\"Ivan\"}\n {}",
+			dst:         &models.HumanRequest{},
+			status:      http.StatusBadRequest,
+			code:        invalidJSONCode,
+			detail:      "request body has data after the JSON value at line 2, column 2",
+		},
+		{
+			name:        "unknown field",
+			contentType: "application/json",
+			body:        `{"name":"Ivan","surname":"P","x":1}`,
+			dst:         &models.HumanRequest{},
+			status:      http.StatusBadRequest,
+			code:        invalidJSONCode,
+			detail:      `unknown field "x" at line 1, column 30`,
+		},
+		{
+			name:        "unknown field on a later line",
+			contentType: "application/json",
+			body:        "{\n  \"name\": \"Ivan\",\n  \"x\": 1,\n  \"surname\": \"P\"\n}",
+			dst:         &models.HumanRequest{},
+			status:      http.StatusBadRequest,
+			code:        invalidJSONCode,
+			detail:      `unknown field "x" at line 3, column 3`,
+		},
+		{
+			name:        "unknown field after a map key of the same name",
+			contentType: "application/json",
+			body:        `{"attributes":{"x":1},"x":2}`,
+			dst:         &models.HumanRequest{},
+			status:      http.StatusBadRequest,
+			code:        invalidJSONCode,
+			detail:      `unknown field "x" at line 1, column 23`,
+		},
+		{
+			name:        "unknown field in a nested object",
+			contentType: "application/json",
+			body:        `{"mode":"atomic","operations":[{"op":"create","human":{"Name":"Ivan"}},{"op":"create","human":{"name":"Oleg","x":1}}]}`,
+			dst:         &models.BatchRequest{},
+			status:      http.StatusBadRequest,
+			code:        invalidJSONCode,
+			detail:      `unknown field "x" at line 1, column 110`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/humans", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			rec := httptest.NewRecorder()
+			if ok := decodeJSONBody(rec, req, tc.dst); ok != (tc.status == http.StatusOK) {
+				t.Fatalf("got %v: %s", ok, rec.Body)
+			}
+			if rec.Code != tc.status {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tc.status, rec.Body)
+			}
+			if tc.status == http.StatusOK {
+				return
+			}
+			var problem models.Problem
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.Code != tc.code || problem.Detail != tc.detail {
+				t.Fatalf("got code %q, detail %q, want %q, %q", problem.Code, problem.Detail, tc.code, tc.detail)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	service "github.com/kiriksik/TestTaskEffectiveMobile/internal/services"
+)
+
+// @Summary Поиск дубликатов
+// @Description	Возвращает пары людей, которые вероятно являются одним человеком. Оценка учитывает сходство имени и фамилии, отчество, возраст, пол и страну. При шифровании персональных данных имена сравниваются после расшифровки, поэтому поиск доступен тенантам не больше 10000 людей, для больших возвращается 409
+// @Tags	duplicates
+// @Produce	json
+// @Param	min_score query number false "Минимальная оценка от 0 до 1" default(0.6)
+// @Param	limit query int false "Максимальное количество пар" default(50)
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {array} models.DuplicateCandidate
+// @Router /api/humans/duplicates [get]
+func (ah *ApiHandler) getDuplicates(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+
+	minScore := service.DefaultDuplicateMinScore
+	if raw := req.URL.Query().Get("min_score"); raw != "" {
+		var err error
+		minScore, err = strconv.ParseFloat(raw, 64)
+		if err != nil {
+			respondWithError(rw, req, http.StatusBadRequest, "bad min_score")
+			return
+		}
+	}
+	limit := service.DefaultDuplicateLimit
+	if raw := req.URL.Query().Get("limit"); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil {
+			respondWithError(rw, req, http.StatusBadRequest, "bad limit")
+			return
+		}
+	}
+
+	duplicates, err := humanService.FindDuplicates(req.Context(), minScore, limit)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, duplicates)
+}
+
+// @Summary Слияние людей
+// @Description	Сливает человека source_id в человека из пути: для каждого поля выбирается победитель, контакты, теги и связи переносятся, source_id удаляется
+// @Tags	duplicates
+// @Accept	json
+// @Produce	json
+// @Param	humanID path string true "ID человека, в которого выполняется слияние"
+// @Param	request body models.MergeRequest true "Источник слияния"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {object} models.HumanResponse
+// @Router /api/humans/{humanID}/merge [post]
+func (ah *ApiHandler) mergeHumans(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	var reqBodyData models.MergeRequest
+	humanID := req.PathValue("humanID")
+	if humanID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	if !decodeJSONBody(rw, req, &reqBodyData) {
+		return
+	}
+
+	human, err := humanService.MergeHumans(req.Context(), humanID, &reqBodyData)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, human)
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/requestid"
+	service "github.com/kiriksik/TestTaskEffectiveMobile/internal/services"
+)
+
+const (
+	problemContentType = "application/problem+json"
+	// problemTypePrefix followed by the error code forms the problem type.
+	problemTypePrefix = "urn:humans:problem:"
+)
+
+// problemTitles are the titles of the problem codes whose title is not the
+// text of their status.
+var problemTitles = map[string]string{
+	service.KindValidation.String():          "Invalid request",
+	service.KindInvalidFields.String():       "Invalid fields",
+	service.KindNotFound.String():            "Not found",
+	service.KindConflict.String():            "Conflict",
+	service.KindUpstreamUnavailable.String(): "Upstream service unavailable",
+	service.KindAborted.String():             "Operation aborted",
+	service.KindBusy.String():                "Too many requests",
+	service.KindInternal.String():            "Internal server error",
+	"invalid_tenant_id":                      "Invalid tenant id",
+	"invalid_api_key":                        "Missing or invalid API key",
+	"tenant_mismatch":                        "API key does not belong to tenant",
+	invalidJSONCode:                          "Malformed JSON body",
+}
+
+// httpStatusFromError translates a service error into an HTTP status code.
+func httpStatusFromError(err error) int {
+	switch service.KindOf(err) {
+	case service.KindNotFound:
+		return http.StatusNotFound
+	case service.KindValidation:
+		return http.StatusBadRequest
+	case service.KindInvalidFields:
+		return http.StatusUnprocessableEntity
+	case service.KindConflict:
+		return http.StatusConflict
+	case service.KindUpstreamUnavailable:
+		return http.StatusBadGateway
+	case service.KindAborted:
+		return http.StatusFailedDependency
+	case service.KindBusy:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondWithServiceError describes err to the client without its cause,
+// which is logged together with the request id instead.
+func respondWithServiceError(rw http.ResponseWriter, req *http.Request, err error) {
+	status := httpStatusFromError(err)
+	detail := service.PublicMessage(err)
+	if detail != err.Error() || status >= http.StatusInternalServerError {
+		log.Printf("request %s: %s %s: %s", requestid.FromContext(req.Context()), req.Method, req.URL.Path, err)
+	}
+	problem := newProblem(status, service.KindOf(err).String(), detail)
+	problem.Errors = service.FieldErrorsOf(err)
+	respondWithProblem(rw, req, problem)
+}
+
+// respondWithError reports a problem with the request itself, coded after
+// the status, such as bad_request.
+func respondWithError(rw http.ResponseWriter, req *http.Request, status int, detail string) {
+	respondWithProblem(rw, req, newProblem(status, statusCode(status), detail))
+}
+
+func respondWithProblem(rw http.ResponseWriter, req *http.Request, problem models.Problem) {
+	problem.Instance = req.URL.Path
+	writeProblem(rw, problem)
+}
+
+func newProblem(status int, code, detail string) models.Problem {
+	title, ok := problemTitles[code]
+	if !ok {
+		title = http.StatusText(status)
+	}
+	return models.Problem{
+		Type:   problemTypePrefix + code,
+		Title:  title,
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// writeProblem writes problem with the request id set by withRequestID.
+func writeProblem(rw http.ResponseWriter, problem models.Problem) {
+	problem.RequestID = rw.Header().Get(requestid.Header)
+	body, _ := json.Marshal(problem)
+	rw.Header().Set("Content-Type", problemContentType)
+	rw.WriteHeader(problem.Status)
+	rw.Write(body)
+}
+
+// statusCode turns a status into an error code: 415 becomes
+// unsupported_media_type.
+func statusCode(status int) string {
+	if status == http.StatusInternalServerError {
+		return service.KindInternal.String()
+	}
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
+
+// withProblemRoutes answers the requests mux has no route for with a
+// problem instead of the mux's plain text: not_found for unknown paths and
+// method_not_allowed, with the Allow header, for known paths called with
+// another method.
+func withProblemRoutes(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		handler, pattern := mux.Handler(req)
+		if pattern != "" {
+			mux.ServeHTTP(rw, req)
+			return
+		}
+		unrouted := &unroutedResponse{header: http.Header{}}
+		handler.ServeHTTP(unrouted, req)
+		if unrouted.status == http.StatusMethodNotAllowed {
+			allow := unrouted.header.Get("Allow")
+			rw.Header().Set("Allow", allow)
+			respondWithError(rw, req, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed here, use %s", req.Method, allow))
+			return
+		}
+		respondWithError(rw, req, http.StatusNotFound, fmt.Sprintf("no route for %s %s", req.Method, req.URL.Path))
+	})
+}
+
+// unroutedResponse keeps the status and headers of the mux's own answer to
+// a request without a route and drops its body.
+type unroutedResponse struct {
+	header http.Header
+	status int
+}
+
+func (r *unroutedResponse) Header() http.Header { return r.header }
+
+func (r *unroutedResponse) WriteHeader(status int) { r.status = status }
+
+func (r *unroutedResponse) Write(body []byte) (int, error) { return len(body), nil }
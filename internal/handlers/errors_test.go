@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/requestid"
+)
+
+func TestUnroutedRequests(t *testing.T) {
+	mux := newTestMux(nil)
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		code   string
+		allow  string
+	}{
+		{"unknown path", "GET", "/api/unknown", http.StatusNotFound, "not_found", ""},
+		{"unknown sub-resource", "GET", "/api/humans/1c235c4b-e3f4-4837-b12b-a2739c11d1d8/unknown", http.StatusNotFound, "not_found", ""},
+		{"wrong method", "PATCH", "/api/humans", http.StatusMethodNotAllowed, "method_not_allowed", "GET, HEAD, POST"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serve(t, mux, tc.method, tc.path, nil, nil)
+			if rec.Code != tc.status {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tc.status, rec.Body)
+			}
+			if got := rec.Header().Get("Content-Type"); got != problemContentType {
+				t.Fatalf("got Content-Type %q", got)
+			}
+			if got := rec.Header().Get("Allow"); got != tc.allow {
+				t.Fatalf("got Allow %q, want %q", got, tc.allow)
+			}
+			var problem models.Problem
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.Code != tc.code || problem.Status != tc.status || problem.Instance != tc.path {
+				t.Fatalf("got %+v", problem)
+			}
+			if problem.RequestID == "" || problem.RequestID != rec.Header().Get(requestid.Header) {
+				t.Fatalf("got request id %q, header %q", problem.RequestID, rec.Header().Get(requestid.Header))
+			}
+		})
+	}
+}
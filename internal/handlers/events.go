@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/feed"
+	service "github.com/kiriksik/TestTaskEffectiveMobile/internal/services"
+)
+
+const eventStreamKeepAlive = 15 * time.Second
+
+// @Summary Поток изменений людей
+// @Description	Server-Sent Events: событие HumanCreated, HumanUpdated или HumanDeleted на каждое изменение человека, подходящего под фильтры списка. id события можно передать в Last-Event-ID при переподключении, чтобы получить пропущенные события из буфера последних событий. Если клиент не успевает читать, поток закрывается и клиенту нужно переподключиться
+// @Tags	humans
+// @Produce	text/event-stream
+// @Param	Last-Event-ID header string false "ID последнего полученного события"
+// @Param	created_since query string false "Создан не раньше (RFC 3339)"
+// @Param	created_until query string false "Создан раньше (RFC 3339)"
+// @Param	updated_since query string false "Обновлён не раньше (RFC 3339)"
+// @Param	updated_until query string false "Обновлён раньше (RFC 3339)"
+// @Param	tag query []string false "Теги" collectionFormat(multi)
+// @Param	tag_match query string false "Совпадение по любому или по всем тегам" Enums(any, all) default(any)
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {object} models.HumanEvent
+// @Router /api/humans/events [get]
+func (ah *ApiHandler) streamHumanEvents(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+
+	filter, err := parseHumanFilter(req)
+	if err != nil {
+		respondWithError(rw, req, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sub, err := humanService.SubscribeHumanEvents(req.Context(), filter, req.Header.Get("Last-Event-ID"))
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+	defer sub.Close()
+
+	rc := http.NewResponseController(rw)
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("X-Accel-Buffering", "no")
+	rw.WriteHeader(http.StatusOK)
+	for _, event := range sub.Replay {
+		writeServerSentEvent(rw, event)
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(eventStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				// Too far behind; the client resumes from its last event.
+				return
+			}
+			writeServerSentEvent(rw, event)
+		case <-keepAlive.C:
+			fmt.Fprint(rw, ": keep-alive\n\n")
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeServerSentEvent(rw http.ResponseWriter, event feed.Event) {
+	fmt.Fprintf(rw, "id: %d\nevent: %s\n", event.ID, event.Type)
+	for _, line := range bytes.Split(event.Payload, []byte("\n")) {
+		fmt.Fprintf(rw, "data: %s\n", line)
+	}
+	fmt.Fprint(rw, "\n")
+}
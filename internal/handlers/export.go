@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/export"
+	service "github.com/kiriksik/TestTaskEffectiveMobile/internal/services"
+)
+
+// @Summary Выгрузка людей
+// @Description	Потоково выгружает людей, подходящих под фильтры списка, в CSV, NDJSON или XLSX. Фильтр по атрибутам задаётся параметрами вида attr.<имя>=<значение>
+// @Tags	humans
+// @Produce	text/csv
+// @Produce	application/x-ndjson
+// @Produce	application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param	format query string false "Формат" Enums(csv, ndjson, xlsx) default(csv)
+// @Param	columns query string false "Колонки через запятую: id, name, surname, patronymic, age, gender, country, created_at, updated_at, attributes"
+// @Param	created_since query string false "Создан не раньше (RFC 3339)"
+// @Param	created_until query string false "Создан раньше (RFC 3339)"
+// @Param	updated_since query string false "Обновлён не раньше (RFC 3339)"
+// @Param	updated_until query string false "Обновлён раньше (RFC 3339)"
+// @Param	tag query []string false "Теги" collectionFormat(multi)
+// @Param	tag_match query string false "Совпадение по любому или по всем тегам" Enums(any, all) default(any)
+// @Param	sort query string false "Сортировка" Enums(created_at, -created_at, updated_at, -updated_at)
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {file} file
+// @Router /api/humans/export [get]
+func (ah *ApiHandler) exportHumans(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+
+	format := req.URL.Query().Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+	contentType, ok := export.ContentType(format)
+	if !ok {
+		respondWithError(rw, req, http.StatusBadRequest, fmt.Sprintf("unsupported export format %q", format))
+		return
+	}
+	columns, err := service.ParseExportColumns(req.URL.Query().Get("columns"))
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+	filter, err := parseHumanFilter(req)
+	if err != nil {
+		respondWithError(rw, req, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// The response starts with the first row, so errors found before it can
+	// still be reported with a status code.
+	var (
+		writer  export.Writer
+		started bool
+	)
+	start := func() error {
+		started = true
+		rw.Header().Set("Content-Type", contentType)
+		rw.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="humans.%s"`, format))
+		rw.WriteHeader(http.StatusOK)
+		var err error
+		writer, err = export.NewWriter(format, rw, columns)
+		return err
+	}
+	err = humanService.ExportHumans(req.Context(), filter, columns, func(values []any) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return writer.WriteRow(values)
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		if !started {
+			respondWithServiceError(rw, req, err)
+			return
+		}
+		// Abort the connection so the client does not take a truncated
+		// file for a complete one.
+		log.Printf("export aborted: %s", err)
+		panic(http.ErrAbortHandler)
+	}
+}
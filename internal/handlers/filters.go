@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+)
+
+// parseHumanFilter reads the list filters shared by the endpoints that
+// select humans. Timestamps are expected in RFC 3339, attribute filters
+// are passed as attr.<name>=<value>.
+func parseHumanFilter(req *http.Request) (models.HumanFilter, error) {
+	query := req.URL.Query()
+	filter := models.HumanFilter{
+		Tags:     query["tag"],
+		TagMatch: query.Get("tag_match"),
+		Sort:     query.Get("sort"),
+	}
+
+	timeParams := []struct {
+		name  string
+		value **time.Time
+	}{
+		{"created_since", &filter.CreatedSince},
+		{"created_until", &filter.CreatedUntil},
+		{"updated_since", &filter.UpdatedSince},
+		{"updated_until", &filter.UpdatedUntil},
+	}
+	for key, values := range query {
+		name, ok := strings.CutPrefix(key, "attr.")
+		if !ok {
+			continue
+		}
+		if filter.Attributes == nil {
+			filter.Attributes = make(map[string]string)
+		}
+		filter.Attributes[name] = values[0]
+	}
+	for _, param := range timeParams {
+		raw := query.Get(param.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return models.HumanFilter{}, fmt.Errorf("bad %s: expected RFC 3339 timestamp", param.name)
+		}
+		*param.value = &t
+	}
+	return filter, nil
+}
+
+// parseHumanExpand reads the comma separated expand parameter.
+func parseHumanExpand(req *http.Request) (models.HumanExpand, error) {
+	var expand models.HumanExpand
+	raw := req.URL.Query().Get("expand")
+	if raw == "" {
+		return expand, nil
+	}
+	for _, name := range strings.Split(raw, ",") {
+		switch strings.TrimSpace(name) {
+		case "contacts":
+			expand.Contacts = true
+		default:
+			return models.HumanExpand{}, fmt.Errorf("unknown expand %q", name)
+		}
+	}
+	return expand, nil
+}
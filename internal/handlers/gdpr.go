@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	service "github.com/kiriksik/TestTaskEffectiveMobile/internal/services"
+)
+
+// @Summary Выгрузка данных субъекта
+// @Description	Возвращает все хранимые данные о человеке: запись, контакты, теги, атрибуты, связи, выведенные из имени данные, а также ещё хранимые события изменений и доставки вебхуков с их телами
+// @Tags	gdpr
+// @Produce	json
+// @Param	humanID path string true "ID человека"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {object} models.SubjectDataExport
+// @Router /api/humans/{humanID}/export [get]
+func (ah *ApiHandler) exportSubjectData(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	humanID := req.PathValue("humanID")
+	if humanID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	export, err := humanService.ExportSubjectData(req.Context(), humanID)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	rw.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="human-%s.json"`, export.Human.ID))
+	respondWithJson(rw, http.StatusOK, export)
+}
+
+// @Summary Анонимизация человека
+// @Description	Необратимо заменяет имя, фамилию и отчество случайными токенами, удаляет контакты и атрибуты. Возраст, пол и страна сохраняются для статистики. Удаляются также прежние события outbox и доставки вебхуков человека и события в буфере повтора потока изменений. Уже отправленное наружу не удаляется: сообщения в потоке JetStream хранятся до истечения NATS_MAX_AGE, а полученное получателями вебхуков, HTTP-приёмником и подписчиками потока остаётся у них
+// @Tags	gdpr
+// @Produce	json
+// @Param	humanID path string true "ID человека"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {object} models.HumanResponse
+// @Router /api/humans/{humanID}/anonymize [post]
+func (ah *ApiHandler) anonymizeHuman(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	humanID := req.PathValue("humanID")
+	if humanID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	human, err := humanService.AnonymizeHuman(req.Context(), humanID)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, human)
+}
@@ -2,12 +2,13 @@ package handler
 
 import (
 	"encoding/json"
-	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/kiriksik/TestTaskEffectiveMobile/config"
 	_ "github.com/kiriksik/TestTaskEffectiveMobile/docs"
 	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/requestid"
 	service "github.com/kiriksik/TestTaskEffectiveMobile/internal/services"
 )
 
@@ -15,42 +16,62 @@ type ApiHandler struct {
 	ApiCfg *config.ApiConfig
 }
 
-type responseError struct {
-	Err string `json:"error"`
-}
-
 func InitializeMux(ac *config.ApiConfig) *http.ServeMux {
 
 	ah := &ApiHandler{ApiCfg: ac}
-	serveMux := http.NewServeMux()
+	apiMux := http.NewServeMux()
+
+	apiMux.HandleFunc("GET /api/humans/stats", ah.getHumanStats)
+	apiMux.HandleFunc("GET /api/humans/export", ah.exportHumans)
+	apiMux.HandleFunc("GET /api/humans/duplicates", ah.getDuplicates)
+	apiMux.HandleFunc("GET /api/humans/events", ah.streamHumanEvents)
+	apiMux.HandleFunc("GET /api/humans/{humanID}", ah.getHumanByID)
+	apiMux.HandleFunc("POST /api/humans", ah.createHuman)
+	apiMux.HandleFunc("POST /api/humans:batch", ah.batchHumans)
+	apiMux.HandleFunc("GET /api/humans", ah.getHumans)
+	apiMux.HandleFunc("PUT /api/humans/{humanID}", ah.updateHuman)
+	apiMux.HandleFunc("DELETE /api/humans/{humanID}", ah.deleteHuman)
+	apiMux.HandleFunc("POST /api/humans/{humanID}/merge", ah.mergeHumans)
+	apiMux.HandleFunc("GET /api/humans/{humanID}/export", ah.exportSubjectData)
+	apiMux.HandleFunc("POST /api/humans/{humanID}/anonymize", ah.anonymizeHuman)
+	apiMux.HandleFunc("POST /api/humans/{humanID}/relatives", ah.createRelationship)
+	apiMux.HandleFunc("GET /api/humans/{humanID}/relatives", ah.getRelatives)
+	apiMux.HandleFunc("DELETE /api/humans/{humanID}/relatives/{relativeID}", ah.deleteRelationship)
+	apiMux.HandleFunc("GET /api/humans/{humanID}/family-tree", ah.getFamilyTree)
+	apiMux.HandleFunc("POST /api/humans/{humanID}/contacts", ah.createContact)
+	apiMux.HandleFunc("GET /api/humans/{humanID}/contacts", ah.getContacts)
+	apiMux.HandleFunc("GET /api/humans/{humanID}/contacts/{contactID}", ah.getContact)
+	apiMux.HandleFunc("PUT /api/humans/{humanID}/contacts/{contactID}", ah.updateContact)
+	apiMux.HandleFunc("DELETE /api/humans/{humanID}/contacts/{contactID}", ah.deleteContact)
+	apiMux.HandleFunc("POST /api/humans/{humanID}/tags", ah.attachTags)
+	apiMux.HandleFunc("DELETE /api/humans/{humanID}/tags/{tag}", ah.detachTag)
+	apiMux.HandleFunc("GET /api/attributes", ah.getAttributeDefinitions)
+	apiMux.HandleFunc("PUT /api/attributes/{name}", ah.putAttributeDefinition)
+	apiMux.HandleFunc("DELETE /api/attributes/{name}", ah.deleteAttributeDefinition)
+	apiMux.HandleFunc("POST /api/imports", ah.createImport)
+	apiMux.HandleFunc("GET /api/imports/{importID}", ah.getImport)
+	apiMux.HandleFunc("POST /api/webhooks", ah.createWebhook)
+	apiMux.HandleFunc("GET /api/webhooks", ah.getWebhooks)
+	apiMux.HandleFunc("GET /api/webhooks/{webhookID}", ah.getWebhook)
+	apiMux.HandleFunc("PATCH /api/webhooks/{webhookID}", ah.updateWebhook)
+	apiMux.HandleFunc("DELETE /api/webhooks/{webhookID}", ah.deleteWebhook)
+	apiMux.HandleFunc("GET /api/webhooks/{webhookID}/deliveries", ah.getWebhookDeliveries)
+	apiMux.HandleFunc("GET /api/webhooks/{webhookID}/deliveries/{deliveryID}", ah.getWebhookDelivery)
+	apiMux.HandleFunc("POST /api/webhooks/{webhookID}/deliveries/{deliveryID}/redeliver", ah.redeliverWebhook)
 
-	serveMux.HandleFunc("GET /api/humans/{humanID}", ah.getHumanByID)
-	serveMux.HandleFunc("POST /api/humans", ah.createHuman)
-	serveMux.HandleFunc("GET /api/humans", ah.getHumans)
-	serveMux.HandleFunc("PUT /api/humans/{humanID}", ah.updateHuman)
-	serveMux.HandleFunc("DELETE /api/humans/{humanID}", ah.deleteHuman)
+	serveMux := http.NewServeMux()
+	serveMux.Handle("/api/", withRequestID(ah.withTenant(withProblemRoutes(apiMux))))
 	return serveMux
 }
 
-func respondWithError(rw http.ResponseWriter, code int, errorMessage string) {
-
-	rw.Header().Set("Content-Type", "application/json")
-
-	rw.WriteHeader(code)
-
-	responseError := responseError{Err: errorMessage}
-	jsonErr, _ := json.Marshal(responseError)
-
-	rw.Write(jsonErr)
-}
-
 func respondWithJson(rw http.ResponseWriter, code int, payload interface{}) {
 
 	rw.Header().Set("Content-Type", "application/json")
 
 	encodedJson, err := json.Marshal(payload)
 	if err != nil {
-		respondWithError(rw, http.StatusInternalServerError, fmt.Sprintf("error marshalling json: %s", err))
+		log.Printf("request %s: failed to encode response: %s", rw.Header().Get(requestid.Header), err)
+		writeProblem(rw, newProblem(http.StatusInternalServerError, service.KindInternal.String(), "failed to encode response"))
 		return
 	}
 
@@ -59,32 +80,31 @@ func respondWithJson(rw http.ResponseWriter, code int, payload interface{}) {
 }
 
 // @Summary Создание человека
-// @Description	Создаёт человека по полям имени, фамилии и отчества(необязательно)
+// @Description	Создаёт человека по полям имени, фамилии и отчества(необязательно). Имя и фамилия обязательны; каждое поле — до 100 латинских или кириллических букв, части можно соединять дефисом или апострофом. При ошибках в полях возвращает 422 со списком ошибок
 // @Tags	humans
 // @Accept	json
 // @Produce	json
 // @Param	request body models.HumanRequest true "Данные человека"
 // @Success	201 {object} models.HumanResponse
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Failure	422 {object} models.Problem
 // @Router /api/humans [post]
 func (ah *ApiHandler) createHuman(rw http.ResponseWriter, req *http.Request) {
 
 	humanService := service.UserService{ApiConfig: ah.ApiCfg}
 	var reqBodyData models.HumanRequest
 
-	err := json.NewDecoder(req.Body).Decode(&reqBodyData)
-	defer req.Body.Close()
-	if err != nil {
-		respondWithError(rw, http.StatusBadRequest, fmt.Sprintf("error marshalling json: %s", err))
+	if !decodeJSONBody(rw, req, &reqBodyData) {
 		return
 	}
 
-	human, status, err := humanService.CreateHuman(req.Context(), &reqBodyData)
+	human, err := humanService.CreateHuman(req.Context(), &reqBodyData)
 	if err != nil {
-		respondWithError(rw, status, err.Error())
+		respondWithServiceError(rw, req, err)
 		return
 	}
 
-	respondWithJson(rw, status, human)
+	respondWithJson(rw, http.StatusCreated, human)
 }
 
 // @Summary Удаление человека
@@ -94,22 +114,23 @@ func (ah *ApiHandler) createHuman(rw http.ResponseWriter, req *http.Request) {
 // @Produce	json
 // @Param	humanID path string true "ID человека"
 // @Success	201 {object} models.HumanResponse
+// @Param	X-Tenant-ID header string false "ID тенанта"
 // @Router /api/humans [delete]
 func (ah *ApiHandler) deleteHuman(rw http.ResponseWriter, req *http.Request) {
 	humanService := service.UserService{ApiConfig: ah.ApiCfg}
 	humanID := req.PathValue("humanID")
 	if humanID == "" {
-		respondWithError(rw, http.StatusBadRequest, "missing id")
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
 		return
 	}
 
-	human, status, err := humanService.DeleteHuman(req.Context(), humanID)
+	human, err := humanService.DeleteHuman(req.Context(), humanID)
 	if err != nil {
-		respondWithError(rw, status, err.Error())
+		respondWithServiceError(rw, req, err)
 		return
 	}
 
-	respondWithJson(rw, status, human)
+	respondWithJson(rw, http.StatusOK, human)
 }
 
 // @Summary Получение человека по ID
@@ -117,73 +138,99 @@ func (ah *ApiHandler) deleteHuman(rw http.ResponseWriter, req *http.Request) {
 // @Tags	humans
 // @Produce	json
 // @Param	humanID path string true "ID человека"
+// @Param	expand query string false "Связанные ресурсы" Enums(contacts)
 // @Success	200 {object} models.HumanResponse
+// @Param	X-Tenant-ID header string false "ID тенанта"
 // @Router /api/humans/{humanID} [get]
 func (ah *ApiHandler) getHumanByID(rw http.ResponseWriter, req *http.Request) {
 	humanService := service.UserService{ApiConfig: ah.ApiCfg}
 	humanID := req.PathValue("humanID")
 	if humanID == "" {
-		respondWithError(rw, http.StatusBadRequest, "missing id")
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
 		return
 	}
-	// fmt.Println(humanID)
-	human, status, err := humanService.GetHumanByID(req.Context(), humanID)
+	expand, err := parseHumanExpand(req)
 	if err != nil {
-		respondWithError(rw, status, err.Error())
+		respondWithError(rw, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	human, err := humanService.GetHumanByID(req.Context(), humanID, expand)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
 		return
 	}
 
-	respondWithJson(rw, status, human)
+	respondWithJson(rw, http.StatusOK, human)
 }
 
 // @Summary Получение списка людей
-// @Description	Возвращает всех человека
+// @Description	Возвращает всех людей, подходящих под фильтры. Фильтр по атрибутам задаётся параметрами вида attr.<имя>=<значение>
 // @Tags	humans
 // @Produce	json
+// @Param	created_since query string false "Создан не раньше (RFC 3339)"
+// @Param	created_until query string false "Создан раньше (RFC 3339)"
+// @Param	updated_since query string false "Обновлён не раньше (RFC 3339)"
+// @Param	updated_until query string false "Обновлён раньше (RFC 3339)"
+// @Param	tag query []string false "Теги" collectionFormat(multi)
+// @Param	tag_match query string false "Совпадение по любому или по всем тегам" Enums(any, all) default(any)
+// @Param	sort query string false "Сортировка" Enums(created_at, -created_at, updated_at, -updated_at)
+// @Param	expand query string false "Связанные ресурсы" Enums(contacts)
 // @Success	200 {array} models.HumanResponse
+// @Param	X-Tenant-ID header string false "ID тенанта"
 // @Router /api/humans [get]
 func (ah *ApiHandler) getHumans(rw http.ResponseWriter, req *http.Request) {
 	humanService := service.UserService{ApiConfig: ah.ApiCfg}
 
-	humans, status, err := humanService.GetHumans(req.Context())
+	filter, err := parseHumanFilter(req)
+	if err != nil {
+		respondWithError(rw, req, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	expand, err := parseHumanExpand(req)
+	if err != nil {
+		respondWithError(rw, req, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	humans, err := humanService.GetHumans(req.Context(), filter, expand)
 	if err != nil {
-		respondWithError(rw, status, err.Error())
+		respondWithServiceError(rw, req, err)
 		return
 	}
 
-	respondWithJson(rw, status, humans)
+	respondWithJson(rw, http.StatusOK, humans)
 }
 
 // @Summary Обновление человека
-// @Description	Обновляет данные человека по его ID
+// @Description	Обновляет данные человека по его ID. Поля проверяются так же, как при создании; при ошибках возвращает 422 со списком ошибок
 // @Tags	humans
 // @Accept	json
 // @Produce	json
 // @Param	humanID path string true "ID человека"
 // @Param	request body models.HumanRequest true "данные человека"
 // @Success	200 {object} models.HumanResponse
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Failure	422 {object} models.Problem
 // @Router /api/humans/{humanID} [put]
 func (ah *ApiHandler) updateHuman(rw http.ResponseWriter, req *http.Request) {
 	humanService := service.UserService{ApiConfig: ah.ApiCfg}
 	var reqBodyData models.HumanRequest
 	humanID := req.PathValue("humanID")
 	if humanID == "" {
-		respondWithError(rw, http.StatusBadRequest, "missing id")
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
 		return
 	}
 
-	err := json.NewDecoder(req.Body).Decode(&reqBodyData)
-	defer req.Body.Close()
-	if err != nil {
-		respondWithError(rw, http.StatusBadRequest, fmt.Sprintf("error marshalling json: %s", err))
+	if !decodeJSONBody(rw, req, &reqBodyData) {
 		return
 	}
 
-	human, status, err := humanService.UpdateHuman(req.Context(), &reqBodyData, humanID)
+	human, err := humanService.UpdateHuman(req.Context(), &reqBodyData, humanID)
 	if err != nil {
-		respondWithError(rw, status, err.Error())
+		respondWithServiceError(rw, req, err)
 		return
 	}
 
-	respondWithJson(rw, status, human)
+	respondWithJson(rw, http.StatusOK, human)
 }
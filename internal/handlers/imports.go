@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	service "github.com/kiriksik/TestTaskEffectiveMobile/internal/services"
+)
+
+// importFormats maps the accepted content types to import formats.
+var importFormats = map[string]string{
+	"text/csv":             "csv",
+	"application/x-ndjson": "ndjson",
+	"application/ndjson":   "ndjson",
+	"application/jsonl":    "ndjson",
+}
+
+// @Summary Импорт людей из файла
+// @Description	Принимает CSV с заголовком (name, surname, patronymic) или NDJSON с объектами людей и запускает фоновую загрузку. Каждая строка создаётся так же, как через POST /api/humans, с проверкой и обогащением. Процесс выполняет не больше 4 импортов одновременно, следующие получают 429. Импорт, прерванный остановкой процесса, помечается как failed; возобновить его нельзя, файл нужно загрузить снова
+// @Tags	imports
+// @Accept	plain
+// @Produce	json
+// @Param	format query string false "Формат файла, по умолчанию определяется по Content-Type" Enums(csv, ndjson)
+// @Param	request body string true "Содержимое файла"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	202 {object} models.ImportJobResponse
+// @Router /api/imports [post]
+func (ah *ApiHandler) createImport(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+
+	format := req.URL.Query().Get("format")
+	if format == "" {
+		mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if err != nil || importFormats[mediaType] == "" {
+			respondWithError(rw, req, http.StatusUnsupportedMediaType, "expected text/csv or application/x-ndjson body, or a format parameter")
+			return
+		}
+		format = importFormats[mediaType]
+	}
+
+	body := http.MaxBytesReader(rw, req.Body, service.MaxImportBytes)
+	defer body.Close()
+
+	job, err := humanService.StartImport(req.Context(), format, body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondWithError(rw, req, http.StatusRequestEntityTooLarge, fmt.Sprintf("import file must be at most %d bytes", tooLarge.Limit))
+			return
+		}
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	rw.Header().Set("Location", "/api/imports/"+job.ID)
+	respondWithJson(rw, http.StatusAccepted, job)
+}
+
+// @Summary Состояние импорта
+// @Description	Возвращает статус импорта, счётчики строк и ошибки по строкам
+// @Tags	imports
+// @Produce	json
+// @Param	importID path string true "ID импорта"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {object} models.ImportJobResponse
+// @Router /api/imports/{importID} [get]
+func (ah *ApiHandler) getImport(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	importID := req.PathValue("importID")
+	if importID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	job, err := humanService.GetImport(req.Context(), importID)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, job)
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	service "github.com/kiriksik/TestTaskEffectiveMobile/internal/services"
+)
+
+// @Summary Добавление родственной связи
+// @Description	Связывает человека с родственником. Тип связи описывает, кем родственник приходится человеку: parent, child, spouse или sibling
+// @Tags	relationships
+// @Accept	json
+// @Produce	json
+// @Param	humanID path string true "ID человека"
+// @Param	request body models.RelationshipRequest true "Данные связи"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	201 {array} models.RelationshipResponse
+// @Router /api/humans/{humanID}/relatives [post]
+func (ah *ApiHandler) createRelationship(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	var reqBodyData models.RelationshipRequest
+	humanID := req.PathValue("humanID")
+	if humanID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	if !decodeJSONBody(rw, req, &reqBodyData) {
+		return
+	}
+
+	relationships, err := humanService.CreateRelationship(req.Context(), humanID, &reqBodyData)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusCreated, relationships)
+}
+
+// @Summary Удаление родственной связи
+// @Description	Удаляет связь заданного типа между человеком и родственником, а при bidirectional=true и обратную связь
+// @Tags	relationships
+// @Produce	json
+// @Param	humanID path string true "ID человека"
+// @Param	relativeID path string true "ID родственника"
+// @Param	type query string true "Тип связи" Enums(parent, child, spouse, sibling)
+// @Param	bidirectional query bool false "Удалить и обратную связь"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {array} models.RelationshipResponse
+// @Router /api/humans/{humanID}/relatives/{relativeID} [delete]
+func (ah *ApiHandler) deleteRelationship(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	humanID := req.PathValue("humanID")
+	relativeID := req.PathValue("relativeID")
+	if humanID == "" || relativeID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	bidirectional := false
+	if raw := req.URL.Query().Get("bidirectional"); raw != "" {
+		var err error
+		bidirectional, err = strconv.ParseBool(raw)
+		if err != nil {
+			respondWithError(rw, req, http.StatusBadRequest, "bad bidirectional")
+			return
+		}
+	}
+
+	relationships, err := humanService.DeleteRelationship(req.Context(), humanID, relativeID, req.URL.Query().Get("type"), bidirectional)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, relationships)
+}
+
+// @Summary Получение родственников
+// @Description	Возвращает родственников человека с типом связи
+// @Tags	relationships
+// @Produce	json
+// @Param	humanID path string true "ID человека"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {array} models.RelativeResponse
+// @Router /api/humans/{humanID}/relatives [get]
+func (ah *ApiHandler) getRelatives(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	humanID := req.PathValue("humanID")
+	if humanID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	relatives, err := humanService.GetRelatives(req.Context(), humanID)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, relatives)
+}
+
+// @Summary Семейное дерево
+// @Description	Возвращает всех людей, связанных с человеком не более чем через depth связей, и связи между ними
+// @Tags	relationships
+// @Produce	json
+// @Param	humanID path string true "ID человека"
+// @Param	depth query int false "Глубина обхода" default(3)
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {object} models.FamilyTreeResponse
+// @Router /api/humans/{humanID}/family-tree [get]
+func (ah *ApiHandler) getFamilyTree(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	humanID := req.PathValue("humanID")
+	if humanID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	depth := service.DefaultFamilyTreeDepth
+	if raw := req.URL.Query().Get("depth"); raw != "" {
+		var err error
+		depth, err = strconv.Atoi(raw)
+		if err != nil {
+			respondWithError(rw, req, http.StatusBadRequest, "bad depth")
+			return
+		}
+	}
+
+	tree, err := humanService.GetFamilyTree(req.Context(), humanID, depth)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, tree)
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/requestid"
+)
+
+// withRequestID gives every request an id, keeping the one the caller sent
+// in X-Request-ID when it is well formed. The id is stored in the request
+// context and echoed in the X-Request-ID response header.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(requestid.Header)
+		if !requestid.ValidID(id) {
+			id = requestid.New()
+		}
+		rw.Header().Set(requestid.Header, id)
+		next.ServeHTTP(rw, req.WithContext(requestid.WithID(req.Context(), id)))
+	})
+}
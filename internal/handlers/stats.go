@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	service "github.com/kiriksik/TestTaskEffectiveMobile/internal/services"
+)
+
+// @Summary Статистика по людям
+// @Description	Возвращает распределение по полу и странам, средний возраст по странам и гистограмму возрастов. Принимает те же фильтры, что и список людей, включая attr.<имя>=<значение>
+// @Tags	humans
+// @Produce	json
+// @Param	bucket_width query int false "Ширина возрастного интервала" default(10)
+// @Param	created_since query string false "Создан не раньше (RFC 3339)"
+// @Param	created_until query string false "Создан раньше (RFC 3339)"
+// @Param	updated_since query string false "Обновлён не раньше (RFC 3339)"
+// @Param	updated_until query string false "Обновлён раньше (RFC 3339)"
+// @Param	tag query []string false "Теги" collectionFormat(multi)
+// @Param	tag_match query string false "Совпадение по любому или по всем тегам" Enums(any, all) default(any)
+// @Success	200 {object} models.HumanStatsResponse
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Router /api/humans/stats [get]
+func (ah *ApiHandler) getHumanStats(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+
+	filter, err := parseHumanFilter(req)
+	if err != nil {
+		respondWithError(rw, req, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bucketWidth := service.DefaultAgeBucketWidth
+	if raw := req.URL.Query().Get("bucket_width"); raw != "" {
+		bucketWidth, err = strconv.Atoi(raw)
+		if err != nil {
+			respondWithError(rw, req, http.StatusBadRequest, "bad bucket_width")
+			return
+		}
+	}
+
+	stats, err := humanService.GetHumanStats(req.Context(), filter, bucketWidth)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, stats)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	service "github.com/kiriksik/TestTaskEffectiveMobile/internal/services"
+)
+
+// @Summary Добавление тегов
+// @Description	Добавляет человеку теги. Новые теги создаются автоматически
+// @Tags	tags
+// @Accept	json
+// @Produce	json
+// @Param	humanID path string true "ID человека"
+// @Param	request body models.TagsRequest true "Теги"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {object} models.HumanResponse
+// @Router /api/humans/{humanID}/tags [post]
+func (ah *ApiHandler) attachTags(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	var reqBodyData models.TagsRequest
+	humanID := req.PathValue("humanID")
+	if humanID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	if !decodeJSONBody(rw, req, &reqBodyData) {
+		return
+	}
+
+	human, err := humanService.AttachTags(req.Context(), humanID, &reqBodyData)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, human)
+}
+
+// @Summary Удаление тега
+// @Description	Снимает тег с человека
+// @Tags	tags
+// @Produce	json
+// @Param	humanID path string true "ID человека"
+// @Param	tag path string true "Тег"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {object} models.HumanResponse
+// @Router /api/humans/{humanID}/tags/{tag} [delete]
+func (ah *ApiHandler) detachTag(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	humanID := req.PathValue("humanID")
+	tag := req.PathValue("tag")
+	if humanID == "" || tag == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	human, err := humanService.DetachTag(req.Context(), humanID, tag)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, human)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/tenant"
+)
+
+const tenantHeader = "X-Tenant-ID"
+
+// withTenant resolves the tenant of every request and stores it in the
+// request context. With API keys configured the key decides the tenant;
+// otherwise the X-Tenant-ID header does, falling back to the default tenant.
+func (ah *ApiHandler) withTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requested := req.Header.Get(tenantHeader)
+		if requested != "" && !tenant.ValidID(requested) {
+			respondWithProblem(rw, req, newProblem(http.StatusBadRequest, "invalid_tenant_id", "bad tenant id"))
+			return
+		}
+
+		tenantID := requested
+		if len(ah.ApiCfg.TenantKeys) > 0 {
+			var ok bool
+			tenantID, ok = ah.tenantFromKey(apiKeyFromRequest(req))
+			if !ok {
+				respondWithProblem(rw, req, newProblem(http.StatusUnauthorized, "invalid_api_key", "missing or invalid api key"))
+				return
+			}
+			if requested != "" && requested != tenantID {
+				respondWithProblem(rw, req, newProblem(http.StatusForbidden, "tenant_mismatch", "api key does not belong to tenant"))
+				return
+			}
+		} else if tenantID == "" {
+			tenantID = tenant.Default
+		}
+
+		next.ServeHTTP(rw, req.WithContext(tenant.WithID(req.Context(), tenantID)))
+	})
+}
+
+func (ah *ApiHandler) tenantFromKey(key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+	for candidate, tenantID := range ah.ApiCfg.TenantKeys {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			return tenantID, true
+		}
+	}
+	return "", false
+}
+
+func apiKeyFromRequest(req *http.Request) string {
+	if key := req.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	auth := req.Header.Get("Authorization")
+	if token, ok := strings.CutPrefix(auth, "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
@@ -0,0 +1,273 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/config"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+)
+
+// enrichmentStub answers agify, genderize and nationalize batch requests
+// with one result per requested name.
+type enrichmentStub struct{}
+
+func (enrichmentStub) RoundTrip(req *http.Request) (*http.Response, error) {
+	results := make([]string, len(req.URL.Query()["name[]"]))
+	for i := range results {
+		results[i] = `{"age":30,"gender":"male","country":[{"country_id":"RU","probability":0.9}]}`
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader("[" + strings.Join(results, ",") + "]")),
+		Request:    req,
+	}, nil
+}
+
+func stubEnrichment(t *testing.T) {
+	t.Helper()
+	transport := http.DefaultTransport
+	http.DefaultTransport = enrichmentStub{}
+	t.Cleanup(func() { http.DefaultTransport = transport })
+}
+
+func newTestMux(tenantKeys map[string]string) http.Handler {
+	repo := repository.NewMemoryHumanRepository()
+	return InitializeMux(&config.ApiConfig{Humans: repo, Transactor: repo, TenantKeys: tenantKeys})
+}
+
+func serve(t *testing.T, mux http.Handler, method, path string, header http.Header, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = strings.NewReader(string(encoded))
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func tenantHeaderOf(tenantID string) http.Header {
+	return http.Header{tenantHeader: {tenantID}}
+}
+
+// mustServe serves the request and decodes the response into out, failing
+// the test unless the status is want.
+func mustServe(t *testing.T, mux http.Handler, method, path string, header http.Header, body any, want int, out any) {
+	t.Helper()
+	rec := serve(t, mux, method, path, header, body)
+	if rec.Code != want {
+		t.Fatalf("%s %s: status %d, want %d: %s", method, path, rec.Code, want, rec.Body)
+	}
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: %s", method, path, err)
+		}
+	}
+}
+
+// tenantFixture is the data of tenant "a" the other tenant must not reach.
+type tenantFixture struct {
+	human, relative models.HumanResponse
+	contact         models.ContactResponse
+}
+
+func seedTenant(t *testing.T, mux http.Handler, header http.Header) tenantFixture {
+	t.Helper()
+	var f tenantFixture
+	mustServe(t, mux, "PUT", "/api/attributes/level", header, models.AttributeDefinitionRequest{Type: "integer"}, http.StatusOK, nil)
+	mustServe(t, mux, "POST", "/api/humans", header, models.HumanRequest{
+		Name: "Ivan", Surname: "Petrov", Attributes: map[string]any{"level": 3},
+	}, http.StatusCreated, &f.human)
+	mustServe(t, mux, "POST", "/api/humans", header, models.HumanRequest{Name: "Oleg", Surname: "Petrov"}, http.StatusCreated, &f.relative)
+	mustServe(t, mux, "POST", "/api/humans/"+f.human.ID+"/relatives", header, models.RelationshipRequest{
+		RelativeID: f.relative.ID, Type: "sibling", Bidirectional: true,
+	}, http.StatusCreated, nil)
+	mustServe(t, mux, "POST", "/api/humans/"+f.human.ID+"/contacts", header, models.ContactRequest{
+		Type: "email", Value: "ivan@example.com", Primary: true,
+	}, http.StatusCreated, &f.contact)
+	mustServe(t, mux, "POST", "/api/humans/"+f.human.ID+"/tags", header, models.TagsRequest{Tags: []string{"vip"}}, http.StatusOK, nil)
+	return f
+}
+
+func TestTenantIsolation(t *testing.T) {
+	stubEnrichment(t)
+	mux := newTestMux(nil)
+	a, b := tenantHeaderOf("a"), tenantHeaderOf("b")
+	f := seedTenant(t, mux, a)
+	human := "/api/humans/" + f.human.ID
+	contact := human + "/contacts/" + f.contact.ID
+
+	notFound := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+	}{
+		{"get human", "GET", human, nil},
+		{"update human", "PUT", human, models.HumanRequest{Name: "Ivan", Surname: "Sidorov"}},
+		{"delete human", "DELETE", human, nil},
+		{"merge into human", "POST", human + "/merge", models.MergeRequest{SourceID: f.relative.ID}},
+		{"export subject data", "GET", human + "/export", nil},
+		{"anonymize human", "POST", human + "/anonymize", nil},
+		{"list relatives", "GET", human + "/relatives", nil},
+		{"family tree", "GET", human + "/family-tree", nil},
+		{"create relationship", "POST", human + "/relatives", models.RelationshipRequest{RelativeID: f.relative.ID, Type: "parent"}},
+		{"delete relationship", "DELETE", human + "/relatives/" + f.relative.ID + "?type=sibling", nil},
+		{"list contacts", "GET", human + "/contacts", nil},
+		{"get contact", "GET", contact, nil},
+		{"create contact", "POST", human + "/contacts", models.ContactRequest{Type: "email", Value: "b@example.com"}},
+		{"update contact", "PUT", contact, models.ContactRequest{Type: "email", Value: "b@example.com"}},
+		{"delete contact", "DELETE", contact, nil},
+		{"attach tags", "POST", human + "/tags", models.TagsRequest{Tags: []string{"stolen"}}},
+		{"detach tag", "DELETE", human + "/tags/vip", nil},
+		{"delete attribute definition", "DELETE", "/api/attributes/level", nil},
+	}
+	for _, tc := range notFound {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serve(t, mux, tc.method, tc.path, b, tc.body)
+			if rec.Code != http.StatusNotFound {
+				t.Fatalf("status %d, want 404: %s", rec.Code, rec.Body)
+			}
+		})
+	}
+
+	// Attribute definitions are per tenant, so b may define its own level.
+	mustServe(t, mux, "PUT", "/api/attributes/level", b, models.AttributeDefinitionRequest{Type: "string"}, http.StatusOK, nil)
+	empty := []struct {
+		name string
+		path string
+	}{
+		{"list humans", "/api/humans"},
+		{"list humans by tag", "/api/humans?tags=vip"},
+		{"list humans by attribute", "/api/humans?attr.level=3"},
+		{"duplicates", "/api/humans/duplicates?min_score=0"},
+	}
+	for _, tc := range empty {
+		t.Run(tc.name, func(t *testing.T) {
+			var items []json.RawMessage
+			mustServe(t, mux, "GET", tc.path, b, nil, http.StatusOK, &items)
+			if len(items) != 0 {
+				t.Fatalf("got %d items, want none", len(items))
+			}
+		})
+	}
+
+	t.Run("attribute definitions", func(t *testing.T) {
+		var defs []models.AttributeDefinitionResponse
+		mustServe(t, mux, "GET", "/api/attributes", b, nil, http.StatusOK, &defs)
+		if len(defs) != 1 || defs[0].Type != "string" {
+			t.Fatalf("got %+v, want only b's own definition", defs)
+		}
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		var stats models.HumanStatsResponse
+		mustServe(t, mux, "GET", "/api/humans/stats", b, nil, http.StatusOK, &stats)
+		if stats.Total != 0 || len(stats.ByGender) != 0 || len(stats.ByCountry) != 0 || len(stats.AgeBuckets) != 0 {
+			t.Fatalf("got %+v, want empty stats", stats)
+		}
+	})
+
+	t.Run("export", func(t *testing.T) {
+		rec := serve(t, mux, "GET", "/api/humans/export?format=ndjson", b, nil)
+		if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "" {
+			t.Fatalf("status %d, body %q, want an empty export", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("tenant a is untouched", func(t *testing.T) {
+		var got models.HumanResponse
+		mustServe(t, mux, "GET", human+"?expand=contacts", a, nil, http.StatusOK, &got)
+		if got.Surname != "Petrov" || len(got.Tags) != 1 || got.Tags[0] != "vip" || got.Attributes["level"] != float64(3) {
+			t.Fatalf("got %+v", got)
+		}
+		if len(got.Contacts) != 1 || got.Contacts[0].ID != f.contact.ID {
+			t.Fatalf("got contacts %+v, want %s", got.Contacts, f.contact.ID)
+		}
+		var relatives []models.RelativeResponse
+		mustServe(t, mux, "GET", human+"/relatives", a, nil, http.StatusOK, &relatives)
+		if len(relatives) != 1 || relatives[0].Human.ID != f.relative.ID {
+			t.Fatalf("got relatives %+v", relatives)
+		}
+		var stats models.HumanStatsResponse
+		mustServe(t, mux, "GET", "/api/humans/stats", a, nil, http.StatusOK, &stats)
+		if stats.Total != 2 {
+			t.Fatalf("got total %d, want 2", stats.Total)
+		}
+	})
+}
+
+func TestTenantSameNames(t *testing.T) {
+	stubEnrichment(t)
+	mux := newTestMux(nil)
+	for _, tenantID := range []string{"a", "b"} {
+		mustServe(t, mux, "POST", "/api/humans", tenantHeaderOf(tenantID), models.HumanRequest{Name: "Ivan", Surname: "Petrov"}, http.StatusCreated, nil)
+	}
+	for _, tenantID := range []string{"a", "b"} {
+		var humans []models.HumanResponse
+		mustServe(t, mux, "GET", "/api/humans", tenantHeaderOf(tenantID), nil, http.StatusOK, &humans)
+		if len(humans) != 1 {
+			t.Fatalf("tenant %s: got %d humans, want 1", tenantID, len(humans))
+		}
+	}
+}
+
+func TestTenantFromAPIKey(t *testing.T) {
+	stubEnrichment(t)
+	mux := newTestMux(map[string]string{"key-a": "a", "key-b": "b"})
+	keyA, keyB := http.Header{"X-Api-Key": {"key-a"}}, http.Header{"Authorization": {"Bearer key-b"}}
+	f := seedTenant(t, mux, keyA)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   int
+	}{
+		{"owner", keyA, http.StatusOK},
+		{"other tenant", keyB, http.StatusNotFound},
+		{"other tenant claiming the owner", http.Header{"Authorization": {"Bearer key-b"}, tenantHeader: {"a"}}, http.StatusForbidden},
+		{"header without key", tenantHeaderOf("a"), http.StatusUnauthorized},
+		{"unknown key", http.Header{"X-Api-Key": {"key-c"}}, http.StatusUnauthorized},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serve(t, mux, "GET", "/api/humans/"+f.human.ID, tc.header, nil)
+			if rec.Code != tc.want {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tc.want, rec.Body)
+			}
+		})
+	}
+}
+
+func TestTenantIDValidation(t *testing.T) {
+	mux := newTestMux(nil)
+	for _, tenantID := range []string{"a b", strings.Repeat("x", 200), "a/b"} {
+		t.Run(fmt.Sprintf("%.20q", tenantID), func(t *testing.T) {
+			rec := serve(t, mux, "GET", "/api/humans", tenantHeaderOf(tenantID), nil)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status %d, want 400: %s", rec.Code, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	service "github.com/kiriksik/TestTaskEffectiveMobile/internal/services"
+)
+
+// @Summary Создание подписки на вебхуки
+// @Description	Подписывает URL на события людей тенанта. Каждая доставка подписывается HMAC-SHA256 секрета от "<X-Webhook-Timestamp>.<тело>" в заголовке X-Webhook-Signature. Если секрет не задан, он генерируется; секрет возвращается только в этом ответе
+// @Tags	webhooks
+// @Accept	json
+// @Produce	json
+// @Param	request body models.WebhookRequest true "Подписка"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	201 {object} models.WebhookResponse
+// @Router /api/webhooks [post]
+func (ah *ApiHandler) createWebhook(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	var reqBodyData models.WebhookRequest
+
+	if !decodeJSONBody(rw, req, &reqBodyData) {
+		return
+	}
+
+	webhook, err := humanService.CreateWebhook(req.Context(), &reqBodyData)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusCreated, webhook)
+}
+
+// @Summary Получение подписок на вебхуки
+// @Description	Возвращает подписки тенанта без секретов
+// @Tags	webhooks
+// @Produce	json
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {array} models.WebhookResponse
+// @Router /api/webhooks [get]
+func (ah *ApiHandler) getWebhooks(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+
+	webhooks, err := humanService.GetWebhooks(req.Context())
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, webhooks)
+}
+
+// @Summary Получение подписки на вебхуки
+// @Description	Возвращает подписку по её ID
+// @Tags	webhooks
+// @Produce	json
+// @Param	webhookID path string true "ID подписки"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {object} models.WebhookResponse
+// @Router /api/webhooks/{webhookID} [get]
+func (ah *ApiHandler) getWebhook(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	webhookID := req.PathValue("webhookID")
+	if webhookID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	webhook, err := humanService.GetWebhook(req.Context(), webhookID)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, webhook)
+}
+
+// @Summary Изменение подписки на вебхуки
+// @Description	Меняет заданные поля подписки. enabled=true включает подписку, отключённую после ошибок доставки, и сбрасывает счётчик ошибок
+// @Tags	webhooks
+// @Accept	json
+// @Produce	json
+// @Param	webhookID path string true "ID подписки"
+// @Param	request body models.WebhookUpdateRequest true "Изменения"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {object} models.WebhookResponse
+// @Router /api/webhooks/{webhookID} [patch]
+func (ah *ApiHandler) updateWebhook(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	var reqBodyData models.WebhookUpdateRequest
+	webhookID := req.PathValue("webhookID")
+	if webhookID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	if !decodeJSONBody(rw, req, &reqBodyData) {
+		return
+	}
+
+	webhook, err := humanService.UpdateWebhook(req.Context(), webhookID, &reqBodyData)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, webhook)
+}
+
+// @Summary Удаление подписки на вебхуки
+// @Description	Удаляет подписку вместе с журналом её доставок
+// @Tags	webhooks
+// @Produce	json
+// @Param	webhookID path string true "ID подписки"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {object} models.WebhookResponse
+// @Router /api/webhooks/{webhookID} [delete]
+func (ah *ApiHandler) deleteWebhook(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	webhookID := req.PathValue("webhookID")
+	if webhookID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	webhook, err := humanService.DeleteWebhook(req.Context(), webhookID)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, webhook)
+}
+
+// @Summary Журнал доставок вебхука
+// @Description	Возвращает последние доставки подписки, новые первыми
+// @Tags	webhooks
+// @Produce	json
+// @Param	webhookID path string true "ID подписки"
+// @Param	status query string false "Статус доставки" Enums(pending, succeeded, failed)
+// @Param	limit query int false "Максимальное количество доставок" default(50)
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {array} models.WebhookDeliveryResponse
+// @Router /api/webhooks/{webhookID}/deliveries [get]
+func (ah *ApiHandler) getWebhookDeliveries(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	webhookID := req.PathValue("webhookID")
+	if webhookID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+	limit := service.DefaultWebhookDeliveryLimit
+	if raw := req.URL.Query().Get("limit"); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil {
+			respondWithError(rw, req, http.StatusBadRequest, "bad limit")
+			return
+		}
+	}
+
+	deliveries, err := humanService.GetWebhookDeliveries(req.Context(), webhookID, req.URL.Query().Get("status"), limit)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, deliveries)
+}
+
+// @Summary Доставка вебхука
+// @Description	Возвращает доставку вместе с журналом всех попыток
+// @Tags	webhooks
+// @Produce	json
+// @Param	webhookID path string true "ID подписки"
+// @Param	deliveryID path string true "ID доставки"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	200 {object} models.WebhookDeliveryResponse
+// @Router /api/webhooks/{webhookID}/deliveries/{deliveryID} [get]
+func (ah *ApiHandler) getWebhookDelivery(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	webhookID := req.PathValue("webhookID")
+	deliveryID := req.PathValue("deliveryID")
+	if webhookID == "" || deliveryID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	delivery, err := humanService.GetWebhookDelivery(req.Context(), webhookID, deliveryID)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusOK, delivery)
+}
+
+// @Summary Повторная доставка вебхука
+// @Description	Ставит доставку в очередь на немедленную отправку с новым запасом попыток, независимо от её статуса
+// @Tags	webhooks
+// @Produce	json
+// @Param	webhookID path string true "ID подписки"
+// @Param	deliveryID path string true "ID доставки"
+// @Param	X-Tenant-ID header string false "ID тенанта"
+// @Success	202 {object} models.WebhookDeliveryResponse
+// @Router /api/webhooks/{webhookID}/deliveries/{deliveryID}/redeliver [post]
+func (ah *ApiHandler) redeliverWebhook(rw http.ResponseWriter, req *http.Request) {
+	humanService := service.UserService{ApiConfig: ah.ApiCfg}
+	webhookID := req.PathValue("webhookID")
+	deliveryID := req.PathValue("deliveryID")
+	if webhookID == "" || deliveryID == "" {
+		respondWithError(rw, req, http.StatusBadRequest, "missing id")
+		return
+	}
+
+	delivery, err := humanService.RedeliverWebhook(req.Context(), webhookID, deliveryID)
+	if err != nil {
+		respondWithServiceError(rw, req, err)
+		return
+	}
+
+	respondWithJson(rw, http.StatusAccepted, delivery)
+}
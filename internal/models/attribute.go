@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+type AttributeDefinitionRequest struct {
+	// Type is string, number, integer, boolean or date (YYYY-MM-DD).
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+	// Enum lists the allowed values. Empty means any value of the type.
+	Enum []any `json:"enum,omitempty"`
+	// Pattern is a regular expression string values must match.
+	Pattern *string `json:"pattern,omitempty"`
+	// MaxLength limits string values, in characters.
+	MaxLength *int `json:"max_length,omitempty"`
+	// Minimum and Maximum bound number and integer values, inclusive.
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+}
+
+type AttributeDefinitionResponse struct {
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Required  bool      `json:"required"`
+	Enum      []any     `json:"enum"`
+	Pattern   *string   `json:"pattern,omitempty"`
+	MaxLength *int      `json:"max_length,omitempty"`
+	Minimum   *float64  `json:"minimum,omitempty"`
+	Maximum   *float64  `json:"maximum,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,36 @@
+package models
+
+type BatchRequest struct {
+	// Mode is atomic, where any failure rolls the whole batch back, or
+	// best_effort, where each operation is applied on its own.
+	Mode       string           `json:"mode"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+type BatchOperation struct {
+	// Op is create, update or delete.
+	Op string `json:"op"`
+	// ID is the human to update or delete.
+	ID    string        `json:"id,omitempty"`
+	Human *HumanRequest `json:"human,omitempty"`
+}
+
+type BatchResponse struct {
+	Mode      string            `json:"mode"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Results   []BatchItemResult `json:"results"`
+}
+
+type BatchItemResult struct {
+	Index  int            `json:"index"`
+	Op     string         `json:"op"`
+	Status int            `json:"status"`
+	Human  *HumanResponse `json:"human,omitempty"`
+	// Code is the machine-readable error code of a failed operation, as
+	// in Problem.
+	Code  string `json:"code,omitempty"`
+	Error string `json:"error,omitempty"`
+	// Errors lists the rejected fields of a human that failed validation.
+	Errors []FieldError `json:"errors,omitempty"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+type ContactRequest struct {
+	// Type is email, phone or address.
+	Type string `json:"type"`
+	// Value holds the email or the phone number in E.164. It is derived
+	// from Address for postal addresses.
+	Value   string   `json:"value,omitempty"`
+	Primary bool     `json:"primary"`
+	Address *Address `json:"address,omitempty"`
+}
+
+type Address struct {
+	Street     string `json:"street"`
+	City       string `json:"city"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	// Country is an ISO 3166-1 alpha-2 code.
+	Country string `json:"country"`
+}
+
+type ContactResponse struct {
+	ID        string    `json:"id"`
+	HumanID   string    `json:"human_id"`
+	Type      string    `json:"type"`
+	Value     string    `json:"value"`
+	Primary   bool      `json:"primary"`
+	Address   *Address  `json:"address,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// HumanExpand selects related resources embedded into human responses.
+type HumanExpand struct {
+	Contacts bool
+}
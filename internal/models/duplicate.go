@@ -0,0 +1,22 @@
+package models
+
+// DuplicateCandidate is a pair of humans that may be the same person.
+type DuplicateCandidate struct {
+	// Score is between 0 and 1, higher is more likely a duplicate.
+	Score          float64 `json:"score"`
+	NameSimilarity float64 `json:"name_similarity"`
+	// Reasons lists the signals that contributed to the score.
+	Reasons   []string      `json:"reasons"`
+	Human     HumanResponse `json:"human"`
+	Candidate HumanResponse `json:"candidate"`
+}
+
+type MergeRequest struct {
+	// SourceID is the human merged into the target and then deleted.
+	SourceID string `json:"source_id"`
+	// Prefer picks the winner per field: "target" or "source". Fields are
+	// name, surname, patronymic, age, gender, country and attributes. By
+	// default the target wins, except for an empty patronymic. Attributes
+	// are combined, with the winner's values taking precedence.
+	Prefer map[string]string `json:"prefer,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+const (
+	EventHumanCreated = "HumanCreated"
+	EventHumanUpdated = "HumanUpdated"
+	EventHumanDeleted = "HumanDeleted"
+)
+
+// HumanEvent is published when a human is created, updated or deleted.
+// Consumers should expect duplicates and may drop events whose ID they have
+// seen.
+type HumanEvent struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	TenantID   string    `json:"tenant_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Human is the state after the change, or before it for deletions.
+	Human HumanResponse `json:"human"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SubjectDataExport is everything stored about one human, for answering
+// data subject access requests.
+type SubjectDataExport struct {
+	ExportedAt time.Time `json:"exported_at"`
+	// Human includes the contacts, tags and custom attributes.
+	Human HumanResponse `json:"human"`
+	// Relationships are the links in both directions.
+	Relationships []RelationshipResponse `json:"relationships"`
+	Enrichment    EnrichmentData         `json:"enrichment"`
+	// Events are the stored change events of the human, oldest first,
+	// with the state each one carries.
+	Events []SubjectEvent `json:"events"`
+	// WebhookDeliveries are the stored deliveries of those events to the
+	// tenant's webhooks, oldest first.
+	WebhookDeliveries []WebhookDeliveryResponse `json:"webhook_deliveries"`
+}
+
+// SubjectEvent is a change event kept in the outbox until it is published
+// and cleaned up.
+type SubjectEvent struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	CreatedAt   time.Time       `json:"created_at"`
+	DeliveredAt *time.Time      `json:"delivered_at,omitempty"`
+	Payload     json.RawMessage `json:"payload" swaggertype:"object"`
+}
+
+// EnrichmentData lists the demographics inferred from the name and where
+// they came from. The upstream responses themselves are not stored.
+type EnrichmentData struct {
+	Age     int      `json:"age"`
+	Gender  string   `json:"gender"`
+	Country string   `json:"country"`
+	Sources []string `json:"sources"`
+}
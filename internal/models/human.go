@@ -1,19 +1,49 @@
 package models
 
+import "time"
+
 type HumanRequest struct {
 	Name       string `json:"name"`
 	Surname    string `json:"surname"`
 	Patronymic string `json:"patronymic,omitempty"`
+	// Attributes are custom fields checked against the tenant's attribute
+	// definitions. On update, omitting them keeps the stored ones.
+	Attributes map[string]any `json:"attributes,omitempty"`
 }
 
 type HumanResponse struct {
-	ID         string  `json:"id"`
-	Name       string  `json:"name"`
-	Surname    string  `json:"surname"`
-	Patronymic *string `json:"patronymic,omitempty"`
-	Age        int     `json:"age"`
-	Gender     string  `json:"gender"`
-	Country    string  `json:"country"`
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Surname    string         `json:"surname"`
+	Patronymic *string        `json:"patronymic,omitempty"`
+	Age        int            `json:"age"`
+	Gender     string         `json:"gender"`
+	Country    string         `json:"country"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	Tags       []string       `json:"tags"`
+	Attributes map[string]any `json:"attributes"`
+	// Contacts is only filled when requested with expand=contacts.
+	Contacts []ContactResponse `json:"contacts,omitempty"`
+}
+
+// HumanFilter narrows and orders the list of humans. Since bounds are
+// inclusive, until bounds are exclusive.
+type HumanFilter struct {
+	CreatedSince *time.Time
+	CreatedUntil *time.Time
+	UpdatedSince *time.Time
+	UpdatedUntil *time.Time
+	// Tags keeps humans carrying any of the tags, or all of them when
+	// TagMatch is "all".
+	Tags     []string
+	TagMatch string
+	// Attributes keeps humans whose attributes equal the given values,
+	// which are converted to each attribute's type.
+	Attributes map[string]string
+	// Sort is one of created_at, updated_at, optionally prefixed with "-"
+	// for descending order.
+	Sort string
 }
 
 type AgeResponse struct {
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type ImportJobResponse struct {
+	ID     string `json:"id"`
+	Format string `json:"format"`
+	// Status is pending, running, completed or failed.
+	Status        string           `json:"status"`
+	TotalRows     int              `json:"total_rows"`
+	ProcessedRows int              `json:"processed_rows"`
+	CreatedRows   int              `json:"created_rows"`
+	FailedRows    int              `json:"failed_rows"`
+	Errors        []ImportRowError `json:"errors"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+	FinishedAt    *time.Time       `json:"finished_at,omitempty"`
+}
+
+type ImportRowError struct {
+	// Row is the 1-based number of the data row, not counting a CSV header.
+	Row     int    `json:"row"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Errors lists the rejected fields of an invalid_fields row.
+	Errors []FieldError `json:"errors,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// Problem is an RFC 7807 problem details object. Every error is returned
+// as one, with the application/problem+json content type.
+type Problem struct {
+	// Type identifies the kind of problem; it is derived from Code.
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// Instance is the path of the request that failed.
+	Instance string `json:"instance,omitempty"`
+	// Code is a stable machine-readable error code, such as not_found or
+	// invalid_fields.
+	Code string `json:"code"`
+	// RequestID is also returned in the X-Request-ID header and is logged
+	// with the cause of the error.
+	RequestID string `json:"request_id,omitempty"`
+	// Errors lists the rejected fields when Code is invalid_fields.
+	Errors []FieldError `json:"errors,omitempty"`
+}
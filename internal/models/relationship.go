@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+type RelationshipRequest struct {
+	RelativeID string `json:"relative_id"`
+	// Type is what the relative is to the human: parent, child, spouse or sibling.
+	Type string `json:"type"`
+	// Bidirectional also records the inverse relation from the relative's side.
+	Bidirectional bool `json:"bidirectional,omitempty"`
+}
+
+type RelationshipResponse struct {
+	ID         string    `json:"id"`
+	HumanID    string    `json:"human_id"`
+	RelativeID string    `json:"relative_id"`
+	Type       string    `json:"type"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type RelativeResponse struct {
+	RelationshipID string        `json:"relationship_id"`
+	Type           string        `json:"type"`
+	Human          HumanResponse `json:"human"`
+}
+
+type FamilyTreeResponse struct {
+	RootID        string                 `json:"root_id"`
+	Depth         int                    `json:"depth"`
+	Members       []FamilyMember         `json:"members"`
+	Relationships []RelationshipResponse `json:"relationships"`
+}
+
+// FamilyMember is a human reachable from the root in Depth links.
+type FamilyMember struct {
+	Depth int           `json:"depth"`
+	Human HumanResponse `json:"human"`
+}
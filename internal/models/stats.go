@@ -0,0 +1,27 @@
+package models
+
+type HumanStatsResponse struct {
+	Total       int64          `json:"total"`
+	ByGender    []GenderCount  `json:"by_gender"`
+	ByCountry   []CountryStats `json:"by_country"`
+	BucketWidth int            `json:"bucket_width"`
+	AgeBuckets  []AgeBucket    `json:"age_buckets"`
+}
+
+type GenderCount struct {
+	Gender string `json:"gender"`
+	Count  int64  `json:"count"`
+}
+
+type CountryStats struct {
+	Country    string  `json:"country"`
+	Count      int64   `json:"count"`
+	AverageAge float64 `json:"average_age"`
+}
+
+// AgeBucket counts humans with From <= age < To.
+type AgeBucket struct {
+	From  int   `json:"from"`
+	To    int   `json:"to"`
+	Count int64 `json:"count"`
+}
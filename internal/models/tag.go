@@ -0,0 +1,7 @@
+package models
+
+type TagsRequest struct {
+	// Tags are lowercased; letters, digits and the characters ".", "_",
+	// ":" and "-" are allowed.
+	Tags []string `json:"tags"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// FieldError describes why one field of a request was rejected.
+type FieldError struct {
+	// Field is the JSON name of the field, such as "surname".
+	Field string `json:"field"`
+	// Code is required, too_long, invalid_characters or invalid_format.
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type WebhookRequest struct {
+	URL string `json:"url"`
+	// EventTypes lists the events to deliver. Empty means every event.
+	EventTypes []string `json:"event_types"`
+	// Secret signs deliveries. A random secret is generated when empty.
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookUpdateRequest changes the fields that are set.
+type WebhookUpdateRequest struct {
+	URL        *string   `json:"url,omitempty"`
+	EventTypes *[]string `json:"event_types,omitempty"`
+	Secret     *string   `json:"secret,omitempty"`
+	// Enabled re-enables a disabled subscription when true.
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+type WebhookResponse struct {
+	ID         string   `json:"id"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	// Secret is only returned when the subscription is created.
+	Secret  string `json:"secret,omitempty"`
+	Enabled bool   `json:"enabled"`
+	// ConsecutiveFailures counts deliveries in a row that ran out of
+	// attempts.
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID        string `json:"id"`
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	// Status is pending, succeeded or failed.
+	Status             string          `json:"status"`
+	Attempts           int             `json:"attempts"`
+	NextAttemptAt      *time.Time      `json:"next_attempt_at,omitempty"`
+	LastResponseStatus *int            `json:"last_response_status,omitempty"`
+	LastError          *string         `json:"last_error,omitempty"`
+	Payload            json.RawMessage `json:"payload" swaggertype:"object"`
+	// Log lists every attempt, oldest first. It is only returned for a
+	// single delivery.
+	Log       []WebhookAttemptResponse `json:"log,omitempty"`
+	CreatedAt time.Time                `json:"created_at"`
+	UpdatedAt time.Time                `json:"updated_at"`
+}
+
+type WebhookAttemptResponse struct {
+	AttemptedAt    time.Time `json:"attempted_at"`
+	ResponseStatus *int      `json:"response_status,omitempty"`
+	Error          *string   `json:"error,omitempty"`
+	DurationMs     int       `json:"duration_ms"`
+}
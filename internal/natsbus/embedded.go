@@ -0,0 +1,37 @@
+package natsbus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+// RunEmbeddedServer starts a NATS server with JetStream inside the process,
+// keeping streams under storeDir. It only accepts in-process connections,
+// made with Connect, which lets the service and its tests run without a
+// broker.
+func RunEmbeddedServer(storeDir string) (*server.Server, error) {
+	srv, err := server.NewServer(&server.Options{
+		ServerName: "humans-embedded",
+		DontListen: true,
+		JetStream:  true,
+		StoreDir:   storeDir,
+		NoSigs:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	srv.Start()
+	if !srv.ReadyForConnections(10 * time.Second) {
+		srv.Shutdown()
+		return nil, fmt.Errorf("embedded NATS server did not start")
+	}
+	return srv, nil
+}
+
+// Connect connects to the embedded server.
+func Connect(srv *server.Server, options ...nats.Option) (*nats.Conn, error) {
+	return nats.Connect("", append(options, nats.InProcessServer(srv))...)
+}
@@ -0,0 +1,164 @@
+// Package natsbus publishes human events to NATS JetStream.
+//
+// An event goes to the subject "<prefix>.<tenant id>.<event type>", such as
+// "humans.acme.HumanCreated", so consumers can pick tenants and event types
+// with wildcards. Every message carries the event id in the Nats-Msg-Id
+// header: JetStream drops a message published again within the stream's
+// duplicate window, as happens when the outbox relay retries a batch.
+package natsbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/eventspb"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	FormatJSON     = "json"
+	FormatProtobuf = "protobuf"
+
+	DefaultSubjectPrefix = "humans"
+	DefaultStream        = "HUMANS"
+
+	HeaderEventType   = "Event-Type"
+	HeaderContentType = "Content-Type"
+
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/protobuf; messageType=humans.v1.HumanEvent"
+)
+
+// Publisher publishes outbox events to JetStream. It implements
+// outbox.Sink.
+type Publisher struct {
+	js     jetstream.JetStream
+	prefix string
+	format string
+}
+
+// NewPublisher returns a publisher sending events under prefix, encoded as
+// JSON or as the humans.v1.HumanEvent protobuf message.
+func NewPublisher(js jetstream.JetStream, prefix, format string) (*Publisher, error) {
+	if !validPrefix(prefix) {
+		return nil, fmt.Errorf("bad subject prefix %q", prefix)
+	}
+	if format != FormatJSON && format != FormatProtobuf {
+		return nil, fmt.Errorf("unknown format %q, want %s or %s", format, FormatJSON, FormatProtobuf)
+	}
+	return &Publisher{js: js, prefix: prefix, format: format}, nil
+}
+
+// Subject returns the subject events of a type are published to.
+func (p *Publisher) Subject(tenantID, eventType string) string {
+	return p.prefix + "." + tenantID + "." + eventType
+}
+
+// Publish returns once the stream has stored the event.
+func (p *Publisher) Publish(ctx context.Context, event database.Outbox) error {
+	data, contentType, err := Encode(event.Payload, p.format)
+	if err != nil {
+		return fmt.Errorf("event %s: %w", event.EventID, err)
+	}
+	msg := nats.NewMsg(p.Subject(event.TenantID, event.EventType))
+	msg.Data = data
+	msg.Header.Set(HeaderContentType, contentType)
+	msg.Header.Set(HeaderEventType, event.EventType)
+	_, err = p.js.PublishMsg(ctx, msg, jetstream.WithMsgID(event.EventID.String()))
+	if err != nil {
+		return fmt.Errorf("failed to publish event %s: %w", event.EventID, err)
+	}
+	return nil
+}
+
+// EnsureStream creates the stream capturing every subject under prefix, or
+// points an existing stream with that name at them. Messages older than
+// maxAge are discarded; zero keeps them until the stream's limits are hit.
+func EnsureStream(ctx context.Context, js jetstream.JetStream, name, prefix string, maxAge time.Duration) error {
+	_, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:       name,
+		Subjects:   []string{prefix + ".>"},
+		Storage:    jetstream.FileStorage,
+		Duplicates: 10 * time.Minute,
+		MaxAge:     maxAge,
+	})
+	return err
+}
+
+// Encode converts the JSON payload of an outbox event into format and
+// returns it with its content type.
+func Encode(payload json.RawMessage, format string) ([]byte, string, error) {
+	switch format {
+	case FormatJSON:
+		return payload, ContentTypeJSON, nil
+	case FormatProtobuf:
+		var event models.HumanEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, "", fmt.Errorf("bad payload: %w", err)
+		}
+		message, err := toProto(event)
+		if err != nil {
+			return nil, "", err
+		}
+		data, err := proto.Marshal(message)
+		if err != nil {
+			return nil, "", err
+		}
+		return data, ContentTypeProtobuf, nil
+	default:
+		return nil, "", fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func toProto(event models.HumanEvent) (*eventspb.HumanEvent, error) {
+	human := event.Human
+	attributes, err := structpb.NewStruct(human.Attributes)
+	if err != nil {
+		return nil, fmt.Errorf("bad attributes: %w", err)
+	}
+	var patronymic string
+	if human.Patronymic != nil {
+		patronymic = *human.Patronymic
+	}
+	return &eventspb.HumanEvent{
+		Id:         event.ID,
+		Type:       event.Type,
+		TenantId:   event.TenantID,
+		OccurredAt: timestamppb.New(event.OccurredAt),
+		Human: &eventspb.Human{
+			Id:         human.ID,
+			Name:       human.Name,
+			Surname:    human.Surname,
+			Patronymic: patronymic,
+			Age:        int32(human.Age),
+			Gender:     human.Gender,
+			Country:    human.Country,
+			CreatedAt:  timestamppb.New(human.CreatedAt),
+			UpdatedAt:  timestamppb.New(human.UpdatedAt),
+			Tags:       human.Tags,
+			Attributes: attributes,
+		},
+	}, nil
+}
+
+// validPrefix reports whether prefix is a subject without wildcards.
+func validPrefix(prefix string) bool {
+	if prefix == "" {
+		return false
+	}
+	for _, token := range strings.Split(prefix, ".") {
+		if token == "" || token == "*" || token == ">" || strings.ContainsAny(token, " \t\r\n") {
+			return false
+		}
+	}
+	return true
+}
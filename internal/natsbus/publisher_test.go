@@ -0,0 +1,157 @@
+package natsbus
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/eventspb"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/nats-io/nats.go/jetstream"
+	"google.golang.org/protobuf/proto"
+)
+
+func newTestJetStream(t *testing.T) jetstream.JetStream {
+	t.Helper()
+	srv, err := RunEmbeddedServer(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Shutdown)
+	nc, err := Connect(srv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(nc.Close)
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return js
+}
+
+func testEvent(t *testing.T) database.Outbox {
+	t.Helper()
+	eventID, humanID := uuid.New(), uuid.New()
+	occurredAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	payload, err := json.Marshal(models.HumanEvent{
+		ID:         eventID.String(),
+		Type:       "HumanCreated",
+		TenantID:   "acme",
+		OccurredAt: occurredAt,
+		Human: models.HumanResponse{
+			ID:         humanID.String(),
+			Name:       "Ivan",
+			Surname:    "Petrov",
+			Age:        30,
+			Gender:     "male",
+			Country:    "RU",
+			CreatedAt:  occurredAt,
+			UpdatedAt:  occurredAt,
+			Tags:       []string{"vip"},
+			Attributes: map[string]any{"level": float64(3)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return database.Outbox{
+		EventID:   eventID,
+		TenantID:  "acme",
+		HumanID:   humanID,
+		EventType: "HumanCreated",
+		Payload:   payload,
+	}
+}
+
+func TestPublish(t *testing.T) {
+	tests := []struct {
+		format      string
+		contentType string
+		// check fails unless data is the encoded event.
+		check func(t *testing.T, event database.Outbox, data []byte)
+	}{
+		{FormatJSON, ContentTypeJSON, func(t *testing.T, event database.Outbox, data []byte) {
+			var got models.HumanEvent
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.ID != event.EventID.String() || got.Type != "HumanCreated" || got.TenantID != "acme" ||
+				got.Human.ID != event.HumanID.String() || got.Human.Name != "Ivan" || got.Human.Attributes["level"] != float64(3) {
+				t.Fatalf("got %+v", got)
+			}
+		}},
+		{FormatProtobuf, ContentTypeProtobuf, func(t *testing.T, event database.Outbox, data []byte) {
+			var got eventspb.HumanEvent
+			if err := proto.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			human := got.GetHuman()
+			if got.GetId() != event.EventID.String() || got.GetType() != "HumanCreated" || got.GetTenantId() != "acme" ||
+				!got.GetOccurredAt().AsTime().Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)) {
+				t.Fatalf("got %v", &got)
+			}
+			if human.GetId() != event.HumanID.String() || human.GetName() != "Ivan" || human.GetAge() != 30 ||
+				len(human.GetTags()) != 1 || human.GetAttributes().GetFields()["level"].GetNumberValue() != 3 {
+				t.Fatalf("got human %v", human)
+			}
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.format, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			js := newTestJetStream(t)
+			if err := EnsureStream(ctx, js, DefaultStream, "events", 0); err != nil {
+				t.Fatal(err)
+			}
+			publisher, err := NewPublisher(js, "events", tc.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			event := testEvent(t)
+			// The second publish is what a relay retry looks like; the
+			// stream must drop it by its Nats-Msg-Id.
+			for range 2 {
+				if err := publisher.Publish(ctx, event); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			stream, err := js.Stream(ctx, DefaultStream)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msgs := stream.CachedInfo().State.Msgs; msgs != 1 {
+				t.Fatalf("stream has %d messages, want 1", msgs)
+			}
+			consumer, err := stream.OrderedConsumer(ctx, jetstream.OrderedConsumerConfig{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg, err := consumer.Next(jetstream.FetchMaxWait(5 * time.Second))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if msg.Subject() != "events.acme.HumanCreated" {
+				t.Fatalf("got subject %q", msg.Subject())
+			}
+			header := msg.Headers()
+			if got := header.Get(HeaderEventType); got != "HumanCreated" {
+				t.Fatalf("got %s %q", HeaderEventType, got)
+			}
+			if got := header.Get(HeaderContentType); got != tc.contentType {
+				t.Fatalf("got %s %q, want %q", HeaderContentType, got, tc.contentType)
+			}
+			if got := header.Get("Nats-Msg-Id"); got != event.EventID.String() {
+				t.Fatalf("got Nats-Msg-Id %q, want %s", got, event.EventID)
+			}
+			tc.check(t, event, msg.Data())
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Package outbox publishes the events services record in the outbox table.
+// Events are written in the same transaction as the change they describe
+// and delivered at least once: an event is marked delivered only after the
+// sink accepted it, so a crash in between publishes it again.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+)
+
+const (
+	DefaultBatchSize    = 100
+	DefaultPollInterval = time.Second
+	DefaultMaxBackoff   = time.Minute
+	DefaultRetention    = 24 * time.Hour
+
+	cleanupInterval = time.Minute
+)
+
+// Relay moves events from the outbox to a sink. Several relays may run
+// against one database; each claims its own batch. A single relay keeps
+// the order of events, since it stops at the first event the sink rejects
+// and retries it before anything newer. A relay without a sink publishes
+// nothing and only deletes events older than Retention, which are then
+// kept just for the event stream.
+type Relay struct {
+	transactor   repository.Transactor
+	sink         Sink
+	BatchSize    int
+	PollInterval time.Duration
+	// MaxBackoff caps the delay between retries while the sink fails.
+	MaxBackoff time.Duration
+	// Retention is how long delivered events are kept before cleanup, or
+	// any events when there is no sink.
+	Retention time.Duration
+}
+
+func NewRelay(transactor repository.Transactor, sink Sink) *Relay {
+	return &Relay{
+		transactor:   transactor,
+		sink:         sink,
+		BatchSize:    DefaultBatchSize,
+		PollInterval: DefaultPollInterval,
+		MaxBackoff:   DefaultMaxBackoff,
+		Retention:    DefaultRetention,
+	}
+}
+
+// Run relays events until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	backoff := r.PollInterval
+	var lastCleanup time.Time
+	for {
+		wait := r.PollInterval
+		var delivered int
+		var err error
+		if r.sink != nil {
+			delivered, err = r.relayBatch(ctx)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		switch {
+		case err != nil:
+			log.Printf("outbox: %s, retrying in %s", err, backoff)
+			wait = backoff
+			backoff = min(2*backoff, r.MaxBackoff)
+		case delivered == r.BatchSize:
+			// There is likely more waiting.
+			wait = 0
+			backoff = r.PollInterval
+		default:
+			backoff = r.PollInterval
+		}
+
+		if time.Since(lastCleanup) >= cleanupInterval {
+			r.cleanup(ctx)
+			lastCleanup = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// relayBatch publishes a batch of events in order and returns how many
+// were delivered. Delivery marks are committed together at the end.
+func (r *Relay) relayBatch(ctx context.Context) (int, error) {
+	tx, err := r.transactor.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	events, err := tx.Outbox().ClaimOutboxEvents(ctx, int32(r.BatchSize))
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	var publishErr error
+	for _, event := range events {
+		if publishErr = r.sink.Publish(ctx, event); publishErr != nil {
+			err := tx.Outbox().RecordOutboxFailure(ctx, database.RecordOutboxFailureParams{
+				ID: event.ID, LastError: publishErr.Error(),
+			})
+			if err != nil {
+				return 0, err
+			}
+			break
+		}
+		if err := tx.Outbox().MarkOutboxEventDelivered(ctx, event.ID); err != nil {
+			return 0, err
+		}
+		delivered++
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return delivered, publishErr
+}
+
+func (r *Relay) cleanup(ctx context.Context) {
+	tx, err := r.transactor.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		log.Printf("outbox: cleanup failed: %s", err)
+		return
+	}
+	defer tx.Rollback()
+	var deleted int64
+	if r.sink != nil {
+		deleted, err = tx.Outbox().DeleteDeliveredOutboxEvents(ctx, time.Now().Add(-r.Retention))
+	} else {
+		deleted, err = tx.Outbox().DeleteOutboxEventsCreatedBefore(ctx, time.Now().Add(-r.Retention))
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		log.Printf("outbox: cleanup failed: %s", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("outbox: deleted %d expired events", deleted)
+	}
+}
@@ -0,0 +1,59 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+)
+
+// Sink publishes events. Publish returns nil only once the event has been
+// accepted; the event may still be published again later.
+type Sink interface {
+	Publish(ctx context.Context, event database.Outbox) error
+}
+
+// LogSink writes the type and ids of events to the log, for development.
+// Payloads are left out: they hold names in plaintext.
+type LogSink struct{}
+
+func (LogSink) Publish(ctx context.Context, event database.Outbox) error {
+	log.Printf("event %s %s of human %s", event.EventType, event.EventID, event.HumanID)
+	return nil
+}
+
+// HTTPSink posts the payload of every event to a URL. The event id and
+// type are also sent as headers.
+type HTTPSink struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewHTTPSink(url string) *HTTPSink {
+	return &HTTPSink{URL: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *HTTPSink) Publish(ctx context.Context, event database.Outbox) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(event.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.EventID.String())
+	req.Header.Set("X-Event-Type", event.EventType)
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("sink responded with %s", resp.Status)
+	}
+	return nil
+}
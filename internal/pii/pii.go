@@ -0,0 +1,209 @@
+// Package pii encrypts personal data with envelope encryption. Every record
+// gets its own AES-GCM data key, stored next to the record wrapped by a
+// long lived key encryption key. Rotating the key encryption key therefore
+// only rewraps data keys. A keyed hash, the blind index, lets equal values
+// be found without decrypting them.
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the size of every key, which makes AES-256.
+const KeySize = 32
+
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// Keyring holds the key encryption keys by id and the blind index key.
+// New data keys are wrapped by the current key, the others are kept to
+// unwrap data keys that have not been rotated yet.
+type Keyring struct {
+	current  string
+	keys     map[string]cipher.AEAD
+	indexKey []byte
+}
+
+func NewKeyring(current string, keys map[string][]byte, indexKey []byte) (*Keyring, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("current key %q is not in the keyring", current)
+	}
+	if len(indexKey) != KeySize {
+		return nil, fmt.Errorf("index key must be %d bytes", KeySize)
+	}
+	keyring := &Keyring{current: current, keys: make(map[string]cipher.AEAD, len(keys)), indexKey: indexKey}
+	for id, key := range keys {
+		if id == "" {
+			return nil, errors.New("key id cannot be empty")
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		keyring.keys[id] = aead
+	}
+	return keyring, nil
+}
+
+// ParseKey decodes a base64 encoded key.
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("key is not valid base64: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// CurrentKeyID is the id of the key new data keys are wrapped with.
+func (k *Keyring) CurrentKeyID() string {
+	return k.current
+}
+
+// BlindIndex returns a keyed hash of value. Equal values of the same tenant
+// hash equally; the hash reveals nothing else about the value.
+func (k *Keyring) BlindIndex(tenantID, value string) []byte {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(tenantID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// NewDataKey generates a data key for a record of the tenant and returns it
+// with its wrapped form and the id of the key that wrapped it.
+func (k *Keyring) NewDataKey(tenantID string) (*DataKey, string, []byte, error) {
+	raw := make([]byte, KeySize)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", nil, err
+	}
+	dataKey, err := newDataKey(raw)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	wrapped, err := k.wrap(tenantID, k.current, raw)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return dataKey, k.current, wrapped, nil
+}
+
+// OpenDataKey unwraps a data key stored with a record of the tenant.
+func (k *Keyring) OpenDataKey(tenantID, keyID string, wrapped []byte) (*DataKey, error) {
+	raw, err := k.unwrap(tenantID, keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	return newDataKey(raw)
+}
+
+// Rewrap wraps a data key again with the current key. The data encrypted
+// with it does not change.
+func (k *Keyring) Rewrap(tenantID, keyID string, wrapped []byte) (string, []byte, error) {
+	raw, err := k.unwrap(tenantID, keyID, wrapped)
+	if err != nil {
+		return "", nil, err
+	}
+	rewrapped, err := k.wrap(tenantID, k.current, raw)
+	if err != nil {
+		return "", nil, err
+	}
+	return k.current, rewrapped, nil
+}
+
+// The tenant and key id are bound to the wrapped key as additional data,
+// so a wrapped key copied to another tenant's record does not open.
+func (k *Keyring) wrap(tenantID, keyID string, raw []byte) ([]byte, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+	return seal(aead, raw, wrapAD(tenantID, keyID))
+}
+
+func (k *Keyring) unwrap(tenantID, keyID string, wrapped []byte) ([]byte, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+	raw, err := open(aead, wrapped, wrapAD(tenantID, keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return raw, nil
+}
+
+func wrapAD(tenantID, keyID string) []byte {
+	return []byte(tenantID + "\x00" + keyID)
+}
+
+// DataKey encrypts the fields of a single record.
+type DataKey struct {
+	aead cipher.AEAD
+}
+
+func newDataKey(raw []byte) (*DataKey, error) {
+	aead, err := newAEAD(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &DataKey{aead: aead}, nil
+}
+
+// Encrypt returns the base64 encoded ciphertext of value. The field name is
+// authenticated with it, so ciphertexts cannot be swapped between fields.
+func (d *DataKey) Encrypt(field, value string) (string, error) {
+	sealed, err := seal(d.aead, []byte(value), []byte(field))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (d *DataKey) Decrypt(field, ciphertext string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode %s: %w", field, err)
+	}
+	value, err := open(d.aead, sealed, []byte(field))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: %w", field, err)
+	}
+	return string(value), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes", KeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal prefixes the ciphertext with a random nonce.
+func seal(aead cipher.AEAD, plaintext, ad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, ad), nil
+}
+
+func open(aead cipher.AEAD, sealed, ad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, ad)
+}
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/pii"
+)
+
+// EncryptedHumanRepository encrypts the name, surname and patronymic of
+// humans before they reach the wrapped repository and decrypts them on the
+// way back, so the layers above only see plaintext. Rows written before
+// encryption was enabled have no key id and are returned as stored.
+type EncryptedHumanRepository struct {
+	HumanRepository
+	keys *pii.Keyring
+}
+
+func NewEncryptedHumanRepository(repo HumanRepository, keys *pii.Keyring) *EncryptedHumanRepository {
+	return &EncryptedHumanRepository{HumanRepository: repo, keys: keys}
+}
+
+var _ HumanRepository = (*EncryptedHumanRepository)(nil)
+
+func (r *EncryptedHumanRepository) CreateHuman(ctx context.Context, arg database.CreateHumanParams) (database.Human, error) {
+	sealed, err := sealPII(r.keys, arg.TenantID, arg.Name, arg.Surname, arg.Patronymic)
+	if err != nil {
+		return database.Human{}, err
+	}
+	arg.Name, arg.Surname, arg.Patronymic = sealed.name, sealed.surname, sealed.patronymic
+	arg.NameIndex, arg.PiiKeyID, arg.PiiDek = sealed.nameIndex, sealed.keyID, sealed.dataKey
+	return r.open(r.HumanRepository.CreateHuman(ctx, arg))
+}
+
+func (r *EncryptedHumanRepository) GetHumanByID(ctx context.Context, arg database.GetHumanByIDParams) (database.Human, error) {
+	return r.open(r.HumanRepository.GetHumanByID(ctx, arg))
+}
+
+func (r *EncryptedHumanRepository) ListHumans(ctx context.Context, arg database.ListHumansParams) ([]database.Human, error) {
+	return r.openAll(r.HumanRepository.ListHumans(ctx, arg))
+}
+
+func (r *EncryptedHumanRepository) StreamHumans(ctx context.Context, arg database.ListHumansParams, fn func(database.Human) error) error {
+	return r.HumanRepository.StreamHumans(ctx, arg, func(human database.Human) error {
+		if err := openPII(r.keys, &human); err != nil {
+			return err
+		}
+		return fn(human)
+	})
+}
+
+// UpdateHuman seals the new values with a fresh data key.
+func (r *EncryptedHumanRepository) UpdateHuman(ctx context.Context, arg database.UpdateHumanParams) (database.Human, error) {
+	sealed, err := sealPII(r.keys, arg.TenantID, arg.Name, arg.Surname, arg.Patronymic)
+	if err != nil {
+		return database.Human{}, err
+	}
+	arg.Name, arg.Surname, arg.Patronymic = sealed.name, sealed.surname, sealed.patronymic
+	arg.NameIndex, arg.PiiKeyID, arg.PiiDek = sealed.nameIndex, sealed.keyID, sealed.dataKey
+	return r.open(r.HumanRepository.UpdateHuman(ctx, arg))
+}
+
+func (r *EncryptedHumanRepository) DeleteHuman(ctx context.Context, arg database.DeleteHumanParams) (database.Human, error) {
+	return r.open(r.HumanRepository.DeleteHuman(ctx, arg))
+}
+
+func (r *EncryptedHumanRepository) ListHumansByIDs(ctx context.Context, arg database.ListHumansByIDsParams) ([]database.Human, error) {
+	return r.openAll(r.HumanRepository.ListHumansByIDs(ctx, arg))
+}
+
+func (r *EncryptedHumanRepository) ListRelatives(ctx context.Context, arg database.ListRelativesParams) ([]database.ListRelativesRow, error) {
+	rows, err := r.HumanRepository.ListRelatives(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		if err := openPII(r.keys, &rows[i].Human); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+// maxEncryptedDuplicateScan bounds the humans ListDuplicateCandidates
+// decrypts per call. Every row costs a data key unwrap and two decryptions,
+// so larger tenants get ErrTooManyToCompare instead of a scan that grows
+// with the tenant on every request.
+const maxEncryptedDuplicateScan = 10000
+
+// ListDuplicateCandidates compares the decrypted names in process, since
+// the database cannot compare ciphertexts. Only names and surnames are
+// decrypted.
+func (r *EncryptedHumanRepository) ListDuplicateCandidates(ctx context.Context, arg database.ListDuplicateCandidatesParams) ([]database.ListDuplicateCandidatesRow, error) {
+	var humans []database.Human
+	err := r.HumanRepository.StreamHumans(ctx, database.ListHumansParams{
+		TenantID:   arg.TenantID,
+		Tags:       []string{},
+		Attributes: json.RawMessage("{}"),
+		Sort:       "created_at",
+	}, func(human database.Human) error {
+		if len(humans) == maxEncryptedDuplicateScan {
+			return ErrTooManyToCompare
+		}
+		if err := openNames(r.keys, &human); err != nil {
+			return err
+		}
+		humans = append(humans, human)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return similarNamePairs(humans, arg.MaxPairs), nil
+}
+
+func (r *EncryptedHumanRepository) open(human database.Human, err error) (database.Human, error) {
+	if err != nil {
+		return human, err
+	}
+	if err := openPII(r.keys, &human); err != nil {
+		return database.Human{}, err
+	}
+	return human, nil
+}
+
+func (r *EncryptedHumanRepository) openAll(humans []database.Human, err error) ([]database.Human, error) {
+	if err != nil {
+		return nil, err
+	}
+	for i := range humans {
+		if err := openPII(r.keys, &humans[i]); err != nil {
+			return nil, err
+		}
+	}
+	return humans, nil
+}
+
+// NewEncryptedTransactor wraps the repositories of every transaction opened
+// by t in an EncryptedHumanRepository, and has the outbox and webhook
+// repositories open the event payloads it sealed.
+func NewEncryptedTransactor(t Transactor, keys *pii.Keyring) Transactor {
+	return &encryptedTransactor{Transactor: t, keys: keys}
+}
+
+type encryptedTransactor struct {
+	Transactor
+	keys *pii.Keyring
+}
+
+func (t *encryptedTransactor) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	tx, err := t.Transactor.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedTx{Tx: tx, keys: t.keys}, nil
+}
+
+type encryptedTx struct {
+	Tx
+	keys *pii.Keyring
+}
+
+func (t *encryptedTx) Humans() HumanRepository {
+	return NewEncryptedHumanRepository(t.Tx.Humans(), t.keys)
+}
+
+func (t *encryptedTx) Outbox() OutboxRepository {
+	return &encryptedOutbox{OutboxRepository: t.Tx.Outbox(), keys: t.keys}
+}
+
+func (t *encryptedTx) Webhooks() WebhookRepository {
+	return &encryptedWebhooks{WebhookRepository: t.Tx.Webhooks(), keys: t.keys}
+}
+
+// sealedPII is the stored form of a human's personal data.
+type sealedPII struct {
+	name, surname string
+	patronymic    sql.NullString
+	nameIndex     []byte
+	keyID         sql.NullString
+	dataKey       []byte
+}
+
+func sealPII(keys *pii.Keyring, tenantID, name, surname string, patronymic sql.NullString) (sealedPII, error) {
+	dataKey, keyID, wrapped, err := keys.NewDataKey(tenantID)
+	if err != nil {
+		return sealedPII{}, fmt.Errorf("failed to create data key: %w", err)
+	}
+	sealed := sealedPII{
+		nameIndex: keys.BlindIndex(tenantID, name),
+		keyID:     sql.NullString{String: keyID, Valid: true},
+		dataKey:   wrapped,
+	}
+	if sealed.name, err = dataKey.Encrypt("name", name); err != nil {
+		return sealedPII{}, err
+	}
+	if sealed.surname, err = dataKey.Encrypt("surname", surname); err != nil {
+		return sealedPII{}, err
+	}
+	if patronymic.Valid {
+		encrypted, err := dataKey.Encrypt("patronymic", patronymic.String)
+		if err != nil {
+			return sealedPII{}, err
+		}
+		sealed.patronymic = sql.NullString{String: encrypted, Valid: true}
+	}
+	return sealed, nil
+}
+
+// openPII decrypts the personal data of human in place.
+func openPII(keys *pii.Keyring, human *database.Human) error {
+	dataKey, err := openNamesWithKey(keys, human)
+	if err != nil || dataKey == nil {
+		return err
+	}
+	if human.Patronymic.Valid {
+		if human.Patronymic.String, err = dataKey.Decrypt("patronymic", human.Patronymic.String); err != nil {
+			return fmt.Errorf("human %s: %w", human.ID, err)
+		}
+	}
+	return nil
+}
+
+// openNames decrypts only the name and surname of human in place.
+func openNames(keys *pii.Keyring, human *database.Human) error {
+	_, err := openNamesWithKey(keys, human)
+	return err
+}
+
+// openNamesWithKey decrypts the name and surname of human in place and
+// returns its data key, or nil for a plaintext row.
+func openNamesWithKey(keys *pii.Keyring, human *database.Human) (*pii.DataKey, error) {
+	if !human.PiiKeyID.Valid {
+		return nil, nil
+	}
+	dataKey, err := keys.OpenDataKey(human.TenantID, human.PiiKeyID.String, human.PiiDek)
+	if err != nil {
+		return nil, fmt.Errorf("human %s: %w", human.ID, err)
+	}
+	if human.Name, err = dataKey.Decrypt("name", human.Name); err != nil {
+		return nil, fmt.Errorf("human %s: %w", human.ID, err)
+	}
+	if human.Surname, err = dataKey.Decrypt("surname", human.Surname); err != nil {
+		return nil, fmt.Errorf("human %s: %w", human.ID, err)
+	}
+	return dataKey, nil
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/pii"
+)
+
+// sealedEvent is the stored form of an event payload, which holds the
+// human's names. It gets a data key of its own rather than the human's,
+// since events outlive deleted humans.
+type sealedEvent struct {
+	KeyID   string `json:"pii_key_id"`
+	DataKey []byte `json:"pii_dek"`
+	Sealed  string `json:"sealed"`
+}
+
+func sealEventPayload(keys *pii.Keyring, tenantID string, payload json.RawMessage) (json.RawMessage, error) {
+	dataKey, keyID, wrapped, err := keys.NewDataKey(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data key: %w", err)
+	}
+	sealed, err := dataKey.Encrypt("payload", string(payload))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(sealedEvent{KeyID: keyID, DataKey: wrapped, Sealed: sealed})
+}
+
+// OpenEventPayload decrypts an event payload of the tenant as stored by
+// EncryptedHumanRepository. Payloads written before encryption was enabled
+// are returned as stored.
+func OpenEventPayload(keys *pii.Keyring, tenantID string, payload json.RawMessage) (json.RawMessage, error) {
+	var sealed sealedEvent
+	if err := json.Unmarshal(payload, &sealed); err != nil || sealed.Sealed == "" {
+		return payload, nil
+	}
+	dataKey, err := keys.OpenDataKey(tenantID, sealed.KeyID, sealed.DataKey)
+	if err != nil {
+		return nil, err
+	}
+	opened, err := dataKey.Decrypt("payload", sealed.Sealed)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(opened), nil
+}
+
+// rotatedEventPayload rewraps the data key of a sealed payload with the
+// current key and seals a plaintext one.
+func rotatedEventPayload(keys *pii.Keyring, tenantID string, payload json.RawMessage) (json.RawMessage, error) {
+	var sealed sealedEvent
+	if err := json.Unmarshal(payload, &sealed); err != nil || sealed.Sealed == "" {
+		return sealEventPayload(keys, tenantID, payload)
+	}
+	keyID, wrapped, err := keys.Rewrap(tenantID, sealed.KeyID, sealed.DataKey)
+	if err != nil {
+		return nil, err
+	}
+	sealed.KeyID, sealed.DataKey = keyID, wrapped
+	return json.Marshal(sealed)
+}
+
+// CreateOutboxEvent seals the payload, which is stored until the event is
+// delivered and cleaned up.
+func (r *EncryptedHumanRepository) CreateOutboxEvent(ctx context.Context, arg database.CreateOutboxEventParams) (database.Outbox, error) {
+	sealed, err := sealEventPayload(r.keys, arg.TenantID, arg.Payload)
+	if err != nil {
+		return database.Outbox{}, err
+	}
+	arg.Payload = sealed
+	event, err := r.HumanRepository.CreateOutboxEvent(ctx, arg)
+	if err != nil {
+		return event, err
+	}
+	return openOutboxEvent(r.keys, event)
+}
+
+// CreateWebhookDelivery seals the payload, which is kept with the delivery
+// log until the delivery is cleaned up.
+func (r *EncryptedHumanRepository) CreateWebhookDelivery(ctx context.Context, arg database.CreateWebhookDeliveryParams) error {
+	sealed, err := sealEventPayload(r.keys, arg.TenantID, arg.Payload)
+	if err != nil {
+		return err
+	}
+	arg.Payload = sealed
+	return r.HumanRepository.CreateWebhookDelivery(ctx, arg)
+}
+
+func (r *EncryptedHumanRepository) ListOutboxEventsOfHuman(ctx context.Context, arg database.ListOutboxEventsOfHumanParams) ([]database.Outbox, error) {
+	events, err := r.HumanRepository.ListOutboxEventsOfHuman(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+	for i := range events {
+		if events[i], err = openOutboxEvent(r.keys, events[i]); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+func (r *EncryptedHumanRepository) ListWebhookDeliveriesOfHuman(ctx context.Context, arg database.ListWebhookDeliveriesOfHumanParams) ([]database.WebhookDelivery, error) {
+	deliveries, err := r.HumanRepository.ListWebhookDeliveriesOfHuman(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+	for i := range deliveries {
+		if deliveries[i], err = openWebhookDelivery(r.keys, deliveries[i]); err != nil {
+			return nil, err
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *EncryptedHumanRepository) ListWebhookDeliveries(ctx context.Context, arg database.ListWebhookDeliveriesParams) ([]database.WebhookDelivery, error) {
+	deliveries, err := r.HumanRepository.ListWebhookDeliveries(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+	for i := range deliveries {
+		if deliveries[i], err = openWebhookDelivery(r.keys, deliveries[i]); err != nil {
+			return nil, err
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *EncryptedHumanRepository) GetWebhookDelivery(ctx context.Context, arg database.GetWebhookDeliveryParams) (database.WebhookDelivery, error) {
+	delivery, err := r.HumanRepository.GetWebhookDelivery(ctx, arg)
+	if err != nil {
+		return delivery, err
+	}
+	return openWebhookDelivery(r.keys, delivery)
+}
+
+func (r *EncryptedHumanRepository) RedeliverWebhookDelivery(ctx context.Context, arg database.RedeliverWebhookDeliveryParams) (database.WebhookDelivery, error) {
+	delivery, err := r.HumanRepository.RedeliverWebhookDelivery(ctx, arg)
+	if err != nil {
+		return delivery, err
+	}
+	return openWebhookDelivery(r.keys, delivery)
+}
+
+func openOutboxEvent(keys *pii.Keyring, event database.Outbox) (database.Outbox, error) {
+	var err error
+	if event.Payload, err = OpenEventPayload(keys, event.TenantID, event.Payload); err != nil {
+		return database.Outbox{}, fmt.Errorf("event %s: %w", event.EventID, err)
+	}
+	return event, nil
+}
+
+func openWebhookDelivery(keys *pii.Keyring, delivery database.WebhookDelivery) (database.WebhookDelivery, error) {
+	var err error
+	if delivery.Payload, err = OpenEventPayload(keys, delivery.TenantID, delivery.Payload); err != nil {
+		return database.WebhookDelivery{}, fmt.Errorf("webhook delivery %s: %w", delivery.ID, err)
+	}
+	return delivery, nil
+}
+
+// encryptedOutbox opens the payloads the outbox relay publishes.
+type encryptedOutbox struct {
+	OutboxRepository
+	keys *pii.Keyring
+}
+
+func (r *encryptedOutbox) ClaimOutboxEvents(ctx context.Context, batchSize int32) ([]database.Outbox, error) {
+	events, err := r.OutboxRepository.ClaimOutboxEvents(ctx, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	for i := range events {
+		if events[i], err = openOutboxEvent(r.keys, events[i]); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// encryptedWebhooks opens the payloads the webhook dispatcher sends.
+type encryptedWebhooks struct {
+	WebhookRepository
+	keys *pii.Keyring
+}
+
+func (r *encryptedWebhooks) ClaimWebhookDeliveries(ctx context.Context, arg database.ClaimWebhookDeliveriesParams) ([]database.ClaimWebhookDeliveriesRow, error) {
+	deliveries, err := r.WebhookRepository.ClaimWebhookDeliveries(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		if delivery.Payload, err = OpenEventPayload(r.keys, delivery.TenantID, delivery.Payload); err != nil {
+			return nil, fmt.Errorf("webhook delivery %s: %w", delivery.ID, err)
+		}
+	}
+	return deliveries, nil
+}
+
+// NewEncryptedEventReader opens the payloads of the events read through
+// events.
+func NewEncryptedEventReader(events EventReader, keys *pii.Keyring) EventReader {
+	return &encryptedEventReader{EventReader: events, keys: keys}
+}
+
+type encryptedEventReader struct {
+	EventReader
+	keys *pii.Keyring
+}
+
+func (r *encryptedEventReader) GetOutboxEvent(ctx context.Context, id int64) (database.Outbox, error) {
+	event, err := r.EventReader.GetOutboxEvent(ctx, id)
+	if err != nil {
+		return event, err
+	}
+	return openOutboxEvent(r.keys, event)
+}
+
+func (r *encryptedEventReader) ListOutboxEventsAfter(ctx context.Context, arg database.ListOutboxEventsAfterParams) ([]database.Outbox, error) {
+	events, err := r.EventReader.ListOutboxEventsAfter(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+	for i := range events {
+		if events[i], err = openOutboxEvent(r.keys, events[i]); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
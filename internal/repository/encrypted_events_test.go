@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/pii"
+)
+
+func testKeyring(t *testing.T, current string, ids ...string) *pii.Keyring {
+	t.Helper()
+	keys := make(map[string][]byte, len(ids))
+	for i, id := range ids {
+		keys[id] = bytes.Repeat([]byte{byte(i + 1)}, pii.KeySize)
+	}
+	keyring, err := pii.NewKeyring(current, keys, bytes.Repeat([]byte{0xff}, pii.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+func TestEncryptedEventPayloads(t *testing.T) {
+	ctx := context.Background()
+	keys := testKeyring(t, "k1", "k1")
+	memory := NewMemoryHumanRepository()
+	transactor := NewEncryptedTransactor(memory, keys)
+	payload := json.RawMessage(`{"id":"e1","human":{"name":"Ivan"}}`)
+
+	sub, err := memory.CreateWebhookSubscription(ctx, database.CreateWebhookSubscriptionParams{
+		TenantID: "a", Url: "https://example.com/hook", Secret: "secret", EventTypes: []string{"HumanCreated"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := transactor.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, err := tx.Humans().CreateOutboxEvent(ctx, database.CreateOutboxEventParams{
+		EventID: uuid.New(), TenantID: "a", HumanID: uuid.New(), EventType: "HumanCreated", Payload: payload,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tx.Humans().CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+		TenantID: "a", SubscriptionID: sub.ID, EventID: event.EventID, HumanID: event.HumanID, EventType: "HumanCreated", Payload: payload,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	stored := []json.RawMessage{memory.store.outbox[0].Payload, memory.store.deliveries[0].Payload}
+	for _, p := range stored {
+		if bytes.Contains(p, []byte("Ivan")) {
+			t.Fatalf("payload stored in plaintext: %s", p)
+		}
+	}
+
+	tx, err = transactor.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	events, err := tx.Outbox().ClaimOutboxEvents(ctx, 10)
+	if err != nil || len(events) != 1 || !bytes.Equal(events[0].Payload, payload) {
+		t.Fatalf("relay got %+v, %v", events, err)
+	}
+	deliveries, err := tx.Webhooks().ClaimWebhookDeliveries(ctx, database.ClaimWebhookDeliveriesParams{BatchSize: 10})
+	if err != nil || len(deliveries) != 1 || !bytes.Equal(deliveries[0].Payload, payload) {
+		t.Fatalf("dispatcher got %+v, %v", deliveries, err)
+	}
+	logged, err := tx.Humans().ListWebhookDeliveries(ctx, database.ListWebhookDeliveriesParams{TenantID: "a", SubscriptionID: sub.ID, MaxRows: 10})
+	if err != nil || len(logged) != 1 || !bytes.Equal(logged[0].Payload, payload) {
+		t.Fatalf("delivery log got %+v, %v", logged, err)
+	}
+
+	t.Run("rotation", func(t *testing.T) {
+		rotating := testKeyring(t, "k2", "k1", "k2")
+		rotated, err := rotatedEventPayload(rotating, "a", stored[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Once rotated, the old key can be removed.
+		opened, err := OpenEventPayload(testKeyring(t, "k2", "x", "k2"), "a", rotated)
+		if err != nil || !bytes.Equal(opened, payload) {
+			t.Fatalf("got %s, %v", opened, err)
+		}
+		if _, err := OpenEventPayload(rotating, "b", rotated); err == nil {
+			t.Fatal("another tenant opened the payload")
+		}
+	})
+
+	t.Run("plaintext", func(t *testing.T) {
+		opened, err := OpenEventPayload(keys, "a", payload)
+		if err != nil || !bytes.Equal(opened, payload) {
+			t.Fatalf("got %s, %v", opened, err)
+		}
+		sealed, err := rotatedEventPayload(keys, "a", payload)
+		if err != nil || bytes.Contains(sealed, []byte("Ivan")) {
+			t.Fatalf("got %s, %v", sealed, err)
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+)
+
+// Errors returned by non-SQL implementations when a write breaks a
+// constraint the Postgres schema would enforce.
+var (
+	ErrUniqueViolation     = errors.New("unique constraint violation")
+	ErrForeignKeyViolation = errors.New("foreign key constraint violation")
+	ErrCheckViolation      = errors.New("check constraint violation")
+)
+
+// ErrTooManyToCompare is returned by ListDuplicateCandidates when the
+// tenant has more humans than can be compared outside the database.
+var ErrTooManyToCompare = errors.New("too many humans to compare")
+
+// HumanRepository is the storage used by the service layer. Every method
+// is scoped to a tenant. Lookups of missing rows, including rows owned by
+// another tenant, return sql.ErrNoRows, as the sqlc queries do.
+type HumanRepository interface {
+	CreateHuman(ctx context.Context, arg database.CreateHumanParams) (database.Human, error)
+	GetHumanByID(ctx context.Context, arg database.GetHumanByIDParams) (database.Human, error)
+	ListHumans(ctx context.Context, arg database.ListHumansParams) ([]database.Human, error)
+	// StreamHumans passes the humans ListHumans would return to fn one at a
+	// time. The SQL implementation must run in a transaction.
+	StreamHumans(ctx context.Context, arg database.ListHumansParams, fn func(database.Human) error) error
+	UpdateHuman(ctx context.Context, arg database.UpdateHumanParams) (database.Human, error)
+	DeleteHuman(ctx context.Context, arg database.DeleteHumanParams) (database.Human, error)
+	CountHumansByGender(ctx context.Context, arg database.CountHumansByGenderParams) ([]database.CountHumansByGenderRow, error)
+	CountHumansByCountry(ctx context.Context, arg database.CountHumansByCountryParams) ([]database.CountHumansByCountryRow, error)
+	CountHumansByAgeBucket(ctx context.Context, arg database.CountHumansByAgeBucketParams) ([]database.CountHumansByAgeBucketRow, error)
+	ListHumansByIDs(ctx context.Context, arg database.ListHumansByIDsParams) ([]database.Human, error)
+
+	CreateRelationship(ctx context.Context, arg database.CreateRelationshipParams) (database.HumanRelationship, error)
+	DeleteRelationship(ctx context.Context, arg database.DeleteRelationshipParams) (database.HumanRelationship, error)
+	ListRelatives(ctx context.Context, arg database.ListRelativesParams) ([]database.ListRelativesRow, error)
+	ListAncestorIDs(ctx context.Context, arg database.ListAncestorIDsParams) ([]uuid.UUID, error)
+	WalkFamilyTree(ctx context.Context, arg database.WalkFamilyTreeParams) ([]database.WalkFamilyTreeRow, error)
+	ListRelationshipsAmong(ctx context.Context, arg database.ListRelationshipsAmongParams) ([]database.HumanRelationship, error)
+	ListRelationshipsOfHuman(ctx context.Context, arg database.ListRelationshipsOfHumanParams) ([]database.HumanRelationship, error)
+
+	CreateContact(ctx context.Context, arg database.CreateContactParams) (database.HumanContact, error)
+	GetContact(ctx context.Context, arg database.GetContactParams) (database.HumanContact, error)
+	ListContacts(ctx context.Context, arg database.ListContactsParams) ([]database.HumanContact, error)
+	ListContactsByHumanIDs(ctx context.Context, arg database.ListContactsByHumanIDsParams) ([]database.HumanContact, error)
+	UpdateContact(ctx context.Context, arg database.UpdateContactParams) (database.HumanContact, error)
+	DeleteContact(ctx context.Context, arg database.DeleteContactParams) (database.HumanContact, error)
+	ClearPrimaryContact(ctx context.Context, arg database.ClearPrimaryContactParams) error
+	DeleteContactsOfHuman(ctx context.Context, arg database.DeleteContactsOfHumanParams) error
+
+	UpsertTag(ctx context.Context, arg database.UpsertTagParams) (database.Tag, error)
+	AttachTag(ctx context.Context, arg database.AttachTagParams) error
+	DetachTag(ctx context.Context, arg database.DetachTagParams) (int64, error)
+	ListTagsByHumanIDs(ctx context.Context, arg database.ListTagsByHumanIDsParams) ([]database.ListTagsByHumanIDsRow, error)
+
+	ListAttributeDefinitions(ctx context.Context, tenantID string) ([]database.AttributeDefinition, error)
+	UpsertAttributeDefinition(ctx context.Context, arg database.UpsertAttributeDefinitionParams) (database.AttributeDefinition, error)
+	DeleteAttributeDefinition(ctx context.Context, arg database.DeleteAttributeDefinitionParams) (database.AttributeDefinition, error)
+	RemoveHumanAttribute(ctx context.Context, arg database.RemoveHumanAttributeParams) error
+
+	CreateImportJob(ctx context.Context, arg database.CreateImportJobParams) (database.ImportJob, error)
+	GetImportJob(ctx context.Context, arg database.GetImportJobParams) (database.ImportJob, error)
+	UpdateImportJobProgress(ctx context.Context, arg database.UpdateImportJobProgressParams) (database.ImportJob, error)
+	CreateImportError(ctx context.Context, arg database.CreateImportErrorParams) error
+	ListImportErrors(ctx context.Context, arg database.ListImportErrorsParams) ([]database.ImportJobError, error)
+
+	// ListDuplicateCandidates returns pairs of humans with similar full
+	// names, most similar first.
+	ListDuplicateCandidates(ctx context.Context, arg database.ListDuplicateCandidatesParams) ([]database.ListDuplicateCandidatesRow, error)
+	MoveContacts(ctx context.Context, arg database.MoveContactsParams) error
+	CopyHumanTags(ctx context.Context, arg database.CopyHumanTagsParams) error
+	MoveRelationships(ctx context.Context, arg database.MoveRelationshipsParams) error
+
+	// CreateOutboxEvent records an event to be published once the
+	// transaction it was written in commits.
+	CreateOutboxEvent(ctx context.Context, arg database.CreateOutboxEventParams) (database.Outbox, error)
+	ListOutboxEventsOfHuman(ctx context.Context, arg database.ListOutboxEventsOfHumanParams) ([]database.Outbox, error)
+	// DeleteOutboxEventsOfHuman drops the events of a human, delivered or
+	// not, when their payloads must no longer be kept.
+	DeleteOutboxEventsOfHuman(ctx context.Context, arg database.DeleteOutboxEventsOfHumanParams) (int64, error)
+	// NotifyHumanErased makes the live event feeds forget the events of a
+	// human they buffer once the transaction commits.
+	NotifyHumanErased(ctx context.Context, arg database.NotifyHumanErasedParams) error
+
+	CreateWebhookSubscription(ctx context.Context, arg database.CreateWebhookSubscriptionParams) (database.WebhookSubscription, error)
+	GetWebhookSubscription(ctx context.Context, arg database.GetWebhookSubscriptionParams) (database.WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context, tenantID string) ([]database.WebhookSubscription, error)
+	UpdateWebhookSubscription(ctx context.Context, arg database.UpdateWebhookSubscriptionParams) (database.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, arg database.DeleteWebhookSubscriptionParams) (database.WebhookSubscription, error)
+	// ListWebhookSubscriptionsForEvent returns the enabled subscriptions
+	// that accept the event type.
+	ListWebhookSubscriptionsForEvent(ctx context.Context, arg database.ListWebhookSubscriptionsForEventParams) ([]database.WebhookSubscription, error)
+	// CreateWebhookDelivery schedules an event for a subscription. An event
+	// already scheduled for the subscription is ignored.
+	CreateWebhookDelivery(ctx context.Context, arg database.CreateWebhookDeliveryParams) error
+	ListWebhookDeliveriesOfHuman(ctx context.Context, arg database.ListWebhookDeliveriesOfHumanParams) ([]database.WebhookDelivery, error)
+	// DeleteWebhookDeliveriesOfHuman drops the pending and finished
+	// deliveries of a human's events together with their attempt logs.
+	DeleteWebhookDeliveriesOfHuman(ctx context.Context, arg database.DeleteWebhookDeliveriesOfHumanParams) (int64, error)
+	ListWebhookDeliveries(ctx context.Context, arg database.ListWebhookDeliveriesParams) ([]database.WebhookDelivery, error)
+	GetWebhookDelivery(ctx context.Context, arg database.GetWebhookDeliveryParams) (database.WebhookDelivery, error)
+	ListWebhookDeliveryAttempts(ctx context.Context, arg database.ListWebhookDeliveryAttemptsParams) ([]database.WebhookDeliveryAttempt, error)
+	RedeliverWebhookDelivery(ctx context.Context, arg database.RedeliverWebhookDeliveryParams) (database.WebhookDelivery, error)
+}
+
+// NewSQLHumanRepository returns the Postgres implementation backed by the
+// sqlc generated queries.
+func NewSQLHumanRepository(db database.DBTX) HumanRepository {
+	return database.New(db)
+}
+
+// OutboxRepository is the storage used by the outbox relay. Unlike
+// HumanRepository it spans all tenants.
+type OutboxRepository interface {
+	// ClaimOutboxEvents returns the oldest undelivered events, skipping
+	// events claimed by other transactions.
+	ClaimOutboxEvents(ctx context.Context, batchSize int32) ([]database.Outbox, error)
+	MarkOutboxEventDelivered(ctx context.Context, id int64) error
+	RecordOutboxFailure(ctx context.Context, arg database.RecordOutboxFailureParams) error
+	DeleteDeliveredOutboxEvents(ctx context.Context, deliveredBefore time.Time) (int64, error)
+	// DeleteOutboxEventsCreatedBefore deletes old events whether they were
+	// delivered or not.
+	DeleteOutboxEventsCreatedBefore(ctx context.Context, createdBefore time.Time) (int64, error)
+}
+
+// EventReader reads the outbox events of every tenant for the live event
+// stream.
+type EventReader interface {
+	GetOutboxEvent(ctx context.Context, id int64) (database.Outbox, error)
+	ListOutboxEventsAfter(ctx context.Context, arg database.ListOutboxEventsAfterParams) ([]database.Outbox, error)
+}
+
+// WebhookRepository is the storage used by the webhook dispatcher. Like
+// OutboxRepository it spans all tenants.
+type WebhookRepository interface {
+	// ClaimWebhookDeliveries returns due deliveries of enabled
+	// subscriptions and postpones their next attempt to leaseUntil, so
+	// that they are not claimed again while being sent.
+	ClaimWebhookDeliveries(ctx context.Context, arg database.ClaimWebhookDeliveriesParams) ([]database.ClaimWebhookDeliveriesRow, error)
+	CreateWebhookDeliveryAttempt(ctx context.Context, arg database.CreateWebhookDeliveryAttemptParams) error
+	UpdateWebhookDeliveryResult(ctx context.Context, arg database.UpdateWebhookDeliveryResultParams) error
+	ResetWebhookSubscriptionFailures(ctx context.Context, id uuid.UUID) error
+	// RecordWebhookSubscriptionFailure counts a delivery that ran out of
+	// attempts, disabling the subscription after MaxFailures in a row.
+	RecordWebhookSubscriptionFailure(ctx context.Context, arg database.RecordWebhookSubscriptionFailureParams) (database.WebhookSubscription, error)
+	DeleteFinishedWebhookDeliveries(ctx context.Context, updatedBefore time.Time) (int64, error)
+}
+
+// ImportRepository is the storage used to sweep abandoned imports. Like
+// OutboxRepository it spans all tenants.
+type ImportRepository interface {
+	// FailStaleImportJobs fails pending and running jobs that have not
+	// saved progress since updatedBefore.
+	FailStaleImportJobs(ctx context.Context, updatedBefore time.Time) (int64, error)
+}
+
+var (
+	_ HumanRepository   = (*database.Queries)(nil)
+	_ OutboxRepository  = (*database.Queries)(nil)
+	_ EventReader       = (*database.Queries)(nil)
+	_ WebhookRepository = (*database.Queries)(nil)
+	_ ImportRepository  = (*database.Queries)(nil)
+)
+
+// TagsMatch decides the tag filter of ListHumans outside the database, the
+// way the human_tags_match SQL function does: a human passes when has
+// reports any of the wanted tags, or all of them with matchAll. An empty
+// filter passes everyone.
+func TagsMatch(has func(tag string) bool, wanted []string, matchAll bool) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	matched := 0
+	for _, tag := range slices.Compact(slices.Sorted(slices.Values(wanted))) {
+		if has(tag) {
+			matched++
+		}
+	}
+	if matchAll {
+		return matched >= len(wanted)
+	}
+	return matched > 0
+}
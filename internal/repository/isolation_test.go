@@ -0,0 +1,458 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/lib/pq"
+)
+
+// tenantFixture is the data of one tenant the other tenant must not reach.
+type tenantFixture struct {
+	human, relative database.Human
+	contact         database.HumanContact
+	job             database.ImportJob
+	webhook         database.WebhookSubscription
+	delivery        database.WebhookDelivery
+}
+
+func seedTenant(t *testing.T, repo HumanRepository, txr Transactor, tenantID string) tenantFixture {
+	t.Helper()
+	ctx := context.Background()
+	var f tenantFixture
+	var err error
+	if _, err = repo.UpsertAttributeDefinition(ctx, database.UpsertAttributeDefinitionParams{
+		TenantID: tenantID, Name: "level", Type: "integer", EnumValues: json.RawMessage("null"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if f.human, err = repo.CreateHuman(ctx, database.CreateHumanParams{
+		TenantID: tenantID, Name: "Ivan", Surname: "Petrov", Age: 30, Gender: "male", Country: "RU",
+		Attributes: json.RawMessage(`{"level":3}`),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if f.relative, err = repo.CreateHuman(ctx, database.CreateHumanParams{
+		TenantID: tenantID, Name: "Oleg", Surname: "Petrov", Age: 60, Gender: "male", Country: "RU",
+		Attributes: json.RawMessage("{}"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = repo.CreateRelationship(ctx, database.CreateRelationshipParams{
+		TenantID: tenantID, HumanID: f.human.ID, RelativeID: f.relative.ID, Relation: "parent",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if f.contact, err = repo.CreateContact(ctx, database.CreateContactParams{
+		TenantID: tenantID, HumanID: f.human.ID, Type: "email", Value: "ivan@example.com", IsPrimary: true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	tag, err := repo.UpsertTag(ctx, database.UpsertTagParams{TenantID: tenantID, Name: "vip"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = repo.AttachTag(ctx, database.AttachTagParams{TenantID: tenantID, HumanID: f.human.ID, TagID: tag.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if f.job, err = repo.CreateImportJob(ctx, database.CreateImportJobParams{TenantID: tenantID, Format: "csv", TotalRows: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err = repo.CreateImportError(ctx, database.CreateImportErrorParams{
+		TenantID: tenantID, JobID: f.job.ID, RowNumber: 2, Code: "validation", Message: "bad row", Fields: json.RawMessage("[]"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	eventID := uuid.New()
+	if _, err = repo.CreateOutboxEvent(ctx, database.CreateOutboxEventParams{
+		EventID: eventID, TenantID: tenantID, HumanID: f.human.ID, EventType: "HumanCreated", Payload: json.RawMessage(`{"name":"Ivan"}`),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if f.webhook, err = repo.CreateWebhookSubscription(ctx, database.CreateWebhookSubscriptionParams{
+		TenantID: tenantID, Url: "https://example.com/hook", EventTypes: []string{}, Secret: "secret",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err = repo.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+		TenantID: tenantID, SubscriptionID: f.webhook.ID, EventID: eventID, HumanID: f.human.ID,
+		EventType: "HumanCreated", Payload: json.RawMessage(`{"name":"Ivan"}`),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	deliveries, err := repo.ListWebhookDeliveriesOfHuman(ctx, database.ListWebhookDeliveriesOfHumanParams{TenantID: tenantID, HumanID: f.human.ID})
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("got deliveries %+v, %v", deliveries, err)
+	}
+	f.delivery = deliveries[0]
+	// Attempts are written by the dispatcher, which spans all tenants.
+	tx, err := txr.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if err = tx.Webhooks().CreateWebhookDeliveryAttempt(ctx, database.CreateWebhookDeliveryAttemptParams{
+		DeliveryID: f.delivery.ID, ResponseStatus: sql.NullInt32{Int32: 500, Valid: true}, DurationMs: 10,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// testTenantIsolation seeds tenant a and calls every tenant scoped method
+// as tenant b with the ids of tenant a. Each call must fail as if the rows
+// did not exist or see nothing, and leave the rows of tenant a as they
+// were. StreamHumans runs the ListHumans query and is covered by it.
+func testTenantIsolation(t *testing.T, repo HumanRepository, txr Transactor, a, b string) {
+	ctx := context.Background()
+	f := seedTenant(t, repo, txr, a)
+	filter := database.ListHumansParams{TenantID: b, Tags: []string{}, Attributes: json.RawMessage("{}"), Sort: "created_at"}
+
+	tests := []struct {
+		name string
+		// call returns the number of rows tenant b got back or changed.
+		call func() (int, error)
+	}{
+		{"get human", func() (int, error) {
+			_, err := repo.GetHumanByID(ctx, database.GetHumanByIDParams{TenantID: b, ID: f.human.ID})
+			return 1, err
+		}},
+		{"update human", func() (int, error) {
+			_, err := repo.UpdateHuman(ctx, database.UpdateHumanParams{
+				TenantID: b, ID: f.human.ID, Name: "Ivan", Surname: "Sidorov", Attributes: json.RawMessage("{}"),
+			})
+			return 1, err
+		}},
+		{"delete human", func() (int, error) {
+			_, err := repo.DeleteHuman(ctx, database.DeleteHumanParams{TenantID: b, ID: f.human.ID})
+			return 1, err
+		}},
+		{"list humans", func() (int, error) {
+			humans, err := repo.ListHumans(ctx, filter)
+			return len(humans), err
+		}},
+		{"list humans by tag", func() (int, error) {
+			arg := filter
+			arg.Tags = []string{"vip"}
+			humans, err := repo.ListHumans(ctx, arg)
+			return len(humans), err
+		}},
+		{"list humans by attribute", func() (int, error) {
+			arg := filter
+			arg.Attributes = json.RawMessage(`{"level":3}`)
+			humans, err := repo.ListHumans(ctx, arg)
+			return len(humans), err
+		}},
+		{"list humans by ids", func() (int, error) {
+			humans, err := repo.ListHumansByIDs(ctx, database.ListHumansByIDsParams{
+				TenantID: b, Ids: []uuid.UUID{f.human.ID, f.relative.ID},
+			})
+			return len(humans), err
+		}},
+		{"count by gender", func() (int, error) {
+			rows, err := repo.CountHumansByGender(ctx, database.CountHumansByGenderParams{
+				TenantID: b, Tags: []string{}, Attributes: json.RawMessage("{}"),
+			})
+			return len(rows), err
+		}},
+		{"count by country", func() (int, error) {
+			rows, err := repo.CountHumansByCountry(ctx, database.CountHumansByCountryParams{
+				TenantID: b, Tags: []string{}, Attributes: json.RawMessage("{}"),
+			})
+			return len(rows), err
+		}},
+		{"count by age bucket", func() (int, error) {
+			rows, err := repo.CountHumansByAgeBucket(ctx, database.CountHumansByAgeBucketParams{
+				BucketWidth: 10, TenantID: b, Tags: []string{}, Attributes: json.RawMessage("{}"),
+			})
+			return len(rows), err
+		}},
+		{"create relationship", func() (int, error) {
+			_, err := repo.CreateRelationship(ctx, database.CreateRelationshipParams{
+				TenantID: b, HumanID: f.human.ID, RelativeID: f.relative.ID, Relation: "sibling",
+			})
+			return 1, err
+		}},
+		{"delete relationship", func() (int, error) {
+			_, err := repo.DeleteRelationship(ctx, database.DeleteRelationshipParams{
+				TenantID: b, HumanID: f.human.ID, RelativeID: f.relative.ID, Relation: "parent",
+			})
+			return 1, err
+		}},
+		{"list relatives", func() (int, error) {
+			rows, err := repo.ListRelatives(ctx, database.ListRelativesParams{TenantID: b, HumanID: f.human.ID})
+			return len(rows), err
+		}},
+		{"list ancestors", func() (int, error) {
+			ids, err := repo.ListAncestorIDs(ctx, database.ListAncestorIDsParams{TenantID: b, HumanID: f.human.ID})
+			return len(ids), err
+		}},
+		{"walk family tree", func() (int, error) {
+			// The walk always starts with the given id itself, so only the
+			// relatives it reaches count.
+			rows, err := repo.WalkFamilyTree(ctx, database.WalkFamilyTreeParams{TenantID: b, HumanID: f.human.ID, MaxDepth: 3})
+			return len(rows) - 1, err
+		}},
+		{"list relationships among", func() (int, error) {
+			rels, err := repo.ListRelationshipsAmong(ctx, database.ListRelationshipsAmongParams{
+				TenantID: b, Ids: []uuid.UUID{f.human.ID, f.relative.ID},
+			})
+			return len(rels), err
+		}},
+		{"list relationships of human", func() (int, error) {
+			rels, err := repo.ListRelationshipsOfHuman(ctx, database.ListRelationshipsOfHumanParams{TenantID: b, HumanID: f.human.ID})
+			return len(rels), err
+		}},
+		{"move relationships", func() (int, error) {
+			return 0, repo.MoveRelationships(ctx, database.MoveRelationshipsParams{TenantID: b, SourceID: f.human.ID, TargetID: uuid.New()})
+		}},
+		{"create contact", func() (int, error) {
+			_, err := repo.CreateContact(ctx, database.CreateContactParams{
+				TenantID: b, HumanID: f.human.ID, Type: "email", Value: "b@example.com",
+			})
+			return 1, err
+		}},
+		{"get contact", func() (int, error) {
+			_, err := repo.GetContact(ctx, database.GetContactParams{TenantID: b, HumanID: f.human.ID, ID: f.contact.ID})
+			return 1, err
+		}},
+		{"list contacts", func() (int, error) {
+			contacts, err := repo.ListContacts(ctx, database.ListContactsParams{TenantID: b, HumanID: f.human.ID})
+			return len(contacts), err
+		}},
+		{"list contacts by human ids", func() (int, error) {
+			contacts, err := repo.ListContactsByHumanIDs(ctx, database.ListContactsByHumanIDsParams{
+				TenantID: b, HumanIds: []uuid.UUID{f.human.ID},
+			})
+			return len(contacts), err
+		}},
+		{"update contact", func() (int, error) {
+			_, err := repo.UpdateContact(ctx, database.UpdateContactParams{
+				TenantID: b, HumanID: f.human.ID, ID: f.contact.ID, Type: "email", Value: "b@example.com",
+			})
+			return 1, err
+		}},
+		{"delete contact", func() (int, error) {
+			_, err := repo.DeleteContact(ctx, database.DeleteContactParams{TenantID: b, HumanID: f.human.ID, ID: f.contact.ID})
+			return 1, err
+		}},
+		{"clear primary contact", func() (int, error) {
+			return 0, repo.ClearPrimaryContact(ctx, database.ClearPrimaryContactParams{
+				TenantID: b, HumanID: f.human.ID, Type: "email", ID: uuid.New(),
+			})
+		}},
+		{"delete contacts of human", func() (int, error) {
+			return 0, repo.DeleteContactsOfHuman(ctx, database.DeleteContactsOfHumanParams{TenantID: b, HumanID: f.human.ID})
+		}},
+		{"move contacts", func() (int, error) {
+			return 0, repo.MoveContacts(ctx, database.MoveContactsParams{TenantID: b, SourceID: f.human.ID, TargetID: f.relative.ID})
+		}},
+		{"attach tag", func() (int, error) {
+			tag, err := repo.UpsertTag(ctx, database.UpsertTagParams{TenantID: b, Name: "stolen"})
+			if err != nil {
+				return 0, err
+			}
+			return 1, repo.AttachTag(ctx, database.AttachTagParams{TenantID: b, HumanID: f.human.ID, TagID: tag.ID})
+		}},
+		{"detach tag", func() (int, error) {
+			n, err := repo.DetachTag(ctx, database.DetachTagParams{TenantID: b, HumanID: f.human.ID, Name: "vip"})
+			return int(n), err
+		}},
+		{"list tags", func() (int, error) {
+			rows, err := repo.ListTagsByHumanIDs(ctx, database.ListTagsByHumanIDsParams{TenantID: b, HumanIds: []uuid.UUID{f.human.ID}})
+			return len(rows), err
+		}},
+		{"copy tags", func() (int, error) {
+			return 0, repo.CopyHumanTags(ctx, database.CopyHumanTagsParams{TenantID: b, SourceID: f.human.ID, TargetID: f.relative.ID})
+		}},
+		{"list attribute definitions", func() (int, error) {
+			defs, err := repo.ListAttributeDefinitions(ctx, b)
+			return len(defs), err
+		}},
+		{"delete attribute definition", func() (int, error) {
+			_, err := repo.DeleteAttributeDefinition(ctx, database.DeleteAttributeDefinitionParams{TenantID: b, Name: "level"})
+			return 1, err
+		}},
+		{"remove human attribute", func() (int, error) {
+			return 0, repo.RemoveHumanAttribute(ctx, database.RemoveHumanAttributeParams{TenantID: b, Name: "level"})
+		}},
+		{"duplicates", func() (int, error) {
+			rows, err := repo.ListDuplicateCandidates(ctx, database.ListDuplicateCandidatesParams{TenantID: b, MaxPairs: 10})
+			return len(rows), err
+		}},
+		{"get import job", func() (int, error) {
+			_, err := repo.GetImportJob(ctx, database.GetImportJobParams{TenantID: b, ID: f.job.ID})
+			return 1, err
+		}},
+		{"update import job", func() (int, error) {
+			_, err := repo.UpdateImportJobProgress(ctx, database.UpdateImportJobProgressParams{
+				TenantID: b, ID: f.job.ID, Status: "failed", ProcessedRows: 2, FailedRows: 2,
+			})
+			return 1, err
+		}},
+		{"create import error", func() (int, error) {
+			return 1, repo.CreateImportError(ctx, database.CreateImportErrorParams{
+				TenantID: b, JobID: f.job.ID, RowNumber: 3, Code: "validation", Message: "stolen", Fields: json.RawMessage("[]"),
+			})
+		}},
+		{"list import errors", func() (int, error) {
+			rows, err := repo.ListImportErrors(ctx, database.ListImportErrorsParams{TenantID: b, JobID: f.job.ID})
+			return len(rows), err
+		}},
+		{"list events of human", func() (int, error) {
+			events, err := repo.ListOutboxEventsOfHuman(ctx, database.ListOutboxEventsOfHumanParams{TenantID: b, HumanID: f.human.ID})
+			return len(events), err
+		}},
+		{"delete events of human", func() (int, error) {
+			n, err := repo.DeleteOutboxEventsOfHuman(ctx, database.DeleteOutboxEventsOfHumanParams{TenantID: b, HumanID: f.human.ID})
+			return int(n), err
+		}},
+		{"get webhook", func() (int, error) {
+			_, err := repo.GetWebhookSubscription(ctx, database.GetWebhookSubscriptionParams{TenantID: b, ID: f.webhook.ID})
+			return 1, err
+		}},
+		{"list webhooks", func() (int, error) {
+			webhooks, err := repo.ListWebhookSubscriptions(ctx, b)
+			return len(webhooks), err
+		}},
+		{"list webhooks for event", func() (int, error) {
+			webhooks, err := repo.ListWebhookSubscriptionsForEvent(ctx, database.ListWebhookSubscriptionsForEventParams{
+				TenantID: b, EventType: "HumanCreated",
+			})
+			return len(webhooks), err
+		}},
+		{"update webhook", func() (int, error) {
+			_, err := repo.UpdateWebhookSubscription(ctx, database.UpdateWebhookSubscriptionParams{
+				TenantID: b, ID: f.webhook.ID, Url: "https://example.org/stolen", EventTypes: []string{}, Secret: "stolen",
+			})
+			return 1, err
+		}},
+		{"delete webhook", func() (int, error) {
+			_, err := repo.DeleteWebhookSubscription(ctx, database.DeleteWebhookSubscriptionParams{TenantID: b, ID: f.webhook.ID})
+			return 1, err
+		}},
+		{"create webhook delivery", func() (int, error) {
+			return 1, repo.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+				TenantID: b, SubscriptionID: f.webhook.ID, EventID: uuid.New(), HumanID: f.human.ID,
+				EventType: "HumanUpdated", Payload: json.RawMessage("{}"),
+			})
+		}},
+		{"list webhook deliveries", func() (int, error) {
+			deliveries, err := repo.ListWebhookDeliveries(ctx, database.ListWebhookDeliveriesParams{
+				TenantID: b, SubscriptionID: f.webhook.ID, MaxRows: 10,
+			})
+			return len(deliveries), err
+		}},
+		{"list webhook deliveries of human", func() (int, error) {
+			deliveries, err := repo.ListWebhookDeliveriesOfHuman(ctx, database.ListWebhookDeliveriesOfHumanParams{TenantID: b, HumanID: f.human.ID})
+			return len(deliveries), err
+		}},
+		{"get webhook delivery", func() (int, error) {
+			_, err := repo.GetWebhookDelivery(ctx, database.GetWebhookDeliveryParams{
+				TenantID: b, SubscriptionID: f.webhook.ID, ID: f.delivery.ID,
+			})
+			return 1, err
+		}},
+		{"list webhook delivery attempts", func() (int, error) {
+			attempts, err := repo.ListWebhookDeliveryAttempts(ctx, database.ListWebhookDeliveryAttemptsParams{TenantID: b, DeliveryID: f.delivery.ID})
+			return len(attempts), err
+		}},
+		{"redeliver webhook delivery", func() (int, error) {
+			_, err := repo.RedeliverWebhookDelivery(ctx, database.RedeliverWebhookDeliveryParams{
+				TenantID: b, SubscriptionID: f.webhook.ID, ID: f.delivery.ID,
+			})
+			return 1, err
+		}},
+		{"delete webhook deliveries of human", func() (int, error) {
+			n, err := repo.DeleteWebhookDeliveriesOfHuman(ctx, database.DeleteWebhookDeliveriesOfHumanParams{TenantID: b, HumanID: f.human.ID})
+			return int(n), err
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n, err := tc.call()
+			switch {
+			case errors.Is(err, sql.ErrNoRows), isForeignKeyViolation(err):
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case n != 0:
+				t.Fatalf("got %d rows of tenant %s", n, a)
+			}
+		})
+	}
+
+	t.Run("tenant a is untouched", func(t *testing.T) {
+		human, err := repo.GetHumanByID(ctx, database.GetHumanByIDParams{TenantID: a, ID: f.human.ID})
+		var attributes map[string]int
+		if err == nil {
+			err = json.Unmarshal(human.Attributes, &attributes)
+		}
+		if err != nil || human.Surname != "Petrov" || attributes["level"] != 3 {
+			t.Fatalf("got %+v, %v", human, err)
+		}
+		contacts, err := repo.ListContacts(ctx, database.ListContactsParams{TenantID: a, HumanID: f.human.ID})
+		if err != nil || len(contacts) != 1 || contacts[0].Value != "ivan@example.com" || !contacts[0].IsPrimary {
+			t.Fatalf("got contacts %+v, %v", contacts, err)
+		}
+		relatives, err := repo.ListRelatives(ctx, database.ListRelativesParams{TenantID: a, HumanID: f.human.ID})
+		if err != nil || len(relatives) != 1 {
+			t.Fatalf("got relatives %+v, %v", relatives, err)
+		}
+		tags, err := repo.ListTagsByHumanIDs(ctx, database.ListTagsByHumanIDsParams{TenantID: a, HumanIds: []uuid.UUID{f.human.ID, f.relative.ID}})
+		if err != nil || len(tags) != 1 || tags[0].Name != "vip" {
+			t.Fatalf("got tags %+v, %v", tags, err)
+		}
+		defs, err := repo.ListAttributeDefinitions(ctx, a)
+		if err != nil || len(defs) != 1 || defs[0].Type != "integer" {
+			t.Fatalf("got definitions %+v, %v", defs, err)
+		}
+		job, err := repo.GetImportJob(ctx, database.GetImportJobParams{TenantID: a, ID: f.job.ID})
+		if err != nil || job.Status != f.job.Status || job.ProcessedRows != 0 {
+			t.Fatalf("got import job %+v, %v", job, err)
+		}
+		importErrors, err := repo.ListImportErrors(ctx, database.ListImportErrorsParams{TenantID: a, JobID: f.job.ID})
+		if err != nil || len(importErrors) != 1 {
+			t.Fatalf("got import errors %+v, %v", importErrors, err)
+		}
+		events, err := repo.ListOutboxEventsOfHuman(ctx, database.ListOutboxEventsOfHumanParams{TenantID: a, HumanID: f.human.ID})
+		if err != nil || len(events) != 1 {
+			t.Fatalf("got events %+v, %v", events, err)
+		}
+		webhook, err := repo.GetWebhookSubscription(ctx, database.GetWebhookSubscriptionParams{TenantID: a, ID: f.webhook.ID})
+		if err != nil || webhook.Url != f.webhook.Url {
+			t.Fatalf("got webhook %+v, %v", webhook, err)
+		}
+		delivery, err := repo.GetWebhookDelivery(ctx, database.GetWebhookDeliveryParams{TenantID: a, SubscriptionID: f.webhook.ID, ID: f.delivery.ID})
+		if err != nil || delivery.Status != f.delivery.Status || delivery.Attempts != f.delivery.Attempts {
+			t.Fatalf("got delivery %+v, %v", delivery, err)
+		}
+		deliveries, err := repo.ListWebhookDeliveries(ctx, database.ListWebhookDeliveriesParams{TenantID: a, SubscriptionID: f.webhook.ID, MaxRows: 10})
+		if err != nil || len(deliveries) != 1 {
+			t.Fatalf("got deliveries %+v, %v", deliveries, err)
+		}
+		attempts, err := repo.ListWebhookDeliveryAttempts(ctx, database.ListWebhookDeliveryAttemptsParams{TenantID: a, DeliveryID: f.delivery.ID})
+		if err != nil || len(attempts) != 1 {
+			t.Fatalf("got attempts %+v, %v", attempts, err)
+		}
+	})
+}
+
+// isForeignKeyViolation reports a write rejected because the row it
+// references does not exist for the tenant.
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23503"
+	}
+	return errors.Is(err, ErrForeignKeyViolation)
+}
@@ -0,0 +1,491 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+)
+
+// MemoryHumanRepository keeps humans in process memory. It is safe for
+// concurrent use and is meant for tests and running without a database.
+// Transactions take an exclusive lock and are therefore serializable.
+type MemoryHumanRepository struct {
+	mu    sync.RWMutex
+	store *memoryStore
+	// onOutbox is called with every outbox event once it is committed.
+	onOutbox func(database.Outbox)
+	// onErased is called with every committed NotifyHumanErased.
+	onErased func(database.NotifyHumanErasedParams)
+}
+
+func NewMemoryHumanRepository() *MemoryHumanRepository {
+	return &MemoryHumanRepository{store: newMemoryStore()}
+}
+
+var (
+	_ HumanRepository   = (*MemoryHumanRepository)(nil)
+	_ Transactor        = (*MemoryHumanRepository)(nil)
+	_ HumanRepository   = (*memoryStore)(nil)
+	_ OutboxRepository  = (*memoryStore)(nil)
+	_ WebhookRepository = (*memoryStore)(nil)
+)
+
+func (r *MemoryHumanRepository) CreateHuman(ctx context.Context, arg database.CreateHumanParams) (database.Human, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.CreateHuman(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) GetHumanByID(ctx context.Context, arg database.GetHumanByIDParams) (database.Human, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.GetHumanByID(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) ListHumans(ctx context.Context, arg database.ListHumansParams) ([]database.Human, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.ListHumans(ctx, arg)
+}
+
+// StreamHumans calls fn without holding the lock, on a snapshot of the
+// matching humans.
+func (r *MemoryHumanRepository) StreamHumans(ctx context.Context, arg database.ListHumansParams, fn func(database.Human) error) error {
+	r.mu.RLock()
+	humans, err := r.store.ListHumans(ctx, arg)
+	r.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return streamHumans(humans, fn)
+}
+
+func (r *MemoryHumanRepository) UpdateHuman(ctx context.Context, arg database.UpdateHumanParams) (database.Human, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.UpdateHuman(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) DeleteHuman(ctx context.Context, arg database.DeleteHumanParams) (database.Human, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.DeleteHuman(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) CountHumansByGender(ctx context.Context, arg database.CountHumansByGenderParams) ([]database.CountHumansByGenderRow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.CountHumansByGender(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) CountHumansByCountry(ctx context.Context, arg database.CountHumansByCountryParams) ([]database.CountHumansByCountryRow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.CountHumansByCountry(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) CountHumansByAgeBucket(ctx context.Context, arg database.CountHumansByAgeBucketParams) ([]database.CountHumansByAgeBucketRow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.CountHumansByAgeBucket(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) ListHumansByIDs(ctx context.Context, arg database.ListHumansByIDsParams) ([]database.Human, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.ListHumansByIDs(ctx, arg)
+}
+
+// BeginTx locks the repository until the transaction is committed or
+// rolled back. Isolation options are ignored.
+func (r *MemoryHumanRepository) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	return &memoryTx{repo: r, snapshot: r.store.clone()}, nil
+}
+
+type memoryTx struct {
+	repo     *MemoryHumanRepository
+	snapshot *memoryStore
+	done     bool
+}
+
+func (t *memoryTx) Humans() HumanRepository {
+	return t.repo.store
+}
+
+func (t *memoryTx) Outbox() OutboxRepository {
+	return t.repo.store
+}
+
+func (t *memoryTx) Webhooks() WebhookRepository {
+	return t.repo.store
+}
+
+func (t *memoryTx) Imports() ImportRepository {
+	return t.repo.store
+}
+
+func (t *memoryTx) Commit() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	erasures := t.repo.store.erasures
+	t.repo.store.erasures = nil
+	var created []database.Outbox
+	if t.repo.onOutbox != nil {
+		for _, event := range t.repo.store.outbox {
+			if event.ID > t.snapshot.lastOutboxID {
+				created = append(created, event)
+			}
+		}
+	}
+	t.repo.mu.Unlock()
+	// Erasures go first, as the events recorded after them are kept.
+	if t.repo.onErased != nil {
+		for _, erasure := range erasures {
+			t.repo.onErased(erasure)
+		}
+	}
+	for _, event := range created {
+		t.repo.onOutbox(event)
+	}
+	return nil
+}
+
+func (t *memoryTx) Rollback() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	t.repo.store = t.snapshot
+	t.repo.mu.Unlock()
+	return nil
+}
+
+// memoryStore holds the tables. It does no locking of its own.
+type memoryStore struct {
+	humans        map[uuid.UUID]database.Human
+	order         []uuid.UUID
+	relationships []database.HumanRelationship
+	contacts      []database.HumanContact
+	tags          []database.Tag
+	humanTags     []memoryHumanTag
+	attributeDefs []database.AttributeDefinition
+	importJobs    map[uuid.UUID]database.ImportJob
+	importErrors  []database.ImportJobError
+	outbox        []database.Outbox
+	lastOutboxID  int64
+	webhooks      []database.WebhookSubscription
+	deliveries    []database.WebhookDelivery
+	attempts      []database.WebhookDeliveryAttempt
+	lastAttemptID int64
+	// erasures are the NotifyHumanErased calls of the open transaction,
+	// announced when it commits.
+	erasures []database.NotifyHumanErasedParams
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		humans:     make(map[uuid.UUID]database.Human),
+		importJobs: make(map[uuid.UUID]database.ImportJob),
+	}
+}
+
+func (s *memoryStore) clone() *memoryStore {
+	c := &memoryStore{
+		humans:        make(map[uuid.UUID]database.Human, len(s.humans)),
+		order:         append([]uuid.UUID(nil), s.order...),
+		relationships: append([]database.HumanRelationship(nil), s.relationships...),
+		contacts:      append([]database.HumanContact(nil), s.contacts...),
+		tags:          append([]database.Tag(nil), s.tags...),
+		humanTags:     append([]memoryHumanTag(nil), s.humanTags...),
+		attributeDefs: append([]database.AttributeDefinition(nil), s.attributeDefs...),
+		importJobs:    make(map[uuid.UUID]database.ImportJob, len(s.importJobs)),
+		importErrors:  append([]database.ImportJobError(nil), s.importErrors...),
+		outbox:        append([]database.Outbox(nil), s.outbox...),
+		lastOutboxID:  s.lastOutboxID,
+		webhooks:      append([]database.WebhookSubscription(nil), s.webhooks...),
+		deliveries:    append([]database.WebhookDelivery(nil), s.deliveries...),
+		attempts:      append([]database.WebhookDeliveryAttempt(nil), s.attempts...),
+		lastAttemptID: s.lastAttemptID,
+	}
+	for id, human := range s.humans {
+		c.humans[id] = human
+	}
+	for id, job := range s.importJobs {
+		c.importJobs[id] = job
+	}
+	return c
+}
+
+func (s *memoryStore) CreateHuman(ctx context.Context, arg database.CreateHumanParams) (database.Human, error) {
+	if s.nameTaken(arg.TenantID, arg.Name, arg.NameIndex, uuid.Nil) {
+		return database.Human{}, ErrUniqueViolation
+	}
+	now := time.Now().UTC()
+	human := database.Human{
+		ID:         uuid.New(),
+		TenantID:   arg.TenantID,
+		Name:       arg.Name,
+		Surname:    arg.Surname,
+		Patronymic: arg.Patronymic,
+		Age:        arg.Age,
+		Gender:     arg.Gender,
+		Country:    arg.Country,
+		Attributes: cloneJSON(arg.Attributes),
+		NameIndex:  arg.NameIndex,
+		PiiKeyID:   arg.PiiKeyID,
+		PiiDek:     arg.PiiDek,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	s.humans[human.ID] = human
+	s.order = append(s.order, human.ID)
+	return human, nil
+}
+
+func (s *memoryStore) GetHumanByID(ctx context.Context, arg database.GetHumanByIDParams) (database.Human, error) {
+	human, ok := s.humans[arg.ID]
+	if !ok || human.TenantID != arg.TenantID {
+		return database.Human{}, sql.ErrNoRows
+	}
+	return human, nil
+}
+
+func (s *memoryStore) ListHumans(ctx context.Context, arg database.ListHumansParams) ([]database.Human, error) {
+	humans := make([]database.Human, 0, len(s.order))
+	for _, id := range s.order {
+		human := s.humans[id]
+		if !s.matchesListParams(human, arg) {
+			continue
+		}
+		humans = append(humans, human)
+	}
+	sort.SliceStable(humans, func(i, j int) bool {
+		return lessBySort(humans[i], humans[j], arg.Sort)
+	})
+	return humans, nil
+}
+
+func (s *memoryStore) StreamHumans(ctx context.Context, arg database.ListHumansParams, fn func(database.Human) error) error {
+	humans, err := s.ListHumans(ctx, arg)
+	if err != nil {
+		return err
+	}
+	return streamHumans(humans, fn)
+}
+
+func streamHumans(humans []database.Human, fn func(database.Human) error) error {
+	for _, human := range humans {
+		if err := fn(human); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) UpdateHuman(ctx context.Context, arg database.UpdateHumanParams) (database.Human, error) {
+	human, ok := s.humans[arg.ID]
+	if !ok || human.TenantID != arg.TenantID {
+		return database.Human{}, sql.ErrNoRows
+	}
+	if s.nameTaken(arg.TenantID, arg.Name, arg.NameIndex, arg.ID) {
+		return database.Human{}, ErrUniqueViolation
+	}
+	human.Name = arg.Name
+	human.Surname = arg.Surname
+	human.Patronymic = arg.Patronymic
+	human.Age = arg.Age
+	human.Gender = arg.Gender
+	human.Country = arg.Country
+	human.Attributes = cloneJSON(arg.Attributes)
+	human.NameIndex = arg.NameIndex
+	human.PiiKeyID = arg.PiiKeyID
+	human.PiiDek = arg.PiiDek
+	human.UpdatedAt = time.Now().UTC()
+	s.humans[arg.ID] = human
+	return human, nil
+}
+
+func (s *memoryStore) DeleteHuman(ctx context.Context, arg database.DeleteHumanParams) (database.Human, error) {
+	human, ok := s.humans[arg.ID]
+	if !ok || human.TenantID != arg.TenantID {
+		return database.Human{}, sql.ErrNoRows
+	}
+	delete(s.humans, arg.ID)
+	for i, orderedID := range s.order {
+		if orderedID == arg.ID {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	s.relationships = slices.DeleteFunc(s.relationships, func(rel database.HumanRelationship) bool {
+		return rel.HumanID == arg.ID || rel.RelativeID == arg.ID
+	})
+	s.contacts = slices.DeleteFunc(s.contacts, func(contact database.HumanContact) bool {
+		return contact.HumanID == arg.ID
+	})
+	s.humanTags = slices.DeleteFunc(s.humanTags, func(link memoryHumanTag) bool {
+		return link.HumanID == arg.ID
+	})
+	return human, nil
+}
+
+func (s *memoryStore) CountHumansByGender(ctx context.Context, arg database.CountHumansByGenderParams) ([]database.CountHumansByGenderRow, error) {
+	humans, _ := s.ListHumans(ctx, database.ListHumansParams{
+		TenantID:     arg.TenantID,
+		CreatedSince: arg.CreatedSince,
+		CreatedUntil: arg.CreatedUntil,
+		UpdatedSince: arg.UpdatedSince,
+		UpdatedUntil: arg.UpdatedUntil,
+		Tags:         arg.Tags,
+		MatchAllTags: arg.MatchAllTags,
+		Attributes:   arg.Attributes,
+	})
+	totals := make(map[string]int64)
+	for _, human := range humans {
+		totals[human.Gender]++
+	}
+	rows := make([]database.CountHumansByGenderRow, 0, len(totals))
+	for gender, total := range totals {
+		rows = append(rows, database.CountHumansByGenderRow{Gender: gender, Total: total})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Gender < rows[j].Gender })
+	return rows, nil
+}
+
+func (s *memoryStore) CountHumansByCountry(ctx context.Context, arg database.CountHumansByCountryParams) ([]database.CountHumansByCountryRow, error) {
+	humans, _ := s.ListHumans(ctx, database.ListHumansParams{
+		TenantID:     arg.TenantID,
+		CreatedSince: arg.CreatedSince,
+		CreatedUntil: arg.CreatedUntil,
+		UpdatedSince: arg.UpdatedSince,
+		UpdatedUntil: arg.UpdatedUntil,
+		Tags:         arg.Tags,
+		MatchAllTags: arg.MatchAllTags,
+		Attributes:   arg.Attributes,
+	})
+	totals := make(map[string]int64)
+	ages := make(map[string]int64)
+	for _, human := range humans {
+		totals[human.Country]++
+		ages[human.Country] += int64(human.Age)
+	}
+	rows := make([]database.CountHumansByCountryRow, 0, len(totals))
+	for country, total := range totals {
+		rows = append(rows, database.CountHumansByCountryRow{
+			Country:    country,
+			Total:      total,
+			AverageAge: float64(ages[country]) / float64(total),
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Total != rows[j].Total {
+			return rows[i].Total > rows[j].Total
+		}
+		return rows[i].Country < rows[j].Country
+	})
+	return rows, nil
+}
+
+func (s *memoryStore) CountHumansByAgeBucket(ctx context.Context, arg database.CountHumansByAgeBucketParams) ([]database.CountHumansByAgeBucketRow, error) {
+	humans, _ := s.ListHumans(ctx, database.ListHumansParams{
+		TenantID:     arg.TenantID,
+		CreatedSince: arg.CreatedSince,
+		CreatedUntil: arg.CreatedUntil,
+		UpdatedSince: arg.UpdatedSince,
+		UpdatedUntil: arg.UpdatedUntil,
+		Tags:         arg.Tags,
+		MatchAllTags: arg.MatchAllTags,
+		Attributes:   arg.Attributes,
+	})
+	totals := make(map[int32]int64)
+	for _, human := range humans {
+		totals[(human.Age/arg.BucketWidth)*arg.BucketWidth]++
+	}
+	rows := make([]database.CountHumansByAgeBucketRow, 0, len(totals))
+	for start, total := range totals {
+		rows = append(rows, database.CountHumansByAgeBucketRow{BucketStart: start, Total: total})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].BucketStart < rows[j].BucketStart })
+	return rows, nil
+}
+
+func (s *memoryStore) ListHumansByIDs(ctx context.Context, arg database.ListHumansByIDsParams) ([]database.Human, error) {
+	humans := make([]database.Human, 0, len(arg.Ids))
+	for _, id := range arg.Ids {
+		human, ok := s.humans[id]
+		if ok && human.TenantID == arg.TenantID && !slices.ContainsFunc(humans, func(h database.Human) bool { return h.ID == id }) {
+			humans = append(humans, human)
+		}
+	}
+	sort.Slice(humans, func(i, j int) bool { return bytes.Compare(humans[i].ID[:], humans[j].ID[:]) < 0 })
+	return humans, nil
+}
+
+// nameTaken mirrors the UNIQUE (tenant_id, name) and
+// UNIQUE (tenant_id, name_index) constraints.
+func (s *memoryStore) nameTaken(tenantID, name string, nameIndex []byte, except uuid.UUID) bool {
+	for id, human := range s.humans {
+		if id == except || human.TenantID != tenantID {
+			continue
+		}
+		if human.Name == name || (nameIndex != nil && bytes.Equal(human.NameIndex, nameIndex)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesListParams mirrors the WHERE clause of the ListHumans query.
+func (s *memoryStore) matchesListParams(human database.Human, arg database.ListHumansParams) bool {
+	if human.TenantID != arg.TenantID {
+		return false
+	}
+	if arg.CreatedSince.Valid && human.CreatedAt.Before(arg.CreatedSince.Time) {
+		return false
+	}
+	if arg.CreatedUntil.Valid && !human.CreatedAt.Before(arg.CreatedUntil.Time) {
+		return false
+	}
+	if arg.UpdatedSince.Valid && human.UpdatedAt.Before(arg.UpdatedSince.Time) {
+		return false
+	}
+	if arg.UpdatedUntil.Valid && !human.UpdatedAt.Before(arg.UpdatedUntil.Time) {
+		return false
+	}
+	hasTag := func(tag string) bool { return s.hasTag(human, tag) }
+	if !TagsMatch(hasTag, arg.Tags, arg.MatchAllTags) {
+		return false
+	}
+	return jsonContains(human.Attributes, arg.Attributes)
+}
+
+// lessBySort mirrors the ORDER BY clause of the ListHumans query.
+func lessBySort(a, b database.Human, sortBy string) bool {
+	var ta, tb time.Time
+	switch sortBy {
+	case "created_at", "-created_at":
+		ta, tb = a.CreatedAt, b.CreatedAt
+	case "updated_at", "-updated_at":
+		ta, tb = a.UpdatedAt, b.UpdatedAt
+	}
+	if !ta.Equal(tb) {
+		if sortBy[0] == '-' {
+			return ta.After(tb)
+		}
+		return ta.Before(tb)
+	}
+	return bytes.Compare(a.ID[:], b.ID[:]) < 0
+}
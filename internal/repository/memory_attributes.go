@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+)
+
+func (r *MemoryHumanRepository) ListAttributeDefinitions(ctx context.Context, tenantID string) ([]database.AttributeDefinition, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.ListAttributeDefinitions(ctx, tenantID)
+}
+
+func (r *MemoryHumanRepository) UpsertAttributeDefinition(ctx context.Context, arg database.UpsertAttributeDefinitionParams) (database.AttributeDefinition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.UpsertAttributeDefinition(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) DeleteAttributeDefinition(ctx context.Context, arg database.DeleteAttributeDefinitionParams) (database.AttributeDefinition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.DeleteAttributeDefinition(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) RemoveHumanAttribute(ctx context.Context, arg database.RemoveHumanAttributeParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.RemoveHumanAttribute(ctx, arg)
+}
+
+var attributeTypes = map[string]bool{"string": true, "number": true, "integer": true, "boolean": true, "date": true}
+
+func (s *memoryStore) ListAttributeDefinitions(ctx context.Context, tenantID string) ([]database.AttributeDefinition, error) {
+	defs := make([]database.AttributeDefinition, 0)
+	for _, def := range s.attributeDefs {
+		if def.TenantID == tenantID {
+			defs = append(defs, def)
+		}
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs, nil
+}
+
+func (s *memoryStore) UpsertAttributeDefinition(ctx context.Context, arg database.UpsertAttributeDefinitionParams) (database.AttributeDefinition, error) {
+	// Mirror the table's check constraints.
+	var enum []any
+	if !attributeTypes[arg.Type] || json.Unmarshal(arg.EnumValues, &enum) != nil ||
+		(arg.MaxLength.Valid && arg.MaxLength.Int32 <= 0) ||
+		(arg.Minimum.Valid && arg.Maximum.Valid && arg.Minimum.Float64 > arg.Maximum.Float64) {
+		return database.AttributeDefinition{}, ErrCheckViolation
+	}
+	now := time.Now().UTC()
+	def := database.AttributeDefinition{
+		TenantID:   arg.TenantID,
+		Name:       arg.Name,
+		Type:       arg.Type,
+		Required:   arg.Required,
+		EnumValues: cloneJSON(arg.EnumValues),
+		Pattern:    arg.Pattern,
+		MaxLength:  arg.MaxLength,
+		Minimum:    arg.Minimum,
+		Maximum:    arg.Maximum,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	for i, existing := range s.attributeDefs {
+		if existing.TenantID == arg.TenantID && existing.Name == arg.Name {
+			def.CreatedAt = existing.CreatedAt
+			s.attributeDefs[i] = def
+			return def, nil
+		}
+	}
+	s.attributeDefs = append(s.attributeDefs, def)
+	return def, nil
+}
+
+func (s *memoryStore) DeleteAttributeDefinition(ctx context.Context, arg database.DeleteAttributeDefinitionParams) (database.AttributeDefinition, error) {
+	for i, def := range s.attributeDefs {
+		if def.TenantID == arg.TenantID && def.Name == arg.Name {
+			s.attributeDefs = slices.Delete(s.attributeDefs, i, i+1)
+			return def, nil
+		}
+	}
+	return database.AttributeDefinition{}, sql.ErrNoRows
+}
+
+func (s *memoryStore) RemoveHumanAttribute(ctx context.Context, arg database.RemoveHumanAttributeParams) error {
+	for id, human := range s.humans {
+		if human.TenantID != arg.TenantID {
+			continue
+		}
+		var attributes map[string]json.RawMessage
+		if json.Unmarshal(human.Attributes, &attributes) != nil {
+			continue
+		}
+		if _, ok := attributes[arg.Name]; !ok {
+			continue
+		}
+		delete(attributes, arg.Name)
+		human.Attributes, _ = json.Marshal(attributes)
+		s.humans[id] = human
+	}
+	return nil
+}
+
+func cloneJSON(raw json.RawMessage) json.RawMessage {
+	if raw == nil {
+		return json.RawMessage("{}")
+	}
+	return append(json.RawMessage(nil), raw...)
+}
+
+// jsonContains mirrors the jsonb @> operator.
+func jsonContains(doc, pattern json.RawMessage) bool {
+	var d, p any
+	if json.Unmarshal(doc, &d) != nil || json.Unmarshal(pattern, &p) != nil {
+		return false
+	}
+	return containsValue(d, p)
+}
+
+func containsValue(doc, pattern any) bool {
+	switch p := pattern.(type) {
+	case map[string]any:
+		d, ok := doc.(map[string]any)
+		if !ok {
+			return false
+		}
+		for key, value := range p {
+			if inner, ok := d[key]; !ok || !containsValue(inner, value) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		d, ok := doc.([]any)
+		if !ok {
+			return false
+		}
+		for _, value := range p {
+			if !slices.ContainsFunc(d, func(inner any) bool { return containsValue(inner, value) }) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(doc, pattern)
+	}
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+)
+
+func (r *MemoryHumanRepository) CreateContact(ctx context.Context, arg database.CreateContactParams) (database.HumanContact, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.CreateContact(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) GetContact(ctx context.Context, arg database.GetContactParams) (database.HumanContact, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.GetContact(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) ListContacts(ctx context.Context, arg database.ListContactsParams) ([]database.HumanContact, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.ListContacts(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) ListContactsByHumanIDs(ctx context.Context, arg database.ListContactsByHumanIDsParams) ([]database.HumanContact, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.ListContactsByHumanIDs(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) UpdateContact(ctx context.Context, arg database.UpdateContactParams) (database.HumanContact, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.UpdateContact(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) DeleteContact(ctx context.Context, arg database.DeleteContactParams) (database.HumanContact, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.DeleteContact(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) DeleteContactsOfHuman(ctx context.Context, arg database.DeleteContactsOfHumanParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.DeleteContactsOfHuman(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) ClearPrimaryContact(ctx context.Context, arg database.ClearPrimaryContactParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.ClearPrimaryContact(ctx, arg)
+}
+
+var contactTypes = map[string]bool{"email": true, "phone": true, "address": true}
+
+func (s *memoryStore) CreateContact(ctx context.Context, arg database.CreateContactParams) (database.HumanContact, error) {
+	now := time.Now().UTC()
+	contact := database.HumanContact{
+		ID:         uuid.New(),
+		TenantID:   arg.TenantID,
+		HumanID:    arg.HumanID,
+		Type:       arg.Type,
+		Value:      arg.Value,
+		IsPrimary:  arg.IsPrimary,
+		Street:     arg.Street,
+		City:       arg.City,
+		Region:     arg.Region,
+		PostalCode: arg.PostalCode,
+		Country:    arg.Country,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.checkContact(contact); err != nil {
+		return database.HumanContact{}, err
+	}
+	s.contacts = append(s.contacts, contact)
+	return contact, nil
+}
+
+func (s *memoryStore) GetContact(ctx context.Context, arg database.GetContactParams) (database.HumanContact, error) {
+	i := s.contactIndex(arg.TenantID, arg.HumanID, arg.ID)
+	if i < 0 {
+		return database.HumanContact{}, sql.ErrNoRows
+	}
+	return s.contacts[i], nil
+}
+
+func (s *memoryStore) ListContacts(ctx context.Context, arg database.ListContactsParams) ([]database.HumanContact, error) {
+	return s.ListContactsByHumanIDs(ctx, database.ListContactsByHumanIDsParams{
+		TenantID: arg.TenantID,
+		HumanIds: []uuid.UUID{arg.HumanID},
+	})
+}
+
+func (s *memoryStore) ListContactsByHumanIDs(ctx context.Context, arg database.ListContactsByHumanIDsParams) ([]database.HumanContact, error) {
+	ids := make(map[uuid.UUID]bool, len(arg.HumanIds))
+	for _, id := range arg.HumanIds {
+		ids[id] = true
+	}
+	var contacts []database.HumanContact
+	for _, contact := range s.contacts {
+		if contact.TenantID == arg.TenantID && ids[contact.HumanID] {
+			contacts = append(contacts, contact)
+		}
+	}
+	sort.SliceStable(contacts, func(i, j int) bool {
+		a, b := contacts[i], contacts[j]
+		if c := bytes.Compare(a.HumanID[:], b.HumanID[:]); c != 0 {
+			return c < 0
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.IsPrimary != b.IsPrimary {
+			return a.IsPrimary
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	return contacts, nil
+}
+
+func (s *memoryStore) UpdateContact(ctx context.Context, arg database.UpdateContactParams) (database.HumanContact, error) {
+	i := s.contactIndex(arg.TenantID, arg.HumanID, arg.ID)
+	if i < 0 {
+		return database.HumanContact{}, sql.ErrNoRows
+	}
+	contact := s.contacts[i]
+	contact.Type = arg.Type
+	contact.Value = arg.Value
+	contact.IsPrimary = arg.IsPrimary
+	contact.Street = arg.Street
+	contact.City = arg.City
+	contact.Region = arg.Region
+	contact.PostalCode = arg.PostalCode
+	contact.Country = arg.Country
+	contact.UpdatedAt = time.Now().UTC()
+	if err := s.checkContact(contact); err != nil {
+		return database.HumanContact{}, err
+	}
+	s.contacts[i] = contact
+	return contact, nil
+}
+
+func (s *memoryStore) DeleteContact(ctx context.Context, arg database.DeleteContactParams) (database.HumanContact, error) {
+	i := s.contactIndex(arg.TenantID, arg.HumanID, arg.ID)
+	if i < 0 {
+		return database.HumanContact{}, sql.ErrNoRows
+	}
+	contact := s.contacts[i]
+	s.contacts = append(s.contacts[:i:i], s.contacts[i+1:]...)
+	return contact, nil
+}
+
+func (s *memoryStore) DeleteContactsOfHuman(ctx context.Context, arg database.DeleteContactsOfHumanParams) error {
+	s.contacts = slices.DeleteFunc(s.contacts, func(contact database.HumanContact) bool {
+		return contact.TenantID == arg.TenantID && contact.HumanID == arg.HumanID
+	})
+	return nil
+}
+
+func (s *memoryStore) ClearPrimaryContact(ctx context.Context, arg database.ClearPrimaryContactParams) error {
+	for i, contact := range s.contacts {
+		if contact.TenantID == arg.TenantID && contact.HumanID == arg.HumanID &&
+			contact.Type == arg.Type && contact.IsPrimary && contact.ID != arg.ID {
+			s.contacts[i].IsPrimary = false
+			s.contacts[i].UpdatedAt = time.Now().UTC()
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) contactIndex(tenantID string, humanID, id uuid.UUID) int {
+	for i, contact := range s.contacts {
+		if contact.TenantID == tenantID && contact.HumanID == humanID && contact.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkContact mirrors the constraints of the human_contacts table.
+func (s *memoryStore) checkContact(contact database.HumanContact) error {
+	if !contactTypes[contact.Type] {
+		return ErrCheckViolation
+	}
+	human, ok := s.humans[contact.HumanID]
+	if !ok || human.TenantID != contact.TenantID {
+		return ErrForeignKeyViolation
+	}
+	for _, other := range s.contacts {
+		if other.ID == contact.ID || other.TenantID != contact.TenantID ||
+			other.HumanID != contact.HumanID || other.Type != contact.Type {
+			continue
+		}
+		if other.Value == contact.Value || (other.IsPrimary && contact.IsPrimary) {
+			return ErrUniqueViolation
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+)
+
+// trigramSimilarityThreshold is the default pg_trgm.similarity_threshold
+// used by the % operator.
+const trigramSimilarityThreshold = 0.3
+
+func (r *MemoryHumanRepository) ListDuplicateCandidates(ctx context.Context, arg database.ListDuplicateCandidatesParams) ([]database.ListDuplicateCandidatesRow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.ListDuplicateCandidates(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) MoveContacts(ctx context.Context, arg database.MoveContactsParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.MoveContacts(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) CopyHumanTags(ctx context.Context, arg database.CopyHumanTagsParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.CopyHumanTags(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) MoveRelationships(ctx context.Context, arg database.MoveRelationshipsParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.MoveRelationships(ctx, arg)
+}
+
+func (s *memoryStore) ListDuplicateCandidates(ctx context.Context, arg database.ListDuplicateCandidatesParams) ([]database.ListDuplicateCandidatesRow, error) {
+	var humans []database.Human
+	for _, human := range s.humans {
+		if human.TenantID == arg.TenantID {
+			humans = append(humans, human)
+		}
+	}
+	return similarNamePairs(humans, arg.MaxPairs), nil
+}
+
+// similarNamePairs does what the ListDuplicateCandidates query does with
+// pg_trgm, for humans whose names are not available to the database. Only
+// humans sharing at least one trigram are compared.
+func similarNamePairs(humans []database.Human, maxPairs int32) []database.ListDuplicateCandidatesRow {
+	sort.Slice(humans, func(i, j int) bool { return bytes.Compare(humans[i].ID[:], humans[j].ID[:]) < 0 })
+	grams := make([]map[string]bool, len(humans))
+	postings := make(map[string][]int)
+	for i, human := range humans {
+		grams[i] = trigrams(human.Name + " " + human.Surname)
+		for trigram := range grams[i] {
+			postings[trigram] = append(postings[trigram], i)
+		}
+	}
+
+	rows := make([]database.ListDuplicateCandidatesRow, 0)
+	for i := range humans {
+		compared := make(map[int]bool)
+		for trigram := range grams[i] {
+			for _, j := range postings[trigram] {
+				if j <= i || compared[j] {
+					continue
+				}
+				compared[j] = true
+				similarity := trigramSimilarity(grams[i], grams[j])
+				if similarity >= trigramSimilarityThreshold {
+					rows = append(rows, database.ListDuplicateCandidatesRow{
+						HumanID: humans[i].ID, CandidateID: humans[j].ID, NameSimilarity: similarity,
+					})
+				}
+			}
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].NameSimilarity != rows[j].NameSimilarity {
+			return rows[i].NameSimilarity > rows[j].NameSimilarity
+		}
+		if c := bytes.Compare(rows[i].HumanID[:], rows[j].HumanID[:]); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(rows[i].CandidateID[:], rows[j].CandidateID[:]) < 0
+	})
+	if len(rows) > int(maxPairs) {
+		rows = rows[:maxPairs]
+	}
+	return rows
+}
+
+// trigrams extracts the trigram set of s the way pg_trgm does: every word
+// of letters and digits is lowercased and padded with two spaces in front
+// and one behind.
+func trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+func trigramSimilarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for trigram := range a {
+		if b[trigram] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+func (s *memoryStore) MoveContacts(ctx context.Context, arg database.MoveContactsParams) error {
+	if err := s.checkHuman(arg.TenantID, arg.TargetID); err != nil {
+		return err
+	}
+	targetHas := func(match func(database.HumanContact) bool) bool {
+		return slices.ContainsFunc(s.contacts, func(c database.HumanContact) bool {
+			return c.TenantID == arg.TenantID && c.HumanID == arg.TargetID && match(c)
+		})
+	}
+	now := time.Now().UTC()
+	for i, contact := range s.contacts {
+		if contact.TenantID != arg.TenantID || contact.HumanID != arg.SourceID {
+			continue
+		}
+		if targetHas(func(c database.HumanContact) bool { return c.Type == contact.Type && c.Value == contact.Value }) {
+			continue
+		}
+		contact.IsPrimary = contact.IsPrimary && !targetHas(func(c database.HumanContact) bool { return c.Type == contact.Type && c.IsPrimary })
+		contact.HumanID = arg.TargetID
+		contact.UpdatedAt = now
+		s.contacts[i] = contact
+	}
+	return nil
+}
+
+func (s *memoryStore) CopyHumanTags(ctx context.Context, arg database.CopyHumanTagsParams) error {
+	if err := s.checkHuman(arg.TenantID, arg.TargetID); err != nil {
+		return err
+	}
+	for _, link := range slices.Clone(s.humanTags) {
+		if link.TenantID != arg.TenantID || link.HumanID != arg.SourceID {
+			continue
+		}
+		link.HumanID = arg.TargetID
+		if !slices.Contains(s.humanTags, link) {
+			s.humanTags = append(s.humanTags, link)
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) MoveRelationships(ctx context.Context, arg database.MoveRelationshipsParams) error {
+	if err := s.checkHuman(arg.TenantID, arg.TargetID); err != nil {
+		return err
+	}
+	repoint := func(id uuid.UUID) uuid.UUID {
+		if id == arg.SourceID {
+			return arg.TargetID
+		}
+		return id
+	}
+	for i, rel := range s.relationships {
+		if rel.TenantID != arg.TenantID || (rel.HumanID != arg.SourceID && rel.RelativeID != arg.SourceID) ||
+			rel.HumanID == arg.TargetID || rel.RelativeID == arg.TargetID {
+			continue
+		}
+		moved := rel
+		moved.HumanID, moved.RelativeID = repoint(rel.HumanID), repoint(rel.RelativeID)
+		if slices.ContainsFunc(s.relationships, func(t database.HumanRelationship) bool {
+			return t.TenantID == moved.TenantID && t.HumanID == moved.HumanID &&
+				t.RelativeID == moved.RelativeID && t.Relation == moved.Relation
+		}) {
+			continue
+		}
+		s.relationships[i] = moved
+	}
+	return nil
+}
+
+// checkHuman mirrors the foreign keys from child tables to humans.
+func (s *memoryStore) checkHuman(tenantID string, id uuid.UUID) error {
+	human, ok := s.humans[id]
+	if !ok || human.TenantID != tenantID {
+		return ErrForeignKeyViolation
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+)
+
+func (r *MemoryHumanRepository) CreateImportJob(ctx context.Context, arg database.CreateImportJobParams) (database.ImportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.CreateImportJob(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) GetImportJob(ctx context.Context, arg database.GetImportJobParams) (database.ImportJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.GetImportJob(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) UpdateImportJobProgress(ctx context.Context, arg database.UpdateImportJobProgressParams) (database.ImportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.UpdateImportJobProgress(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) CreateImportError(ctx context.Context, arg database.CreateImportErrorParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.CreateImportError(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) ListImportErrors(ctx context.Context, arg database.ListImportErrorsParams) ([]database.ImportJobError, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.ListImportErrors(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) FailStaleImportJobs(ctx context.Context, updatedBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.FailStaleImportJobs(ctx, updatedBefore)
+}
+
+func (s *memoryStore) CreateImportJob(ctx context.Context, arg database.CreateImportJobParams) (database.ImportJob, error) {
+	if arg.Format != "csv" && arg.Format != "ndjson" {
+		return database.ImportJob{}, ErrCheckViolation
+	}
+	now := time.Now().UTC()
+	job := database.ImportJob{
+		ID:        uuid.New(),
+		TenantID:  arg.TenantID,
+		Format:    arg.Format,
+		Status:    "pending",
+		TotalRows: arg.TotalRows,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.importJobs[job.ID] = job
+	return job, nil
+}
+
+func (s *memoryStore) GetImportJob(ctx context.Context, arg database.GetImportJobParams) (database.ImportJob, error) {
+	job, ok := s.importJobs[arg.ID]
+	if !ok || job.TenantID != arg.TenantID {
+		return database.ImportJob{}, sql.ErrNoRows
+	}
+	return job, nil
+}
+
+var importJobStatuses = map[string]bool{"pending": true, "running": true, "completed": true, "failed": true}
+
+func (s *memoryStore) UpdateImportJobProgress(ctx context.Context, arg database.UpdateImportJobProgressParams) (database.ImportJob, error) {
+	job, ok := s.importJobs[arg.ID]
+	if !ok || job.TenantID != arg.TenantID || !importJobUnfinished(job) {
+		return database.ImportJob{}, sql.ErrNoRows
+	}
+	if !importJobStatuses[arg.Status] {
+		return database.ImportJob{}, ErrCheckViolation
+	}
+	job.Status = arg.Status
+	job.ProcessedRows = arg.ProcessedRows
+	job.CreatedRows = arg.CreatedRows
+	job.FailedRows = arg.FailedRows
+	job.FinishedAt = arg.FinishedAt
+	job.UpdatedAt = time.Now().UTC()
+	s.importJobs[arg.ID] = job
+	return job, nil
+}
+
+func (s *memoryStore) FailStaleImportJobs(ctx context.Context, updatedBefore time.Time) (int64, error) {
+	var failed int64
+	now := time.Now().UTC()
+	for id, job := range s.importJobs {
+		if !importJobUnfinished(job) || !job.UpdatedAt.Before(updatedBefore) {
+			continue
+		}
+		job.Status = "failed"
+		job.FinishedAt = sql.NullTime{Time: now, Valid: true}
+		job.UpdatedAt = now
+		s.importJobs[id] = job
+		failed++
+	}
+	return failed, nil
+}
+
+func importJobUnfinished(job database.ImportJob) bool {
+	return job.Status == "pending" || job.Status == "running"
+}
+
+func (s *memoryStore) CreateImportError(ctx context.Context, arg database.CreateImportErrorParams) error {
+	job, ok := s.importJobs[arg.JobID]
+	if !ok || job.TenantID != arg.TenantID {
+		return ErrForeignKeyViolation
+	}
+	if slices.ContainsFunc(s.importErrors, func(e database.ImportJobError) bool {
+		return e.JobID == arg.JobID && e.RowNumber == arg.RowNumber
+	}) {
+		return ErrUniqueViolation
+	}
+	s.importErrors = append(s.importErrors, database.ImportJobError{
+		TenantID:  arg.TenantID,
+		JobID:     arg.JobID,
+		RowNumber: arg.RowNumber,
+		Code:      arg.Code,
+		Message:   arg.Message,
+		Fields:    arg.Fields,
+	})
+	return nil
+}
+
+func (s *memoryStore) ListImportErrors(ctx context.Context, arg database.ListImportErrorsParams) ([]database.ImportJobError, error) {
+	errs := make([]database.ImportJobError, 0)
+	for _, e := range s.importErrors {
+		if e.TenantID == arg.TenantID && e.JobID == arg.JobID {
+			errs = append(errs, e)
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].RowNumber < errs[j].RowNumber })
+	return errs, nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+)
+
+var outboxEventTypes = map[string]bool{"HumanCreated": true, "HumanUpdated": true, "HumanDeleted": true}
+
+// SetOutboxListener makes the repository call fn with every outbox event
+// once the transaction that wrote it commits, as Postgres notifies
+// listeners. It must be called before the repository is used.
+func (r *MemoryHumanRepository) SetOutboxListener(fn func(database.Outbox)) {
+	r.onOutbox = fn
+}
+
+// SetErasureListener makes the repository call fn with every
+// NotifyHumanErased once its transaction commits. It must be called before
+// the repository is used.
+func (r *MemoryHumanRepository) SetErasureListener(fn func(database.NotifyHumanErasedParams)) {
+	r.onErased = fn
+}
+
+func (r *MemoryHumanRepository) CreateOutboxEvent(ctx context.Context, arg database.CreateOutboxEventParams) (database.Outbox, error) {
+	r.mu.Lock()
+	event, err := r.store.CreateOutboxEvent(ctx, arg)
+	r.mu.Unlock()
+	if err == nil && r.onOutbox != nil {
+		r.onOutbox(event)
+	}
+	return event, err
+}
+
+func (s *memoryStore) CreateOutboxEvent(ctx context.Context, arg database.CreateOutboxEventParams) (database.Outbox, error) {
+	if !outboxEventTypes[arg.EventType] {
+		return database.Outbox{}, ErrCheckViolation
+	}
+	if slices.ContainsFunc(s.outbox, func(event database.Outbox) bool { return event.EventID == arg.EventID }) {
+		return database.Outbox{}, ErrUniqueViolation
+	}
+	s.lastOutboxID++
+	event := database.Outbox{
+		ID:        s.lastOutboxID,
+		EventID:   arg.EventID,
+		TenantID:  arg.TenantID,
+		HumanID:   arg.HumanID,
+		EventType: arg.EventType,
+		Payload:   cloneJSON(arg.Payload),
+		CreatedAt: time.Now().UTC(),
+	}
+	s.outbox = append(s.outbox, event)
+	return event, nil
+}
+
+func (r *MemoryHumanRepository) ListOutboxEventsOfHuman(ctx context.Context, arg database.ListOutboxEventsOfHumanParams) ([]database.Outbox, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.ListOutboxEventsOfHuman(ctx, arg)
+}
+
+func (s *memoryStore) ListOutboxEventsOfHuman(ctx context.Context, arg database.ListOutboxEventsOfHumanParams) ([]database.Outbox, error) {
+	events := make([]database.Outbox, 0)
+	for _, event := range s.outbox {
+		if event.TenantID == arg.TenantID && event.HumanID == arg.HumanID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *MemoryHumanRepository) DeleteOutboxEventsOfHuman(ctx context.Context, arg database.DeleteOutboxEventsOfHumanParams) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.DeleteOutboxEventsOfHuman(ctx, arg)
+}
+
+func (s *memoryStore) DeleteOutboxEventsOfHuman(ctx context.Context, arg database.DeleteOutboxEventsOfHumanParams) (int64, error) {
+	before := len(s.outbox)
+	s.outbox = slices.DeleteFunc(s.outbox, func(event database.Outbox) bool {
+		return event.TenantID == arg.TenantID && event.HumanID == arg.HumanID
+	})
+	return int64(before - len(s.outbox)), nil
+}
+
+func (r *MemoryHumanRepository) NotifyHumanErased(ctx context.Context, arg database.NotifyHumanErasedParams) error {
+	if r.onErased != nil {
+		r.onErased(arg)
+	}
+	return nil
+}
+
+func (s *memoryStore) NotifyHumanErased(ctx context.Context, arg database.NotifyHumanErasedParams) error {
+	s.erasures = append(s.erasures, arg)
+	return nil
+}
+
+// ClaimOutboxEvents has nothing to skip: the memory transaction holding
+// the store is the only one.
+func (s *memoryStore) ClaimOutboxEvents(ctx context.Context, batchSize int32) ([]database.Outbox, error) {
+	events := make([]database.Outbox, 0)
+	for _, event := range s.outbox {
+		if len(events) == int(batchSize) {
+			break
+		}
+		if !event.DeliveredAt.Valid {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (s *memoryStore) MarkOutboxEventDelivered(ctx context.Context, id int64) error {
+	if i := s.outboxIndex(id); i >= 0 {
+		s.outbox[i].Attempts++
+		s.outbox[i].LastError = sql.NullString{}
+		s.outbox[i].DeliveredAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+	}
+	return nil
+}
+
+func (s *memoryStore) RecordOutboxFailure(ctx context.Context, arg database.RecordOutboxFailureParams) error {
+	if i := s.outboxIndex(arg.ID); i >= 0 {
+		s.outbox[i].Attempts++
+		s.outbox[i].LastError = sql.NullString{String: arg.LastError, Valid: true}
+	}
+	return nil
+}
+
+func (s *memoryStore) DeleteDeliveredOutboxEvents(ctx context.Context, deliveredBefore time.Time) (int64, error) {
+	before := len(s.outbox)
+	s.outbox = slices.DeleteFunc(s.outbox, func(event database.Outbox) bool {
+		return event.DeliveredAt.Valid && event.DeliveredAt.Time.Before(deliveredBefore)
+	})
+	return int64(before - len(s.outbox)), nil
+}
+
+func (s *memoryStore) DeleteOutboxEventsCreatedBefore(ctx context.Context, createdBefore time.Time) (int64, error) {
+	before := len(s.outbox)
+	s.outbox = slices.DeleteFunc(s.outbox, func(event database.Outbox) bool {
+		return event.CreatedAt.Before(createdBefore)
+	})
+	return int64(before - len(s.outbox)), nil
+}
+
+func (s *memoryStore) outboxIndex(id int64) int {
+	return slices.IndexFunc(s.outbox, func(event database.Outbox) bool { return event.ID == id })
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+)
+
+func (r *MemoryHumanRepository) CreateRelationship(ctx context.Context, arg database.CreateRelationshipParams) (database.HumanRelationship, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.CreateRelationship(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) DeleteRelationship(ctx context.Context, arg database.DeleteRelationshipParams) (database.HumanRelationship, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.DeleteRelationship(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) ListRelatives(ctx context.Context, arg database.ListRelativesParams) ([]database.ListRelativesRow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.ListRelatives(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) ListAncestorIDs(ctx context.Context, arg database.ListAncestorIDsParams) ([]uuid.UUID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.ListAncestorIDs(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) WalkFamilyTree(ctx context.Context, arg database.WalkFamilyTreeParams) ([]database.WalkFamilyTreeRow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.WalkFamilyTree(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) ListRelationshipsAmong(ctx context.Context, arg database.ListRelationshipsAmongParams) ([]database.HumanRelationship, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.ListRelationshipsAmong(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) ListRelationshipsOfHuman(ctx context.Context, arg database.ListRelationshipsOfHumanParams) ([]database.HumanRelationship, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.ListRelationshipsOfHuman(ctx, arg)
+}
+
+var relations = map[string]bool{"parent": true, "child": true, "spouse": true, "sibling": true}
+
+func (s *memoryStore) CreateRelationship(ctx context.Context, arg database.CreateRelationshipParams) (database.HumanRelationship, error) {
+	if !relations[arg.Relation] || arg.HumanID == arg.RelativeID {
+		return database.HumanRelationship{}, ErrCheckViolation
+	}
+	for _, id := range []uuid.UUID{arg.HumanID, arg.RelativeID} {
+		human, ok := s.humans[id]
+		if !ok || human.TenantID != arg.TenantID {
+			return database.HumanRelationship{}, ErrForeignKeyViolation
+		}
+	}
+	for _, rel := range s.relationships {
+		if rel.TenantID == arg.TenantID && rel.HumanID == arg.HumanID &&
+			rel.RelativeID == arg.RelativeID && rel.Relation == arg.Relation {
+			return database.HumanRelationship{}, ErrUniqueViolation
+		}
+	}
+	rel := database.HumanRelationship{
+		ID:         uuid.New(),
+		TenantID:   arg.TenantID,
+		HumanID:    arg.HumanID,
+		RelativeID: arg.RelativeID,
+		Relation:   arg.Relation,
+		CreatedAt:  time.Now().UTC(),
+	}
+	s.relationships = append(s.relationships, rel)
+	return rel, nil
+}
+
+func (s *memoryStore) DeleteRelationship(ctx context.Context, arg database.DeleteRelationshipParams) (database.HumanRelationship, error) {
+	for i, rel := range s.relationships {
+		if rel.TenantID == arg.TenantID && rel.HumanID == arg.HumanID &&
+			rel.RelativeID == arg.RelativeID && rel.Relation == arg.Relation {
+			s.relationships = append(s.relationships[:i:i], s.relationships[i+1:]...)
+			return rel, nil
+		}
+	}
+	return database.HumanRelationship{}, sql.ErrNoRows
+}
+
+func (s *memoryStore) ListRelatives(ctx context.Context, arg database.ListRelativesParams) ([]database.ListRelativesRow, error) {
+	var rows []database.ListRelativesRow
+	for _, rel := range s.relationships {
+		if rel.TenantID != arg.TenantID || rel.HumanID != arg.HumanID {
+			continue
+		}
+		rows = append(rows, database.ListRelativesRow{
+			HumanRelationship: rel,
+			Human:             s.humans[rel.RelativeID],
+		})
+	}
+	return rows, nil
+}
+
+// ListAncestorIDs follows parent links, and child links read backwards, up
+// from the given human.
+func (s *memoryStore) ListAncestorIDs(ctx context.Context, arg database.ListAncestorIDsParams) ([]uuid.UUID, error) {
+	parents := make(map[uuid.UUID][]uuid.UUID)
+	for _, rel := range s.relationships {
+		if rel.TenantID != arg.TenantID {
+			continue
+		}
+		switch rel.Relation {
+		case "parent":
+			parents[rel.HumanID] = append(parents[rel.HumanID], rel.RelativeID)
+		case "child":
+			parents[rel.RelativeID] = append(parents[rel.RelativeID], rel.HumanID)
+		}
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	var ancestors []uuid.UUID
+	queue := []uuid.UUID{arg.HumanID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, parent := range parents[current] {
+			if seen[parent] {
+				continue
+			}
+			seen[parent] = true
+			ancestors = append(ancestors, parent)
+			queue = append(queue, parent)
+		}
+	}
+	return ancestors, nil
+}
+
+// WalkFamilyTree visits relatives in both directions breadth first, up to
+// MaxDepth links away from the starting human.
+func (s *memoryStore) WalkFamilyTree(ctx context.Context, arg database.WalkFamilyTreeParams) ([]database.WalkFamilyTreeRow, error) {
+	neighbours := make(map[uuid.UUID][]uuid.UUID)
+	for _, rel := range s.relationships {
+		if rel.TenantID != arg.TenantID {
+			continue
+		}
+		neighbours[rel.HumanID] = append(neighbours[rel.HumanID], rel.RelativeID)
+		neighbours[rel.RelativeID] = append(neighbours[rel.RelativeID], rel.HumanID)
+	}
+
+	depths := map[uuid.UUID]int32{arg.HumanID: 0}
+	rows := []database.WalkFamilyTreeRow{{HumanID: arg.HumanID, Depth: 0}}
+	queue := []uuid.UUID{arg.HumanID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if depths[current] >= arg.MaxDepth {
+			continue
+		}
+		for _, next := range neighbours[current] {
+			if _, seen := depths[next]; seen {
+				continue
+			}
+			depths[next] = depths[current] + 1
+			rows = append(rows, database.WalkFamilyTreeRow{HumanID: next, Depth: depths[next]})
+			queue = append(queue, next)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Depth != rows[j].Depth {
+			return rows[i].Depth < rows[j].Depth
+		}
+		return bytes.Compare(rows[i].HumanID[:], rows[j].HumanID[:]) < 0
+	})
+	return rows, nil
+}
+
+func (s *memoryStore) ListRelationshipsAmong(ctx context.Context, arg database.ListRelationshipsAmongParams) ([]database.HumanRelationship, error) {
+	ids := make(map[uuid.UUID]bool, len(arg.Ids))
+	for _, id := range arg.Ids {
+		ids[id] = true
+	}
+	var rels []database.HumanRelationship
+	for _, rel := range s.relationships {
+		if rel.TenantID == arg.TenantID && ids[rel.HumanID] && ids[rel.RelativeID] {
+			rels = append(rels, rel)
+		}
+	}
+	return rels, nil
+}
+
+func (s *memoryStore) ListRelationshipsOfHuman(ctx context.Context, arg database.ListRelationshipsOfHumanParams) ([]database.HumanRelationship, error) {
+	var rels []database.HumanRelationship
+	for _, rel := range s.relationships {
+		if rel.TenantID == arg.TenantID && (rel.HumanID == arg.HumanID || rel.RelativeID == arg.HumanID) {
+			rels = append(rels, rel)
+		}
+	}
+	return rels, nil
+}
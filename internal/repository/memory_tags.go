@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+)
+
+// memoryHumanTag is a row of the human_tags join table.
+type memoryHumanTag struct {
+	TenantID string
+	HumanID  uuid.UUID
+	TagID    uuid.UUID
+}
+
+func (r *MemoryHumanRepository) UpsertTag(ctx context.Context, arg database.UpsertTagParams) (database.Tag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.UpsertTag(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) AttachTag(ctx context.Context, arg database.AttachTagParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.AttachTag(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) DetachTag(ctx context.Context, arg database.DetachTagParams) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.DetachTag(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) ListTagsByHumanIDs(ctx context.Context, arg database.ListTagsByHumanIDsParams) ([]database.ListTagsByHumanIDsRow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.ListTagsByHumanIDs(ctx, arg)
+}
+
+func (s *memoryStore) UpsertTag(ctx context.Context, arg database.UpsertTagParams) (database.Tag, error) {
+	for _, tag := range s.tags {
+		if tag.TenantID == arg.TenantID && tag.Name == arg.Name {
+			return tag, nil
+		}
+	}
+	tag := database.Tag{
+		ID:        uuid.New(),
+		TenantID:  arg.TenantID,
+		Name:      arg.Name,
+		CreatedAt: time.Now().UTC(),
+	}
+	s.tags = append(s.tags, tag)
+	return tag, nil
+}
+
+func (s *memoryStore) AttachTag(ctx context.Context, arg database.AttachTagParams) error {
+	human, ok := s.humans[arg.HumanID]
+	if !ok || human.TenantID != arg.TenantID {
+		return ErrForeignKeyViolation
+	}
+	if !slices.ContainsFunc(s.tags, func(tag database.Tag) bool {
+		return tag.TenantID == arg.TenantID && tag.ID == arg.TagID
+	}) {
+		return ErrForeignKeyViolation
+	}
+	link := memoryHumanTag{TenantID: arg.TenantID, HumanID: arg.HumanID, TagID: arg.TagID}
+	if !slices.Contains(s.humanTags, link) {
+		s.humanTags = append(s.humanTags, link)
+	}
+	return nil
+}
+
+func (s *memoryStore) DetachTag(ctx context.Context, arg database.DetachTagParams) (int64, error) {
+	tagID, ok := s.tagID(arg.TenantID, arg.Name)
+	if !ok {
+		return 0, nil
+	}
+	before := len(s.humanTags)
+	s.humanTags = slices.DeleteFunc(s.humanTags, func(link memoryHumanTag) bool {
+		return link.TenantID == arg.TenantID && link.HumanID == arg.HumanID && link.TagID == tagID
+	})
+	return int64(before - len(s.humanTags)), nil
+}
+
+func (s *memoryStore) ListTagsByHumanIDs(ctx context.Context, arg database.ListTagsByHumanIDsParams) ([]database.ListTagsByHumanIDsRow, error) {
+	names := make(map[uuid.UUID]string, len(s.tags))
+	for _, tag := range s.tags {
+		names[tag.ID] = tag.Name
+	}
+	rows := make([]database.ListTagsByHumanIDsRow, 0)
+	for _, link := range s.humanTags {
+		if link.TenantID == arg.TenantID && slices.Contains(arg.HumanIds, link.HumanID) {
+			rows = append(rows, database.ListTagsByHumanIDsRow{HumanID: link.HumanID, Name: names[link.TagID]})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if c := bytes.Compare(rows[i].HumanID[:], rows[j].HumanID[:]); c != 0 {
+			return c < 0
+		}
+		return rows[i].Name < rows[j].Name
+	})
+	return rows, nil
+}
+
+func (s *memoryStore) tagID(tenantID, name string) (uuid.UUID, bool) {
+	for _, tag := range s.tags {
+		if tag.TenantID == tenantID && tag.Name == name {
+			return tag.ID, true
+		}
+	}
+	return uuid.Nil, false
+}
+
+// hasTag reports whether the tag named name is attached to the human.
+func (s *memoryStore) hasTag(human database.Human, name string) bool {
+	tagID, ok := s.tagID(human.TenantID, name)
+	return ok && slices.Contains(s.humanTags, memoryHumanTag{TenantID: human.TenantID, HumanID: human.ID, TagID: tagID})
+}
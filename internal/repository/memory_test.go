@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+)
+
+func TestMemoryTenantIsolation(t *testing.T) {
+	repo := NewMemoryHumanRepository()
+	testTenantIsolation(t, repo, repo, "a", "b")
+}
+
+func TestMemoryErasureAnnouncedOnCommit(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryHumanRepository()
+	var announced []string
+	repo.SetOutboxListener(func(event database.Outbox) {
+		announced = append(announced, "event "+event.EventType)
+	})
+	repo.SetErasureListener(func(erasure database.NotifyHumanErasedParams) {
+		announced = append(announced, "erasure "+erasure.TenantID)
+	})
+
+	humanID := uuid.New()
+	erase := func(tx Tx) {
+		t.Helper()
+		if err := tx.Humans().NotifyHumanErased(ctx, database.NotifyHumanErasedParams{TenantID: "a", HumanID: humanID}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tx.Humans().CreateOutboxEvent(ctx, database.CreateOutboxEventParams{
+			EventID: uuid.New(), TenantID: "a", HumanID: humanID, EventType: "HumanUpdated", Payload: json.RawMessage("{}"),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tx, err := repo.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	erase(tx)
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if len(announced) != 0 {
+		t.Fatalf("rolled back transaction announced %v", announced)
+	}
+
+	if tx, err = repo.BeginTx(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	erase(tx)
+	if len(announced) != 0 {
+		t.Fatalf("open transaction announced %v", announced)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	// The erasure must come first, or it would drop the event recorded
+	// after it.
+	if want := []string{"erasure a", "event HumanUpdated"}; !slices.Equal(announced, want) {
+		t.Fatalf("announced %v, want %v", announced, want)
+	}
+}
@@ -0,0 +1,404 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+)
+
+func (r *MemoryHumanRepository) CreateWebhookSubscription(ctx context.Context, arg database.CreateWebhookSubscriptionParams) (database.WebhookSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.CreateWebhookSubscription(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) GetWebhookSubscription(ctx context.Context, arg database.GetWebhookSubscriptionParams) (database.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.GetWebhookSubscription(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) ListWebhookSubscriptions(ctx context.Context, tenantID string) ([]database.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.ListWebhookSubscriptions(ctx, tenantID)
+}
+
+func (r *MemoryHumanRepository) UpdateWebhookSubscription(ctx context.Context, arg database.UpdateWebhookSubscriptionParams) (database.WebhookSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.UpdateWebhookSubscription(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) DeleteWebhookSubscription(ctx context.Context, arg database.DeleteWebhookSubscriptionParams) (database.WebhookSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.DeleteWebhookSubscription(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) ListWebhookSubscriptionsForEvent(ctx context.Context, arg database.ListWebhookSubscriptionsForEventParams) ([]database.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.ListWebhookSubscriptionsForEvent(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) CreateWebhookDelivery(ctx context.Context, arg database.CreateWebhookDeliveryParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.CreateWebhookDelivery(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) DeleteWebhookDeliveriesOfHuman(ctx context.Context, arg database.DeleteWebhookDeliveriesOfHumanParams) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.DeleteWebhookDeliveriesOfHuman(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) ListWebhookDeliveriesOfHuman(ctx context.Context, arg database.ListWebhookDeliveriesOfHumanParams) ([]database.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.ListWebhookDeliveriesOfHuman(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) ListWebhookDeliveries(ctx context.Context, arg database.ListWebhookDeliveriesParams) ([]database.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.ListWebhookDeliveries(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) GetWebhookDelivery(ctx context.Context, arg database.GetWebhookDeliveryParams) (database.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.GetWebhookDelivery(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) ListWebhookDeliveryAttempts(ctx context.Context, arg database.ListWebhookDeliveryAttemptsParams) ([]database.WebhookDeliveryAttempt, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.ListWebhookDeliveryAttempts(ctx, arg)
+}
+
+func (r *MemoryHumanRepository) RedeliverWebhookDelivery(ctx context.Context, arg database.RedeliverWebhookDeliveryParams) (database.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.RedeliverWebhookDelivery(ctx, arg)
+}
+
+func (s *memoryStore) CreateWebhookSubscription(ctx context.Context, arg database.CreateWebhookSubscriptionParams) (database.WebhookSubscription, error) {
+	now := time.Now().UTC()
+	sub := database.WebhookSubscription{
+		ID:         uuid.New(),
+		TenantID:   arg.TenantID,
+		Url:        arg.Url,
+		EventTypes: slices.Clone(arg.EventTypes),
+		Secret:     arg.Secret,
+		Enabled:    true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	s.webhooks = append(s.webhooks, sub)
+	return sub, nil
+}
+
+func (s *memoryStore) GetWebhookSubscription(ctx context.Context, arg database.GetWebhookSubscriptionParams) (database.WebhookSubscription, error) {
+	i := s.webhookIndex(arg.TenantID, arg.ID)
+	if i < 0 {
+		return database.WebhookSubscription{}, sql.ErrNoRows
+	}
+	return s.webhooks[i], nil
+}
+
+func (s *memoryStore) ListWebhookSubscriptions(ctx context.Context, tenantID string) ([]database.WebhookSubscription, error) {
+	subs := make([]database.WebhookSubscription, 0)
+	for _, sub := range s.webhooks {
+		if sub.TenantID == tenantID {
+			subs = append(subs, sub)
+		}
+	}
+	return subs, nil
+}
+
+func (s *memoryStore) UpdateWebhookSubscription(ctx context.Context, arg database.UpdateWebhookSubscriptionParams) (database.WebhookSubscription, error) {
+	i := s.webhookIndex(arg.TenantID, arg.ID)
+	if i < 0 {
+		return database.WebhookSubscription{}, sql.ErrNoRows
+	}
+	sub := &s.webhooks[i]
+	now := time.Now().UTC()
+	sub.Url = arg.Url
+	sub.EventTypes = slices.Clone(arg.EventTypes)
+	sub.Secret = arg.Secret
+	sub.Enabled = arg.Enabled
+	if arg.Enabled {
+		sub.ConsecutiveFailures = 0
+		sub.DisabledAt = sql.NullTime{}
+	} else if !sub.DisabledAt.Valid {
+		sub.DisabledAt = sql.NullTime{Time: now, Valid: true}
+	}
+	sub.UpdatedAt = now
+	return *sub, nil
+}
+
+// DeleteWebhookSubscription also deletes the deliveries of the
+// subscription, as the foreign keys cascade.
+func (s *memoryStore) DeleteWebhookSubscription(ctx context.Context, arg database.DeleteWebhookSubscriptionParams) (database.WebhookSubscription, error) {
+	i := s.webhookIndex(arg.TenantID, arg.ID)
+	if i < 0 {
+		return database.WebhookSubscription{}, sql.ErrNoRows
+	}
+	sub := s.webhooks[i]
+	s.webhooks = slices.Delete(s.webhooks, i, i+1)
+	s.deleteDeliveries(func(delivery database.WebhookDelivery) bool { return delivery.SubscriptionID == sub.ID })
+	return sub, nil
+}
+
+func (s *memoryStore) ListWebhookSubscriptionsForEvent(ctx context.Context, arg database.ListWebhookSubscriptionsForEventParams) ([]database.WebhookSubscription, error) {
+	subs := make([]database.WebhookSubscription, 0)
+	for _, sub := range s.webhooks {
+		if sub.TenantID != arg.TenantID || !sub.Enabled {
+			continue
+		}
+		if len(sub.EventTypes) == 0 || slices.Contains(sub.EventTypes, arg.EventType) {
+			subs = append(subs, sub)
+		}
+	}
+	return subs, nil
+}
+
+func (s *memoryStore) CreateWebhookDelivery(ctx context.Context, arg database.CreateWebhookDeliveryParams) error {
+	if s.webhookIndex(arg.TenantID, arg.SubscriptionID) < 0 {
+		return ErrForeignKeyViolation
+	}
+	exists := slices.ContainsFunc(s.deliveries, func(delivery database.WebhookDelivery) bool {
+		return delivery.SubscriptionID == arg.SubscriptionID && delivery.EventID == arg.EventID
+	})
+	if exists {
+		return nil
+	}
+	now := time.Now().UTC()
+	s.deliveries = append(s.deliveries, database.WebhookDelivery{
+		ID:             uuid.New(),
+		TenantID:       arg.TenantID,
+		SubscriptionID: arg.SubscriptionID,
+		EventID:        arg.EventID,
+		HumanID:        arg.HumanID,
+		EventType:      arg.EventType,
+		Payload:        cloneJSON(arg.Payload),
+		Status:         "pending",
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	})
+	return nil
+}
+
+func (s *memoryStore) DeleteWebhookDeliveriesOfHuman(ctx context.Context, arg database.DeleteWebhookDeliveriesOfHumanParams) (int64, error) {
+	deleted := s.deleteDeliveries(func(delivery database.WebhookDelivery) bool {
+		return delivery.TenantID == arg.TenantID && delivery.HumanID == arg.HumanID
+	})
+	return int64(deleted), nil
+}
+
+func (s *memoryStore) ListWebhookDeliveriesOfHuman(ctx context.Context, arg database.ListWebhookDeliveriesOfHumanParams) ([]database.WebhookDelivery, error) {
+	deliveries := make([]database.WebhookDelivery, 0)
+	for _, delivery := range s.deliveries {
+		if delivery.TenantID == arg.TenantID && delivery.HumanID == arg.HumanID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func (s *memoryStore) ListWebhookDeliveries(ctx context.Context, arg database.ListWebhookDeliveriesParams) ([]database.WebhookDelivery, error) {
+	deliveries := make([]database.WebhookDelivery, 0)
+	// Deliveries are appended in creation order.
+	for i := len(s.deliveries) - 1; i >= 0 && len(deliveries) < int(arg.MaxRows); i-- {
+		delivery := s.deliveries[i]
+		if delivery.TenantID != arg.TenantID || delivery.SubscriptionID != arg.SubscriptionID {
+			continue
+		}
+		if arg.Status != "" && delivery.Status != arg.Status {
+			continue
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
+
+func (s *memoryStore) GetWebhookDelivery(ctx context.Context, arg database.GetWebhookDeliveryParams) (database.WebhookDelivery, error) {
+	i := s.deliveryIndex(arg.ID)
+	if i < 0 || s.deliveries[i].TenantID != arg.TenantID || s.deliveries[i].SubscriptionID != arg.SubscriptionID {
+		return database.WebhookDelivery{}, sql.ErrNoRows
+	}
+	return s.deliveries[i], nil
+}
+
+func (s *memoryStore) ListWebhookDeliveryAttempts(ctx context.Context, arg database.ListWebhookDeliveryAttemptsParams) ([]database.WebhookDeliveryAttempt, error) {
+	attempts := make([]database.WebhookDeliveryAttempt, 0)
+	i := s.deliveryIndex(arg.DeliveryID)
+	if i < 0 || s.deliveries[i].TenantID != arg.TenantID {
+		return attempts, nil
+	}
+	for _, attempt := range s.attempts {
+		if attempt.DeliveryID == arg.DeliveryID {
+			attempts = append(attempts, attempt)
+		}
+	}
+	return attempts, nil
+}
+
+func (s *memoryStore) RedeliverWebhookDelivery(ctx context.Context, arg database.RedeliverWebhookDeliveryParams) (database.WebhookDelivery, error) {
+	delivery, err := s.GetWebhookDelivery(ctx, database.GetWebhookDeliveryParams(arg))
+	if err != nil {
+		return database.WebhookDelivery{}, err
+	}
+	now := time.Now().UTC()
+	delivery.Status = "pending"
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = now
+	delivery.UpdatedAt = now
+	s.deliveries[s.deliveryIndex(delivery.ID)] = delivery
+	return delivery, nil
+}
+
+// ClaimWebhookDeliveries has nothing to skip: the memory transaction
+// holding the store is the only one.
+func (s *memoryStore) ClaimWebhookDeliveries(ctx context.Context, arg database.ClaimWebhookDeliveriesParams) ([]database.ClaimWebhookDeliveriesRow, error) {
+	now := time.Now()
+	due := make([]int, 0)
+	for i, delivery := range s.deliveries {
+		if delivery.Status != "pending" || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		if j := s.webhookIndex(delivery.TenantID, delivery.SubscriptionID); j >= 0 && s.webhooks[j].Enabled {
+			due = append(due, i)
+		}
+	}
+	sort.SliceStable(due, func(a, b int) bool {
+		return s.deliveries[due[a]].NextAttemptAt.Before(s.deliveries[due[b]].NextAttemptAt)
+	})
+	if len(due) > int(arg.BatchSize) {
+		due = due[:arg.BatchSize]
+	}
+
+	rows := make([]database.ClaimWebhookDeliveriesRow, len(due))
+	for k, i := range due {
+		delivery := &s.deliveries[i]
+		delivery.NextAttemptAt = arg.LeaseUntil
+		sub := s.webhooks[s.webhookIndex(delivery.TenantID, delivery.SubscriptionID)]
+		rows[k] = database.ClaimWebhookDeliveriesRow{
+			ID:             delivery.ID,
+			TenantID:       delivery.TenantID,
+			SubscriptionID: delivery.SubscriptionID,
+			EventID:        delivery.EventID,
+			EventType:      delivery.EventType,
+			Payload:        cloneJSON(delivery.Payload),
+			Attempts:       delivery.Attempts,
+			Url:            sub.Url,
+			Secret:         sub.Secret,
+		}
+	}
+	return rows, nil
+}
+
+func (s *memoryStore) CreateWebhookDeliveryAttempt(ctx context.Context, arg database.CreateWebhookDeliveryAttemptParams) error {
+	if s.deliveryIndex(arg.DeliveryID) < 0 {
+		return ErrForeignKeyViolation
+	}
+	s.lastAttemptID++
+	s.attempts = append(s.attempts, database.WebhookDeliveryAttempt{
+		ID:             s.lastAttemptID,
+		DeliveryID:     arg.DeliveryID,
+		ResponseStatus: arg.ResponseStatus,
+		Error:          arg.Error,
+		DurationMs:     arg.DurationMs,
+		AttemptedAt:    time.Now().UTC(),
+	})
+	return nil
+}
+
+var webhookDeliveryStatuses = map[string]bool{"pending": true, "succeeded": true, "failed": true}
+
+func (s *memoryStore) UpdateWebhookDeliveryResult(ctx context.Context, arg database.UpdateWebhookDeliveryResultParams) error {
+	if !webhookDeliveryStatuses[arg.Status] {
+		return ErrCheckViolation
+	}
+	i := s.deliveryIndex(arg.ID)
+	if i < 0 {
+		return nil
+	}
+	delivery := &s.deliveries[i]
+	delivery.Status = arg.Status
+	delivery.Attempts++
+	delivery.NextAttemptAt = arg.NextAttemptAt
+	delivery.LastResponseStatus = arg.LastResponseStatus
+	delivery.LastError = arg.LastError
+	delivery.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (s *memoryStore) ResetWebhookSubscriptionFailures(ctx context.Context, id uuid.UUID) error {
+	for i := range s.webhooks {
+		if s.webhooks[i].ID == id {
+			s.webhooks[i].ConsecutiveFailures = 0
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) RecordWebhookSubscriptionFailure(ctx context.Context, arg database.RecordWebhookSubscriptionFailureParams) (database.WebhookSubscription, error) {
+	i := slices.IndexFunc(s.webhooks, func(sub database.WebhookSubscription) bool { return sub.ID == arg.ID })
+	if i < 0 {
+		return database.WebhookSubscription{}, sql.ErrNoRows
+	}
+	sub := &s.webhooks[i]
+	now := time.Now().UTC()
+	sub.ConsecutiveFailures++
+	if sub.Enabled && sub.ConsecutiveFailures >= arg.MaxFailures {
+		sub.Enabled = false
+		sub.DisabledAt = sql.NullTime{Time: now, Valid: true}
+	}
+	sub.UpdatedAt = now
+	return *sub, nil
+}
+
+func (s *memoryStore) DeleteFinishedWebhookDeliveries(ctx context.Context, updatedBefore time.Time) (int64, error) {
+	deleted := s.deleteDeliveries(func(delivery database.WebhookDelivery) bool {
+		return delivery.Status != "pending" && delivery.UpdatedAt.Before(updatedBefore)
+	})
+	return int64(deleted), nil
+}
+
+// deleteDeliveries deletes the matching deliveries with their attempts and
+// returns how many deliveries were deleted.
+func (s *memoryStore) deleteDeliveries(match func(database.WebhookDelivery) bool) int {
+	deleted := make(map[uuid.UUID]bool)
+	s.deliveries = slices.DeleteFunc(s.deliveries, func(delivery database.WebhookDelivery) bool {
+		if match(delivery) {
+			deleted[delivery.ID] = true
+			return true
+		}
+		return false
+	})
+	if len(deleted) > 0 {
+		s.attempts = slices.DeleteFunc(s.attempts, func(attempt database.WebhookDeliveryAttempt) bool {
+			return deleted[attempt.DeliveryID]
+		})
+	}
+	return len(deleted)
+}
+
+func (s *memoryStore) webhookIndex(tenantID string, id uuid.UUID) int {
+	return slices.IndexFunc(s.webhooks, func(sub database.WebhookSubscription) bool {
+		return sub.ID == id && sub.TenantID == tenantID
+	})
+}
+
+func (s *memoryStore) deliveryIndex(id uuid.UUID) int {
+	return slices.IndexFunc(s.deliveries, func(delivery database.WebhookDelivery) bool { return delivery.ID == id })
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/pii"
+)
+
+// RotateKeys moves the humans of every tenant onto the current key. Data
+// keys wrapped by an older key are rewrapped, leaving the ciphertexts as
+// they are; rows still in plaintext are encrypted. Each batch runs in its
+// own short transaction that locks only its rows, so the API keeps serving
+// while keys rotate, and an interrupted rotation resumes where it stopped.
+// It returns the number of rows rotated.
+func RotateKeys(ctx context.Context, db *sql.DB, keys *pii.Keyring, batchSize int, progress io.Writer) (int, error) {
+	if batchSize < 1 {
+		return 0, fmt.Errorf("batch size must be positive")
+	}
+	rotated := 0
+	after := uuid.Nil
+	for {
+		n, last, err := rotateBatch(ctx, db, keys, after, batchSize)
+		rotated += n
+		if err != nil {
+			return rotated, err
+		}
+		if n > 0 {
+			fmt.Fprintf(progress, "rotated %d humans\n", rotated)
+		}
+		if n < batchSize {
+			return rotated, nil
+		}
+		after = last
+	}
+}
+
+func rotateBatch(ctx context.Context, db *sql.DB, keys *pii.Keyring, after uuid.UUID, batchSize int) (int, uuid.UUID, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return 0, after, err
+	}
+	defer tx.Rollback()
+	queries := database.New(tx)
+
+	humans, err := queries.ListHumansForKeyRotation(ctx, database.ListHumansForKeyRotationParams{
+		After:        after,
+		CurrentKeyID: keys.CurrentKeyID(),
+		BatchSize:    int32(batchSize),
+	})
+	if err != nil {
+		return 0, after, err
+	}
+	for _, human := range humans {
+		update, err := rotatedPII(keys, human)
+		if err != nil {
+			return 0, after, fmt.Errorf("human %s: %w", human.ID, err)
+		}
+		if err := queries.UpdateHumanPII(ctx, update); err != nil {
+			return 0, after, fmt.Errorf("human %s: %w", human.ID, err)
+		}
+		after = human.ID
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, after, err
+	}
+	return len(humans), after, nil
+}
+
+func rotatedPII(keys *pii.Keyring, human database.Human) (database.UpdateHumanPIIParams, error) {
+	if human.PiiKeyID.Valid {
+		keyID, wrapped, err := keys.Rewrap(human.TenantID, human.PiiKeyID.String, human.PiiDek)
+		if err != nil {
+			return database.UpdateHumanPIIParams{}, err
+		}
+		return database.UpdateHumanPIIParams{
+			ID:         human.ID,
+			Name:       human.Name,
+			Surname:    human.Surname,
+			Patronymic: human.Patronymic,
+			NameIndex:  human.NameIndex,
+			PiiKeyID:   sql.NullString{String: keyID, Valid: true},
+			PiiDek:     wrapped,
+		}, nil
+	}
+	sealed, err := sealPII(keys, human.TenantID, human.Name, human.Surname, human.Patronymic)
+	if err != nil {
+		return database.UpdateHumanPIIParams{}, err
+	}
+	return database.UpdateHumanPIIParams{
+		ID:         human.ID,
+		Name:       sealed.name,
+		Surname:    sealed.surname,
+		Patronymic: sealed.patronymic,
+		NameIndex:  sealed.nameIndex,
+		PiiKeyID:   sealed.keyID,
+		PiiDek:     sealed.dataKey,
+	}, nil
+}
+
+// RotateEventPayloads moves the stored payloads of outbox events and
+// webhook deliveries onto the current key the way RotateKeys moves humans,
+// sealing the ones written before encryption was enabled. Without it,
+// removing an old key would leave the events not yet published and the
+// delivery logs unreadable. It returns the number of payloads rotated.
+func RotateEventPayloads(ctx context.Context, db *sql.DB, keys *pii.Keyring, batchSize int, progress io.Writer) (int, error) {
+	if batchSize < 1 {
+		return 0, fmt.Errorf("batch size must be positive")
+	}
+	rotated := 0
+	var afterEvent int64
+	for {
+		n, last, err := rotateOutboxBatch(ctx, db, keys, afterEvent, batchSize)
+		rotated += n
+		if err != nil {
+			return rotated, err
+		}
+		if n > 0 {
+			fmt.Fprintf(progress, "rotated %d event payloads\n", rotated)
+		}
+		if n < batchSize {
+			break
+		}
+		afterEvent = last
+	}
+	afterDelivery := uuid.Nil
+	for {
+		n, last, err := rotateDeliveryBatch(ctx, db, keys, afterDelivery, batchSize)
+		rotated += n
+		if err != nil {
+			return rotated, err
+		}
+		if n > 0 {
+			fmt.Fprintf(progress, "rotated %d event payloads\n", rotated)
+		}
+		if n < batchSize {
+			return rotated, nil
+		}
+		afterDelivery = last
+	}
+}
+
+func rotateOutboxBatch(ctx context.Context, db *sql.DB, keys *pii.Keyring, after int64, batchSize int) (int, int64, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return 0, after, err
+	}
+	defer tx.Rollback()
+	queries := database.New(tx)
+
+	events, err := queries.ListOutboxEventsForKeyRotation(ctx, database.ListOutboxEventsForKeyRotationParams{
+		After:        after,
+		CurrentKeyID: keys.CurrentKeyID(),
+		BatchSize:    int32(batchSize),
+	})
+	if err != nil {
+		return 0, after, err
+	}
+	for _, event := range events {
+		payload, err := rotatedEventPayload(keys, event.TenantID, event.Payload)
+		if err != nil {
+			return 0, after, fmt.Errorf("event %s: %w", event.EventID, err)
+		}
+		if err := queries.UpdateOutboxEventPayload(ctx, database.UpdateOutboxEventPayloadParams{ID: event.ID, Payload: payload}); err != nil {
+			return 0, after, fmt.Errorf("event %s: %w", event.EventID, err)
+		}
+		after = event.ID
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, after, err
+	}
+	return len(events), after, nil
+}
+
+func rotateDeliveryBatch(ctx context.Context, db *sql.DB, keys *pii.Keyring, after uuid.UUID, batchSize int) (int, uuid.UUID, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return 0, after, err
+	}
+	defer tx.Rollback()
+	queries := database.New(tx)
+
+	deliveries, err := queries.ListWebhookDeliveriesForKeyRotation(ctx, database.ListWebhookDeliveriesForKeyRotationParams{
+		After:        after,
+		CurrentKeyID: keys.CurrentKeyID(),
+		BatchSize:    int32(batchSize),
+	})
+	if err != nil {
+		return 0, after, err
+	}
+	for _, delivery := range deliveries {
+		payload, err := rotatedEventPayload(keys, delivery.TenantID, delivery.Payload)
+		if err != nil {
+			return 0, after, fmt.Errorf("webhook delivery %s: %w", delivery.ID, err)
+		}
+		if err := queries.UpdateWebhookDeliveryPayload(ctx, database.UpdateWebhookDeliveryPayloadParams{ID: delivery.ID, Payload: payload}); err != nil {
+			return 0, after, fmt.Errorf("webhook delivery %s: %w", delivery.ID, err)
+		}
+		after = delivery.ID
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, after, err
+	}
+	return len(deliveries), after, nil
+}
+
+// IndexPlaintextNames sets the name index of rows written before
+// encryption was enabled. Until a plaintext row has one, the unique name
+// constraint cannot see it, so an encrypted human with the same name could
+// be created next to it; the server therefore runs this before serving
+// requests with keys enabled. A row whose name is already taken by an
+// encrypted human fails with a unique violation and has to be renamed or
+// removed by hand. It returns the number of rows indexed.
+func IndexPlaintextNames(ctx context.Context, db *sql.DB, keys *pii.Keyring, batchSize int) (int, error) {
+	if batchSize < 1 {
+		return 0, fmt.Errorf("batch size must be positive")
+	}
+	indexed := 0
+	after := uuid.Nil
+	for {
+		n, last, err := indexBatch(ctx, db, keys, after, batchSize)
+		indexed += n
+		if err != nil {
+			return indexed, err
+		}
+		if n < batchSize {
+			return indexed, nil
+		}
+		after = last
+	}
+}
+
+func indexBatch(ctx context.Context, db *sql.DB, keys *pii.Keyring, after uuid.UUID, batchSize int) (int, uuid.UUID, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return 0, after, err
+	}
+	defer tx.Rollback()
+	queries := database.New(tx)
+
+	humans, err := queries.ListUnindexedHumans(ctx, database.ListUnindexedHumansParams{
+		After:     after,
+		BatchSize: int32(batchSize),
+	})
+	if err != nil {
+		return 0, after, err
+	}
+	for _, human := range humans {
+		err := queries.SetHumanNameIndex(ctx, database.SetHumanNameIndexParams{
+			ID:        human.ID,
+			NameIndex: keys.BlindIndex(human.TenantID, human.Name),
+		})
+		if err != nil {
+			return 0, after, fmt.Errorf("human %s: %w", human.ID, err)
+		}
+		after = human.ID
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, after, err
+	}
+	return len(humans), after, nil
+}
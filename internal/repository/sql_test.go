@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/migrations"
+	"github.com/lib/pq"
+)
+
+// openTestDB connects to the Postgres at DATABASE_URL and migrates it up.
+// Tests that need it are skipped when the variable is not set.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := migrations.Run(context.Background(), db, "up", io.Discard); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+// testTenants returns tenant ids no earlier run used, and deletes their
+// rows when the test ends.
+func testTenants(t *testing.T, db *sql.DB, names ...string) []string {
+	t.Helper()
+	suffix := uuid.NewString()[:8]
+	tenants := make([]string, len(names))
+	for i, name := range names {
+		tenants[i] = "test-" + name + "-" + suffix
+	}
+	t.Cleanup(func() {
+		// Contacts, relationships, tags of humans, import errors and
+		// webhook deliveries with their attempts go by cascade.
+		for _, table := range []string{"humans", "tags", "attribute_definitions", "import_jobs", "outbox", "webhook_subscriptions"} {
+			if _, err := db.Exec("DELETE FROM "+table+" WHERE tenant_id = ANY($1)", pq.Array(tenants)); err != nil {
+				t.Errorf("failed to clean up %s: %v", table, err)
+			}
+		}
+	})
+	return tenants
+}
+
+// TestSQLTenantIsolation runs the tenant isolation checks against the
+// sqlc queries.
+func TestSQLTenantIsolation(t *testing.T) {
+	db := openTestDB(t)
+	tenants := testTenants(t, db, "a", "b")
+	testTenantIsolation(t, NewSQLHumanRepository(db), NewSQLTransactor(db), tenants[0], tenants[1])
+}
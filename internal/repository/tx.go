@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+)
+
+// Tx is a unit of work. Repositories obtained from it see and make changes
+// that become visible to others only after Commit.
+type Tx interface {
+	Humans() HumanRepository
+	Outbox() OutboxRepository
+	Webhooks() WebhookRepository
+	Imports() ImportRepository
+	Commit() error
+	Rollback() error
+}
+
+// Transactor opens units of work over a storage.
+type Transactor interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error)
+}
+
+type SQLTransactor struct {
+	db *sql.DB
+}
+
+func NewSQLTransactor(db *sql.DB) *SQLTransactor {
+	return &SQLTransactor{db: db}
+}
+
+func (t *SQLTransactor) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	tx, err := t.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlTx{tx: tx, queries: database.New(t.db).WithTx(tx)}, nil
+}
+
+type sqlTx struct {
+	tx      *sql.Tx
+	queries *database.Queries
+}
+
+func (t *sqlTx) Humans() HumanRepository {
+	return t.queries
+}
+
+func (t *sqlTx) Outbox() OutboxRepository {
+	return t.queries
+}
+
+func (t *sqlTx) Webhooks() WebhookRepository {
+	return t.queries
+}
+
+func (t *sqlTx) Imports() ImportRepository {
+	return t.queries
+}
+
+func (t *sqlTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *sqlTx) Rollback() error {
+	return t.tx.Rollback()
+}
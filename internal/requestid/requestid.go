@@ -0,0 +1,39 @@
+// Package requestid carries the id of the HTTP request being served, so
+// that errors returned to a client can be found in the server log.
+package requestid
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// Header is the request header a caller may set its own id in, and the
+// response header the id is returned in.
+const Header = "X-Request-ID"
+
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type contextKey struct{}
+
+// ValidID reports whether an id supplied by a caller can be kept.
+func ValidID(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+// New returns a fresh request id.
+func New() string {
+	return uuid.NewString()
+}
+
+// WithID returns a copy of ctx carrying the request id.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request id stored in ctx by WithID, or "".
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+)
+
+var attributeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+func (humanService *UserService) GetAttributeDefinitions(ctx context.Context) ([]models.AttributeDefinitionResponse, error) {
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defs, err := humanService.ApiConfig.Humans.ListAttributeDefinitions(ctx, tenantID)
+	if err != nil {
+		return nil, InternalError("failed to get attribute definitions", err)
+	}
+	response := make([]models.AttributeDefinitionResponse, len(defs))
+	for i, def := range defs {
+		response[i] = toAttributeDefinitionResponse(def)
+	}
+	return response, nil
+}
+
+// PutAttributeDefinition creates or replaces the definition of an attribute.
+// Stored values are not revalidated; the new rules apply to later writes.
+func (humanService *UserService) PutAttributeDefinition(ctx context.Context, name string, req *models.AttributeDefinitionRequest) (models.AttributeDefinitionResponse, error) {
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.AttributeDefinitionResponse{}, err
+	}
+	params, err := validateAttributeDefinition(tenantID, name, req)
+	if err != nil {
+		return models.AttributeDefinitionResponse{}, err
+	}
+
+	def, err := humanService.ApiConfig.Humans.UpsertAttributeDefinition(ctx, params)
+	if err != nil {
+		return models.AttributeDefinitionResponse{}, storageError("error saving attribute definition", err)
+	}
+	return toAttributeDefinitionResponse(def), nil
+}
+
+// DeleteAttributeDefinition removes the definition together with the
+// attribute's values on every human of the tenant.
+func (humanService *UserService) DeleteAttributeDefinition(ctx context.Context, name string) (models.AttributeDefinitionResponse, error) {
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.AttributeDefinitionResponse{}, err
+	}
+
+	var def database.AttributeDefinition
+	err = humanService.inTx(ctx, TxOptions{Isolation: sql.LevelReadCommitted}, func(tx repository.Tx) error {
+		var err error
+		def, err = tx.Humans().DeleteAttributeDefinition(ctx, database.DeleteAttributeDefinitionParams{TenantID: tenantID, Name: name})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return NotFoundError("attribute definition does not exists")
+			}
+			return InternalError("failed to delete attribute definition", err)
+		}
+		err = tx.Humans().RemoveHumanAttribute(ctx, database.RemoveHumanAttributeParams{TenantID: tenantID, Name: name})
+		if err != nil {
+			return InternalError("failed to remove attribute values", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.AttributeDefinitionResponse{}, err
+	}
+	return toAttributeDefinitionResponse(def), nil
+}
+
+func validateAttributeDefinition(tenantID, name string, req *models.AttributeDefinitionRequest) (database.UpsertAttributeDefinitionParams, error) {
+	if req == nil {
+		return database.UpsertAttributeDefinitionParams{}, ValidationError("bad request", nil)
+	}
+	if !attributeNamePattern.MatchString(name) {
+		return database.UpsertAttributeDefinitionParams{}, ValidationError(fmt.Sprintf("invalid attribute name %q", name), nil)
+	}
+	params := database.UpsertAttributeDefinitionParams{TenantID: tenantID, Name: name, Type: req.Type, Required: req.Required}
+
+	switch req.Type {
+	case "string":
+		if req.Minimum != nil || req.Maximum != nil {
+			return database.UpsertAttributeDefinitionParams{}, ValidationError("minimum and maximum apply to number and integer attributes only", nil)
+		}
+	case "number", "integer":
+		if req.Pattern != nil || req.MaxLength != nil {
+			return database.UpsertAttributeDefinitionParams{}, ValidationError("pattern and max_length apply to string attributes only", nil)
+		}
+	case "boolean", "date":
+		if req.Pattern != nil || req.MaxLength != nil || req.Minimum != nil || req.Maximum != nil {
+			return database.UpsertAttributeDefinitionParams{}, ValidationError(fmt.Sprintf("%s attributes take no constraints besides enum", req.Type), nil)
+		}
+	default:
+		return database.UpsertAttributeDefinitionParams{}, ValidationError(fmt.Sprintf("unknown attribute type %q", req.Type), nil)
+	}
+	if req.Pattern != nil {
+		if _, err := regexp.Compile(*req.Pattern); err != nil {
+			return database.UpsertAttributeDefinitionParams{}, ValidationError("invalid pattern", err)
+		}
+		params.Pattern = sql.NullString{String: *req.Pattern, Valid: true}
+	}
+	if req.MaxLength != nil {
+		if *req.MaxLength < 1 {
+			return database.UpsertAttributeDefinitionParams{}, ValidationError("max_length must be positive", nil)
+		}
+		params.MaxLength = sql.NullInt32{Int32: int32(*req.MaxLength), Valid: true}
+	}
+	if req.Minimum != nil {
+		params.Minimum = sql.NullFloat64{Float64: *req.Minimum, Valid: true}
+	}
+	if req.Maximum != nil {
+		params.Maximum = sql.NullFloat64{Float64: *req.Maximum, Valid: true}
+	}
+	if params.Minimum.Valid && params.Maximum.Valid && params.Minimum.Float64 > params.Maximum.Float64 {
+		return database.UpsertAttributeDefinitionParams{}, ValidationError("minimum must not exceed maximum", nil)
+	}
+
+	enum := req.Enum
+	if enum == nil {
+		enum = []any{}
+	}
+	// Enum values must themselves satisfy the rest of the definition.
+	check := database.AttributeDefinition{
+		Name: name, Type: params.Type, Pattern: params.Pattern, MaxLength: params.MaxLength,
+		Minimum: params.Minimum, Maximum: params.Maximum,
+	}
+	for _, value := range enum {
+		if err := checkAttributeValue(check, value); err != nil {
+			return database.UpsertAttributeDefinitionParams{}, ValidationError(fmt.Sprintf("invalid enum value: %s", err), nil)
+		}
+	}
+	params.EnumValues, _ = json.Marshal(enum)
+	return params, nil
+}
+
+// validateAttributes checks attributes against the definitions and returns
+// them encoded for storage.
+func validateAttributes(defs []database.AttributeDefinition, attributes map[string]any) (json.RawMessage, error) {
+	byName := make(map[string]database.AttributeDefinition, len(defs))
+	for _, def := range defs {
+		byName[def.Name] = def
+		if _, ok := attributes[def.Name]; def.Required && !ok {
+			return nil, ValidationError(fmt.Sprintf("attribute %q is required", def.Name), nil)
+		}
+	}
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		def, ok := byName[name]
+		if !ok {
+			return nil, ValidationError(fmt.Sprintf("unknown attribute %q", name), nil)
+		}
+		if err := checkAttributeValue(def, attributes[name]); err != nil {
+			return nil, ValidationError(err.Error(), nil)
+		}
+	}
+	if attributes == nil {
+		attributes = map[string]any{}
+	}
+	raw, err := json.Marshal(attributes)
+	if err != nil {
+		return nil, ValidationError("bad attributes", err)
+	}
+	return raw, nil
+}
+
+// checkAttributeValue checks a value decoded from JSON against a definition.
+func checkAttributeValue(def database.AttributeDefinition, value any) error {
+	switch def.Type {
+	case "string", "date":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("attribute %q must be a string", def.Name)
+		}
+		if def.Type == "date" {
+			if _, err := time.Parse(time.DateOnly, s); err != nil {
+				return fmt.Errorf("attribute %q must be a date in YYYY-MM-DD format", def.Name)
+			}
+		}
+		if def.MaxLength.Valid && utf8.RuneCountInString(s) > int(def.MaxLength.Int32) {
+			return fmt.Errorf("attribute %q must be at most %d characters", def.Name, def.MaxLength.Int32)
+		}
+		if def.Pattern.Valid {
+			matched, err := regexp.MatchString(def.Pattern.String, s)
+			if err != nil || !matched {
+				return fmt.Errorf("attribute %q must match %s", def.Name, def.Pattern.String)
+			}
+		}
+	case "number", "integer":
+		n, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("attribute %q must be a number", def.Name)
+		}
+		if def.Type == "integer" && n != math.Trunc(n) {
+			return fmt.Errorf("attribute %q must be an integer", def.Name)
+		}
+		if def.Minimum.Valid && n < def.Minimum.Float64 {
+			return fmt.Errorf("attribute %q must be at least %g", def.Name, def.Minimum.Float64)
+		}
+		if def.Maximum.Valid && n > def.Maximum.Float64 {
+			return fmt.Errorf("attribute %q must be at most %g", def.Name, def.Maximum.Float64)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("attribute %q must be a boolean", def.Name)
+		}
+	default:
+		return fmt.Errorf("attribute %q has unknown type %q", def.Name, def.Type)
+	}
+
+	var enum []any
+	if err := json.Unmarshal(def.EnumValues, &enum); err == nil && len(enum) > 0 {
+		for _, allowed := range enum {
+			if reflect.DeepEqual(allowed, value) {
+				return nil
+			}
+		}
+		return fmt.Errorf("attribute %q must be one of %s", def.Name, def.EnumValues)
+	}
+	return nil
+}
+
+// attributeFilter converts the raw query values to the attributes' types
+// and encodes them as a jsonb containment pattern.
+func attributeFilter(defs []database.AttributeDefinition, raw map[string]string) (json.RawMessage, error) {
+	byName := make(map[string]database.AttributeDefinition, len(defs))
+	for _, def := range defs {
+		byName[def.Name] = def
+	}
+	pattern := make(map[string]any, len(raw))
+	for name, value := range raw {
+		def, ok := byName[name]
+		if !ok {
+			return nil, ValidationError(fmt.Sprintf("unknown attribute %q", name), nil)
+		}
+		var err error
+		switch def.Type {
+		case "number", "integer":
+			pattern[name], err = strconv.ParseFloat(value, 64)
+		case "boolean":
+			pattern[name], err = strconv.ParseBool(value)
+		default:
+			pattern[name] = value
+		}
+		if err != nil {
+			return nil, ValidationError(fmt.Sprintf("bad value for attribute %q", name), err)
+		}
+	}
+	encoded, err := json.Marshal(pattern)
+	if err != nil {
+		return nil, ValidationError("bad attribute filter", err)
+	}
+	return encoded, nil
+}
+
+func decodeAttributes(raw json.RawMessage) map[string]any {
+	attributes := map[string]any{}
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &attributes)
+	}
+	return attributes
+}
+
+func toAttributeDefinitionResponse(def database.AttributeDefinition) models.AttributeDefinitionResponse {
+	response := models.AttributeDefinitionResponse{
+		Name:      def.Name,
+		Type:      def.Type,
+		Required:  def.Required,
+		Enum:      []any{},
+		CreatedAt: def.CreatedAt.UTC(),
+		UpdatedAt: def.UpdatedAt.UTC(),
+	}
+	_ = json.Unmarshal(def.EnumValues, &response.Enum)
+	if def.Pattern.Valid {
+		response.Pattern = &def.Pattern.String
+	}
+	if def.MaxLength.Valid {
+		maxLength := int(def.MaxLength.Int32)
+		response.MaxLength = &maxLength
+	}
+	if def.Minimum.Valid {
+		response.Minimum = &def.Minimum.Float64
+	}
+	if def.Maximum.Valid {
+		response.Maximum = &def.Maximum.Float64
+	}
+	return response
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+)
+
+const (
+	BatchModeAtomic     = "atomic"
+	BatchModeBestEffort = "best_effort"
+
+	MaxBatchOperations = 1000
+)
+
+// BatchResult is the outcome of one batch operation. Err is nil when the
+// operation was applied.
+type BatchResult struct {
+	Op    string
+	Human *models.HumanResponse
+	Err   error
+}
+
+// batchOp is a validated batch operation.
+type batchOp struct {
+	op     string
+	id     uuid.UUID
+	req    *models.HumanRequest
+	params models.ExtraParamsResponse
+}
+
+// BatchHumans applies up to MaxBatchOperations creates, updates and deletes
+// in order. The names being written are enriched together before anything
+// is stored. In atomic mode the first failure rolls back the whole batch
+// and the other operations report as aborted; in best-effort mode every
+// operation runs in its own transaction. An empty mode is set to atomic.
+// The error is only set when the batch as a whole is rejected.
+func (humanService *UserService) BatchHumans(ctx context.Context, req *models.BatchRequest) ([]BatchResult, error) {
+	if req == nil {
+		return nil, ValidationError("bad request", nil)
+	}
+	switch req.Mode {
+	case "":
+		req.Mode = BatchModeAtomic
+	case BatchModeAtomic, BatchModeBestEffort:
+	default:
+		return nil, ValidationError(fmt.Sprintf("unknown batch mode %q", req.Mode), nil)
+	}
+	if len(req.Operations) == 0 || len(req.Operations) > MaxBatchOperations {
+		return nil, ValidationError(fmt.Sprintf("batch must have between 1 and %d operations", MaxBatchOperations), nil)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(req.Operations))
+	ops := make([]batchOp, len(req.Operations))
+	var names []string
+	for i, operation := range req.Operations {
+		results[i].Op = operation.Op
+		ops[i], results[i].Err = parseBatchOperation(operation)
+		if results[i].Err == nil && ops[i].req != nil {
+			names = append(names, ops[i].req.Name)
+		}
+	}
+	enriched := getParamsFromAPIBatch(ctx, names)
+	for i := range ops {
+		if results[i].Err != nil || ops[i].req == nil {
+			continue
+		}
+		result := enriched[ops[i].req.Name]
+		ops[i].params, results[i].Err = result.params, result.err
+	}
+
+	if req.Mode == BatchModeBestEffort {
+		for i, op := range ops {
+			if results[i].Err != nil {
+				continue
+			}
+			var human models.HumanResponse
+			err := humanService.inTx(ctx, TxOptions{Isolation: sql.LevelReadCommitted}, func(tx repository.Tx) error {
+				var err error
+				human, err = applyBatchOp(ctx, tx.Humans(), tenantID, op)
+				return err
+			})
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+			results[i].Human = &human
+		}
+		return results, nil
+	}
+
+	failed := -1
+	for i := range results {
+		if results[i].Err != nil {
+			failed = i
+			break
+		}
+	}
+	humans := make([]models.HumanResponse, len(ops))
+	if failed < 0 {
+		var opErr error
+		err = humanService.inTx(ctx, TxOptions{Isolation: sql.LevelReadCommitted}, func(tx repository.Tx) error {
+			failed, opErr = -1, nil
+			for i, op := range ops {
+				human, err := applyBatchOp(ctx, tx.Humans(), tenantID, op)
+				if err != nil {
+					failed, opErr = i, err
+					return err
+				}
+				humans[i] = human
+			}
+			return nil
+		})
+		if err != nil && failed < 0 {
+			return nil, err
+		}
+		if failed >= 0 {
+			results[failed].Err = opErr
+		}
+	}
+	for i := range results {
+		switch {
+		case failed < 0:
+			results[i].Human = &humans[i]
+		case i != failed:
+			results[i].Err = AbortedError(fmt.Sprintf("not applied, operation %d failed", failed))
+		}
+	}
+	return results, nil
+}
+
+func parseBatchOperation(operation models.BatchOperation) (batchOp, error) {
+	op := batchOp{op: operation.Op}
+	switch operation.Op {
+	case "create":
+	case "update", "delete":
+		id, err := uuid.Parse(operation.ID)
+		if err != nil {
+			return batchOp{}, ValidationError("bad uuid", err)
+		}
+		op.id = id
+	default:
+		return batchOp{}, ValidationError(fmt.Sprintf("unknown batch operation %q", operation.Op), nil)
+	}
+	if operation.Op != "delete" {
+		if operation.Human == nil {
+			return batchOp{}, ValidationError("human is required", nil)
+		}
+		if err := validateHumanRequest(operation.Human); err != nil {
+			return batchOp{}, err
+		}
+		op.req = operation.Human
+	}
+	return op, nil
+}
+
+func applyBatchOp(ctx context.Context, humans repository.HumanRepository, tenantID string, op batchOp) (models.HumanResponse, error) {
+	switch op.op {
+	case "create":
+		return createHuman(ctx, humans, tenantID, op.req, op.params)
+	case "update":
+		return updateHuman(ctx, humans, tenantID, op.id, op.req, op.params)
+	case "delete":
+		return deleteHuman(ctx, humans, tenantID, op.id)
+	}
+	return models.HumanResponse{}, InternalError("unknown batch operation", errors.New(op.op))
+}
@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+)
+
+var (
+	e164Pattern        = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+	countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	phoneSeparators    = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+)
+
+const maxAddressFieldLength = 200
+
+// contactFields is a validated and normalised contact.
+type contactFields struct {
+	Type       string
+	Value      string
+	Primary    bool
+	Street     sql.NullString
+	City       sql.NullString
+	Region     sql.NullString
+	PostalCode sql.NullString
+	Country    sql.NullString
+}
+
+func (humanService *UserService) CreateContact(ctx context.Context, humanID string, req *models.ContactRequest) (models.ContactResponse, error) {
+	uid, err := uuid.Parse(humanID)
+	if err != nil {
+		return models.ContactResponse{}, ValidationError("bad uuid", err)
+	}
+	fields, err := validateContact(req)
+	if err != nil {
+		return models.ContactResponse{}, err
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.ContactResponse{}, err
+	}
+
+	var contact database.HumanContact
+	err = humanService.inTx(ctx, TxOptions{Isolation: sql.LevelReadCommitted}, func(tx repository.Tx) error {
+		if err := humanExists(ctx, tx.Humans(), tenantID, uid); err != nil {
+			return err
+		}
+		if fields.Primary {
+			err := tx.Humans().ClearPrimaryContact(ctx, database.ClearPrimaryContactParams{
+				TenantID: tenantID, HumanID: uid, Type: fields.Type, ID: uuid.Nil,
+			})
+			if err != nil {
+				return InternalError("failed to update primary contact", err)
+			}
+		}
+		var err error
+		contact, err = tx.Humans().CreateContact(ctx, database.CreateContactParams{
+			TenantID:   tenantID,
+			HumanID:    uid,
+			Type:       fields.Type,
+			Value:      fields.Value,
+			IsPrimary:  fields.Primary,
+			Street:     fields.Street,
+			City:       fields.City,
+			Region:     fields.Region,
+			PostalCode: fields.PostalCode,
+			Country:    fields.Country,
+		})
+		if err != nil {
+			return storageError("error saving contact", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.ContactResponse{}, err
+	}
+	return toContactResponse(contact), nil
+}
+
+func (humanService *UserService) GetContact(ctx context.Context, humanID, contactID string) (models.ContactResponse, error) {
+	uid, cid, err := parseContactIDs(humanID, contactID)
+	if err != nil {
+		return models.ContactResponse{}, err
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.ContactResponse{}, err
+	}
+
+	contact, err := humanService.ApiConfig.Humans.GetContact(ctx, database.GetContactParams{TenantID: tenantID, HumanID: uid, ID: cid})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ContactResponse{}, NotFoundError("contact does not exists")
+		}
+		return models.ContactResponse{}, InternalError("failed to get contact", err)
+	}
+	return toContactResponse(contact), nil
+}
+
+func (humanService *UserService) GetContacts(ctx context.Context, humanID string) ([]models.ContactResponse, error) {
+	uid, err := uuid.Parse(humanID)
+	if err != nil {
+		return nil, ValidationError("bad uuid", err)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := humanExists(ctx, humanService.ApiConfig.Humans, tenantID, uid); err != nil {
+		return nil, err
+	}
+
+	contacts, err := humanService.ApiConfig.Humans.ListContacts(ctx, database.ListContactsParams{TenantID: tenantID, HumanID: uid})
+	if err != nil {
+		return nil, InternalError("failed to get contacts", err)
+	}
+	response := make([]models.ContactResponse, len(contacts))
+	for i, contact := range contacts {
+		response[i] = toContactResponse(contact)
+	}
+	return response, nil
+}
+
+func (humanService *UserService) UpdateContact(ctx context.Context, humanID, contactID string, req *models.ContactRequest) (models.ContactResponse, error) {
+	uid, cid, err := parseContactIDs(humanID, contactID)
+	if err != nil {
+		return models.ContactResponse{}, err
+	}
+	fields, err := validateContact(req)
+	if err != nil {
+		return models.ContactResponse{}, err
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.ContactResponse{}, err
+	}
+
+	var contact database.HumanContact
+	err = humanService.inTx(ctx, TxOptions{Isolation: sql.LevelReadCommitted}, func(tx repository.Tx) error {
+		_, err := tx.Humans().GetContact(ctx, database.GetContactParams{TenantID: tenantID, HumanID: uid, ID: cid})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return NotFoundError("contact does not exists")
+			}
+			return InternalError("failed to get contact", err)
+		}
+		if fields.Primary {
+			err := tx.Humans().ClearPrimaryContact(ctx, database.ClearPrimaryContactParams{
+				TenantID: tenantID, HumanID: uid, Type: fields.Type, ID: cid,
+			})
+			if err != nil {
+				return InternalError("failed to update primary contact", err)
+			}
+		}
+		contact, err = tx.Humans().UpdateContact(ctx, database.UpdateContactParams{
+			TenantID:   tenantID,
+			HumanID:    uid,
+			ID:         cid,
+			Type:       fields.Type,
+			Value:      fields.Value,
+			IsPrimary:  fields.Primary,
+			Street:     fields.Street,
+			City:       fields.City,
+			Region:     fields.Region,
+			PostalCode: fields.PostalCode,
+			Country:    fields.Country,
+		})
+		if err != nil {
+			return storageError("error updating contact", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.ContactResponse{}, err
+	}
+	return toContactResponse(contact), nil
+}
+
+func (humanService *UserService) DeleteContact(ctx context.Context, humanID, contactID string) (models.ContactResponse, error) {
+	uid, cid, err := parseContactIDs(humanID, contactID)
+	if err != nil {
+		return models.ContactResponse{}, err
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.ContactResponse{}, err
+	}
+
+	contact, err := humanService.ApiConfig.Humans.DeleteContact(ctx, database.DeleteContactParams{TenantID: tenantID, HumanID: uid, ID: cid})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ContactResponse{}, NotFoundError("contact does not exists")
+		}
+		return models.ContactResponse{}, InternalError("failed to delete contact", err)
+	}
+	return toContactResponse(contact), nil
+}
+
+// expandHumans fills in the tags of humans and embeds the requested related
+// resources, loading each kind with a single query.
+func (humanService *UserService) expandHumans(ctx context.Context, tenantID string, humans []models.HumanResponse, expand models.HumanExpand) error {
+	if err := loadTags(ctx, humanService.ApiConfig.Humans, tenantID, humans); err != nil {
+		return err
+	}
+	if !expand.Contacts {
+		return nil
+	}
+	return loadContacts(ctx, humanService.ApiConfig.Humans, tenantID, humans)
+}
+
+// loadContacts embeds the contacts of humans.
+func loadContacts(ctx context.Context, repo repository.HumanRepository, tenantID string, humans []models.HumanResponse) error {
+	if len(humans) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(humans))
+	for i, human := range humans {
+		ids[i] = uuid.MustParse(human.ID)
+	}
+	contacts, err := repo.ListContactsByHumanIDs(ctx, database.ListContactsByHumanIDsParams{TenantID: tenantID, HumanIds: ids})
+	if err != nil {
+		return InternalError("failed to get contacts", err)
+	}
+	byHuman := make(map[string][]models.ContactResponse)
+	for _, contact := range contacts {
+		humanID := contact.HumanID.String()
+		byHuman[humanID] = append(byHuman[humanID], toContactResponse(contact))
+	}
+	for i := range humans {
+		humans[i].Contacts = byHuman[humans[i].ID]
+		if humans[i].Contacts == nil {
+			humans[i].Contacts = []models.ContactResponse{}
+		}
+	}
+	return nil
+}
+
+func parseContactIDs(humanID, contactID string) (uuid.UUID, uuid.UUID, error) {
+	uid, err := uuid.Parse(humanID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, ValidationError("bad uuid", err)
+	}
+	cid, err := uuid.Parse(contactID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, ValidationError("bad contact uuid", err)
+	}
+	return uid, cid, nil
+}
+
+func validateContact(req *models.ContactRequest) (contactFields, error) {
+	if req == nil {
+		return contactFields{}, ValidationError("bad request", nil)
+	}
+	fields := contactFields{Type: req.Type, Primary: req.Primary}
+
+	switch req.Type {
+	case "email":
+		value := strings.TrimSpace(req.Value)
+		parsed, err := mail.ParseAddress(value)
+		if err != nil || parsed.Address != value {
+			return contactFields{}, ValidationError(fmt.Sprintf("invalid email %q", req.Value), nil)
+		}
+		fields.Value = strings.ToLower(value)
+	case "phone":
+		value := phoneSeparators.Replace(strings.TrimSpace(req.Value))
+		if !e164Pattern.MatchString(value) {
+			return contactFields{}, ValidationError(fmt.Sprintf("invalid phone %q, expected E.164 like +79991234567", req.Value), nil)
+		}
+		fields.Value = value
+	case "address":
+		if req.Address == nil {
+			return contactFields{}, ValidationError("address is required for address contacts", nil)
+		}
+		address := models.Address{
+			Street:     strings.TrimSpace(req.Address.Street),
+			City:       strings.TrimSpace(req.Address.City),
+			Region:     strings.TrimSpace(req.Address.Region),
+			PostalCode: strings.TrimSpace(req.Address.PostalCode),
+			Country:    strings.ToUpper(strings.TrimSpace(req.Address.Country)),
+		}
+		if address.Street == "" || address.City == "" {
+			return contactFields{}, ValidationError("address street and city are required", nil)
+		}
+		if !countryCodePattern.MatchString(address.Country) {
+			return contactFields{}, ValidationError("address country must be an ISO 3166-1 alpha-2 code", nil)
+		}
+		for _, part := range []string{address.Street, address.City, address.Region, address.PostalCode} {
+			if len(part) > maxAddressFieldLength {
+				return contactFields{}, ValidationError(fmt.Sprintf("address fields must be at most %d characters", maxAddressFieldLength), nil)
+			}
+		}
+		fields.Street = sql.NullString{String: address.Street, Valid: true}
+		fields.City = sql.NullString{String: address.City, Valid: true}
+		fields.Region = sql.NullString{String: address.Region, Valid: address.Region != ""}
+		fields.PostalCode = sql.NullString{String: address.PostalCode, Valid: address.PostalCode != ""}
+		fields.Country = sql.NullString{String: address.Country, Valid: true}
+		fields.Value = formatAddress(address)
+	default:
+		return contactFields{}, ValidationError(fmt.Sprintf("unknown contact type %q", req.Type), nil)
+	}
+	return fields, nil
+}
+
+func formatAddress(address models.Address) string {
+	parts := []string{address.Street, address.City}
+	if address.Region != "" {
+		parts = append(parts, address.Region)
+	}
+	if address.PostalCode != "" {
+		parts = append(parts, address.PostalCode)
+	}
+	return strings.Join(append(parts, address.Country), ", ")
+}
+
+func toContactResponse(contact database.HumanContact) models.ContactResponse {
+	response := models.ContactResponse{
+		ID:        contact.ID.String(),
+		HumanID:   contact.HumanID.String(),
+		Type:      contact.Type,
+		Value:     contact.Value,
+		Primary:   contact.IsPrimary,
+		CreatedAt: contact.CreatedAt.UTC(),
+		UpdatedAt: contact.UpdatedAt.UTC(),
+	}
+	if contact.Type == "address" {
+		response.Address = &models.Address{
+			Street:     contact.Street.String,
+			City:       contact.City.String,
+			Region:     contact.Region.String,
+			PostalCode: contact.PostalCode.String,
+			Country:    contact.Country.String,
+		}
+	}
+	return response
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+)
+
+const (
+	DefaultDuplicateMinScore = 0.6
+	DefaultDuplicateLimit    = 50
+	maxDuplicateLimit        = 500
+
+	// maxDuplicatePairs bounds the pairs scored per request. Pairs come
+	// most similar names first, so the cut drops the weakest candidates.
+	maxDuplicatePairs = 2000
+
+	// closeAgeYears is how far apart enriched ages may be and still count
+	// as agreeing; they are estimates from the name alone.
+	closeAgeYears = 5
+)
+
+// Weights of the duplicate signals. Name similarity dominates, a differing
+// patronymic counts against the pair since it names a different father.
+const (
+	nameSimilarityWeight = 0.6
+	patronymicWeight     = 0.2
+	genderWeight         = 0.05
+	countryWeight        = 0.05
+	ageWeight            = 0.1
+	patronymicMismatch   = 0.3
+)
+
+// mergeFields are the fields a merge picks a winner for.
+var mergeFields = []string{"name", "surname", "patronymic", "age", "gender", "country", "attributes"}
+
+// FindDuplicates returns pairs of humans that are likely the same person,
+// highest score first.
+func (humanService *UserService) FindDuplicates(ctx context.Context, minScore float64, limit int) ([]models.DuplicateCandidate, error) {
+	if minScore < 0 || minScore > 1 {
+		return nil, ValidationError("min_score must be between 0 and 1", nil)
+	}
+	if limit < 1 || limit > maxDuplicateLimit {
+		return nil, ValidationError(fmt.Sprintf("limit must be between 1 and %d", maxDuplicateLimit), nil)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		pairs  []database.ListDuplicateCandidatesRow
+		humans map[uuid.UUID]database.Human
+	)
+	opts := TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err = humanService.inTx(ctx, opts, func(tx repository.Tx) error {
+		var err error
+		pairs, err = tx.Humans().ListDuplicateCandidates(ctx, database.ListDuplicateCandidatesParams{
+			TenantID: tenantID, MaxPairs: maxDuplicatePairs,
+		})
+		if errors.Is(err, repository.ErrTooManyToCompare) {
+			return ConflictError("too many humans to search for duplicates while names are encrypted", err)
+		}
+		if err != nil {
+			return InternalError("failed to find duplicate candidates", err)
+		}
+		ids := make([]uuid.UUID, 0, 2*len(pairs))
+		for _, pair := range pairs {
+			ids = append(ids, pair.HumanID, pair.CandidateID)
+		}
+		rows, err := tx.Humans().ListHumansByIDs(ctx, database.ListHumansByIDsParams{TenantID: tenantID, Ids: ids})
+		if err != nil {
+			return InternalError("failed to get duplicate candidates", err)
+		}
+		humans = make(map[uuid.UUID]database.Human, len(rows))
+		for _, human := range rows {
+			humans[human.ID] = human
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]models.DuplicateCandidate, 0)
+	for _, pair := range pairs {
+		human, candidate := humans[pair.HumanID], humans[pair.CandidateID]
+		score, reasons := scoreDuplicate(human, candidate, pair.NameSimilarity)
+		if score < minScore {
+			continue
+		}
+		candidates = append(candidates, models.DuplicateCandidate{
+			Score:          score,
+			NameSimilarity: round2(pair.NameSimilarity),
+			Reasons:        reasons,
+			Human:          toHumanResponse(human),
+			Candidate:      toHumanResponse(candidate),
+		})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	pairHumans := make([]models.HumanResponse, 0, 2*len(candidates))
+	for _, candidate := range candidates {
+		pairHumans = append(pairHumans, candidate.Human, candidate.Candidate)
+	}
+	if err := loadTags(ctx, humanService.ApiConfig.Humans, tenantID, pairHumans); err != nil {
+		return nil, err
+	}
+	for i := range candidates {
+		candidates[i].Human, candidates[i].Candidate = pairHumans[2*i], pairHumans[2*i+1]
+	}
+	return candidates, nil
+}
+
+// scoreDuplicate combines the name similarity with the patronymic and the
+// enriched attributes into a score between 0 and 1.
+func scoreDuplicate(a, b database.Human, nameSimilarity float64) (float64, []string) {
+	score := nameSimilarityWeight * nameSimilarity
+	reasons := []string{"similar_name"}
+
+	if a.Patronymic.String != "" && b.Patronymic.String != "" {
+		if strings.EqualFold(a.Patronymic.String, b.Patronymic.String) {
+			score += patronymicWeight
+			reasons = append(reasons, "same_patronymic")
+		} else {
+			score -= patronymicMismatch
+			reasons = append(reasons, "different_patronymic")
+		}
+	}
+	if a.Gender != "" && a.Gender == b.Gender {
+		score += genderWeight
+		reasons = append(reasons, "same_gender")
+	}
+	if a.Country != "" && a.Country == b.Country {
+		score += countryWeight
+		reasons = append(reasons, "same_country")
+	}
+	if a.Age > 0 && b.Age > 0 && math.Abs(float64(a.Age-b.Age)) <= closeAgeYears {
+		score += ageWeight
+		reasons = append(reasons, "close_age")
+	}
+	return round2(min(max(score, 0), 1)), reasons
+}
+
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+// MergeHumans merges the source human into the target: the target keeps
+// the winning value of every field, takes over the source's contacts, tags
+// and relationships, and the source is deleted.
+func (humanService *UserService) MergeHumans(ctx context.Context, targetID string, req *models.MergeRequest) (models.HumanResponse, error) {
+	if req == nil {
+		return models.HumanResponse{}, ValidationError("bad request", nil)
+	}
+	tid, err := uuid.Parse(targetID)
+	if err != nil {
+		return models.HumanResponse{}, ValidationError("bad uuid", err)
+	}
+	sid, err := uuid.Parse(req.SourceID)
+	if err != nil {
+		return models.HumanResponse{}, ValidationError("bad source uuid", err)
+	}
+	if tid == sid {
+		return models.HumanResponse{}, ValidationError("human cannot be merged into themselves", nil)
+	}
+	for field, winner := range req.Prefer {
+		if !slices.Contains(mergeFields, field) {
+			return models.HumanResponse{}, ValidationError(fmt.Sprintf("unknown merge field %q", field), nil)
+		}
+		if winner != "source" && winner != "target" {
+			return models.HumanResponse{}, ValidationError(fmt.Sprintf("merge winner of %q must be source or target", field), nil)
+		}
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.HumanResponse{}, err
+	}
+
+	var merged models.HumanResponse
+	// Serializable, like relationship changes, so that moving links cannot
+	// race with a concurrent insert into an ancestry cycle.
+	err = humanService.inTx(ctx, TxOptions{Isolation: sql.LevelSerializable}, func(tx repository.Tx) error {
+		var err error
+		merged, err = mergeHumans(ctx, tx.Humans(), tenantID, tid, sid, req.Prefer)
+		return err
+	})
+	if err != nil {
+		return models.HumanResponse{}, err
+	}
+	return merged, nil
+}
+
+func mergeHumans(ctx context.Context, humans repository.HumanRepository, tenantID string, targetID, sourceID uuid.UUID, prefer map[string]string) (models.HumanResponse, error) {
+	target, err := getHuman(ctx, humans, tenantID, targetID)
+	if err != nil {
+		return models.HumanResponse{}, err
+	}
+	source, err := getHuman(ctx, humans, tenantID, sourceID)
+	if err != nil {
+		return models.HumanResponse{}, err
+	}
+	update, err := mergeFieldValues(target, source, prefer)
+	if err != nil {
+		return models.HumanResponse{}, err
+	}
+
+	if err := humans.MoveContacts(ctx, database.MoveContactsParams{TenantID: tenantID, SourceID: sourceID, TargetID: targetID}); err != nil {
+		return models.HumanResponse{}, storageError("error moving contacts", err)
+	}
+	if err := humans.CopyHumanTags(ctx, database.CopyHumanTagsParams{TenantID: tenantID, SourceID: sourceID, TargetID: targetID}); err != nil {
+		return models.HumanResponse{}, storageError("error moving tags", err)
+	}
+	if err := humans.MoveRelationships(ctx, database.MoveRelationshipsParams{TenantID: tenantID, SourceID: sourceID, TargetID: targetID}); err != nil {
+		return models.HumanResponse{}, storageError("error moving relationships", err)
+	}
+	// Moved parent and child links may close a cycle through the target,
+	// and any new cycle has to pass through it.
+	ancestors, err := humans.ListAncestorIDs(ctx, database.ListAncestorIDsParams{TenantID: tenantID, HumanID: targetID})
+	if err != nil {
+		return models.HumanResponse{}, InternalError("failed to check ancestry", err)
+	}
+	if slices.Contains(ancestors, targetID) {
+		return models.HumanResponse{}, ConflictError("merge would create an ancestry cycle", nil)
+	}
+
+	// The source goes first, together with the rows that were not moved,
+	// so the target can take over its unique name.
+	deleted := []models.HumanResponse{toHumanResponse(source)}
+	if err := loadTags(ctx, humans, tenantID, deleted); err != nil {
+		return models.HumanResponse{}, err
+	}
+	if _, err := humans.DeleteHuman(ctx, database.DeleteHumanParams{TenantID: tenantID, ID: sourceID}); err != nil {
+		return models.HumanResponse{}, storageError("failed to delete merged human", err)
+	}
+	if err := recordHumanEvent(ctx, humans, tenantID, models.EventHumanDeleted, deleted[0]); err != nil {
+		return models.HumanResponse{}, err
+	}
+	human, err := humans.UpdateHuman(ctx, update)
+	if err != nil {
+		return models.HumanResponse{}, storageError("error updating human", err)
+	}
+	response := []models.HumanResponse{toHumanResponse(human)}
+	if err := loadTags(ctx, humans, tenantID, response); err != nil {
+		return models.HumanResponse{}, err
+	}
+	if err := recordHumanEvent(ctx, humans, tenantID, models.EventHumanUpdated, response[0]); err != nil {
+		return models.HumanResponse{}, err
+	}
+	return response[0], nil
+}
+
+// mergeFieldValues picks the winning value of every field. The target wins
+// unless prefer names the source, and an empty patronymic never wins over
+// a set one. Attributes are combined, the winner's values replacing the
+// loser's.
+func mergeFieldValues(target, source database.Human, prefer map[string]string) (database.UpdateHumanParams, error) {
+	update := database.UpdateHumanParams{
+		TenantID:   target.TenantID,
+		ID:         target.ID,
+		Name:       target.Name,
+		Surname:    target.Surname,
+		Patronymic: target.Patronymic,
+		Age:        target.Age,
+		Gender:     target.Gender,
+		Country:    target.Country,
+	}
+	fromSource := func(field string) bool { return prefer[field] == "source" }
+	if fromSource("name") {
+		update.Name = source.Name
+	}
+	if fromSource("surname") {
+		update.Surname = source.Surname
+	}
+	if (fromSource("patronymic") || !target.Patronymic.Valid) && source.Patronymic.Valid {
+		update.Patronymic = source.Patronymic
+	}
+	if fromSource("age") {
+		update.Age = source.Age
+	}
+	if fromSource("gender") {
+		update.Gender = source.Gender
+	}
+	if fromSource("country") {
+		update.Country = source.Country
+	}
+
+	winner, loser := decodeAttributes(target.Attributes), decodeAttributes(source.Attributes)
+	if fromSource("attributes") {
+		winner, loser = loser, winner
+	}
+	for name, value := range loser {
+		if _, ok := winner[name]; !ok {
+			winner[name] = value
+		}
+	}
+	attributes, err := json.Marshal(winner)
+	if err != nil {
+		return database.UpdateHumanParams{}, InternalError("failed to encode merged attributes", err)
+	}
+	update.Attributes = attributes
+	return update, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+)
+
+const (
+	// enrichmentBatchSize is the most names agify, genderize and nationalize
+	// accept in one request.
+	enrichmentBatchSize = 10
+	// enrichmentWorkers bounds the chunks enriched concurrently.
+	enrichmentWorkers = 4
+)
+
+// enrichment is the result of enriching one name.
+type enrichment struct {
+	params models.ExtraParamsResponse
+	err    error
+}
+
+// getParamsFromAPIBatch enriches every distinct name, asking each upstream
+// API for up to enrichmentBatchSize names at a time. A failed chunk only
+// fails the names it contained.
+func getParamsFromAPIBatch(ctx context.Context, names []string) map[string]enrichment {
+	results := make(map[string]enrichment, len(names))
+	var distinct []string
+	for _, name := range names {
+		if _, ok := results[name]; ok {
+			continue
+		}
+		results[name] = enrichment{}
+		distinct = append(distinct, name)
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		sema = make(chan struct{}, enrichmentWorkers)
+	)
+	for start := 0; start < len(distinct); start += enrichmentBatchSize {
+		chunk := distinct[start:min(start+enrichmentBatchSize, len(distinct))]
+		wg.Add(1)
+		sema <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sema }()
+			params, err := fetchParamsChunk(ctx, chunk)
+			mu.Lock()
+			defer mu.Unlock()
+			for i, name := range chunk {
+				if err != nil {
+					results[name] = enrichment{err: err}
+					continue
+				}
+				results[name] = enrichment{params: params[i]}
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// fetchParamsChunk enriches names with one request per upstream API. The
+// APIs answer in the order the names were given.
+func fetchParamsChunk(ctx context.Context, names []string) ([]models.ExtraParamsResponse, error) {
+	var (
+		ages      []models.AgeResponse
+		genders   []models.GenderResponse
+		countries []models.CountryResponse
+	)
+	if err := fetchBatch(ctx, "https://api.agify.io/", names, &ages); err != nil {
+		return nil, UpstreamUnavailableError("failed to get human age", err)
+	}
+	if err := fetchBatch(ctx, "https://api.genderize.io/", names, &genders); err != nil {
+		return nil, UpstreamUnavailableError("failed to get human gender", err)
+	}
+	if err := fetchBatch(ctx, "https://api.nationalize.io/", names, &countries); err != nil {
+		return nil, UpstreamUnavailableError("failed to get human country", err)
+	}
+	if len(ages) != len(names) || len(genders) != len(names) || len(countries) != len(names) {
+		return nil, UpstreamUnavailableError("unexpected number of enrichment results", nil)
+	}
+
+	params := make([]models.ExtraParamsResponse, len(names))
+	for i := range names {
+		var best struct {
+			Name        string
+			Probability float64
+		}
+		for _, c := range countries[i].Country {
+			if c.Probability > best.Probability {
+				best.Name = c.CountryID
+				best.Probability = c.Probability
+			}
+		}
+		params[i] = models.ExtraParamsResponse{Age: ages[i].Age, Gender: genders[i].Gender, Country: best.Name}
+	}
+	return params, nil
+}
+
+func fetchBatch(ctx context.Context, endpoint string, names []string, out any) error {
+	query := url.Values{"name[]": names}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
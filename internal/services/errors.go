@@ -0,0 +1,172 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+	"github.com/lib/pq"
+)
+
+// ErrorKind classifies a service error independently of any transport.
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindNotFound
+	KindValidation
+	KindConflict
+	KindUpstreamUnavailable
+	KindAborted
+	// KindInvalidFields is a well-formed request with invalid field values.
+	KindInvalidFields
+	// KindBusy is a request refused because the service is at capacity.
+	KindBusy
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindNotFound:
+		return "not_found"
+	case KindValidation:
+		return "validation"
+	case KindConflict:
+		return "conflict"
+	case KindUpstreamUnavailable:
+		return "upstream_unavailable"
+	case KindAborted:
+		return "aborted"
+	case KindInvalidFields:
+		return "invalid_fields"
+	case KindBusy:
+		return "busy"
+	default:
+		return "internal"
+	}
+}
+
+// Error is the domain error returned by the service layer. Transports map
+// its Kind to their own status codes.
+type Error struct {
+	Kind    ErrorKind
+	Message string
+	Err     error
+	// Fields lists the rejected fields of a KindInvalidFields error.
+	Fields []models.FieldError
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s", e.publicMessage(), e.Err)
+	}
+	return e.publicMessage()
+}
+
+func (e *Error) publicMessage() string {
+	if len(e.Fields) > 0 {
+		messages := make([]string, len(e.Fields))
+		for i, field := range e.Fields {
+			messages[i] = field.Message
+		}
+		return fmt.Sprintf("%s: %s", e.Message, strings.Join(messages, "; "))
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func NotFoundError(message string) error {
+	return &Error{Kind: KindNotFound, Message: message}
+}
+
+func ValidationError(message string, err error) error {
+	return &Error{Kind: KindValidation, Message: message, Err: err}
+}
+
+func InvalidFieldsError(message string, fields []models.FieldError) error {
+	return &Error{Kind: KindInvalidFields, Message: message, Fields: fields}
+}
+
+func ConflictError(message string, err error) error {
+	return &Error{Kind: KindConflict, Message: message, Err: err}
+}
+
+func UpstreamUnavailableError(message string, err error) error {
+	return &Error{Kind: KindUpstreamUnavailable, Message: message, Err: err}
+}
+
+// AbortedError reports work that was not applied because another part of
+// the same unit of work failed.
+func AbortedError(message string) error {
+	return &Error{Kind: KindAborted, Message: message}
+}
+
+// BusyError reports work refused until running work finishes.
+func BusyError(message string) error {
+	return &Error{Kind: KindBusy, Message: message}
+}
+
+func InternalError(message string, err error) error {
+	return &Error{Kind: KindInternal, Message: message, Err: err}
+}
+
+// KindOf reports the kind of err. Errors that did not originate in the
+// service layer are treated as internal.
+func KindOf(err error) ErrorKind {
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return serviceErr.Kind
+	}
+	return KindInternal
+}
+
+// PublicMessage describes err for clients. The cause wrapped in a service
+// error is left out, since it may expose storage details, and errors from
+// outside the service layer are not described at all.
+func PublicMessage(err error) string {
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return serviceErr.publicMessage()
+	}
+	return "internal error"
+}
+
+// FieldErrorsOf returns the rejected fields of a KindInvalidFields error.
+func FieldErrorsOf(err error) []models.FieldError {
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return serviceErr.Fields
+	}
+	return nil
+}
+
+const (
+	pqForeignKeyViolation = "23503"
+	pqUniqueViolation     = "23505"
+	pqCheckViolation      = "23514"
+)
+
+// storageError wraps a database error, recognising unique constraint
+// violations as conflicts and broken references or checks as invalid input.
+func storageError(message string, err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pqUniqueViolation:
+			return ConflictError(message, err)
+		case pqForeignKeyViolation, pqCheckViolation:
+			return ValidationError(message, err)
+		}
+	}
+	switch {
+	case errors.Is(err, repository.ErrUniqueViolation):
+		return ConflictError(message, err)
+	case errors.Is(err, repository.ErrForeignKeyViolation), errors.Is(err, repository.ErrCheckViolation):
+		return ValidationError(message, err)
+	}
+	return InternalError(message, err)
+}
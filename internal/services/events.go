@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/feed"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+)
+
+// recordHumanEvent writes an event into the outbox and schedules its
+// delivery to the tenant's webhooks. It must run in the transaction of the
+// change it describes, so that the event is published if and only if the
+// change commits.
+func recordHumanEvent(ctx context.Context, humans repository.HumanRepository, tenantID, eventType string, human models.HumanResponse) error {
+	humanID, err := uuid.Parse(human.ID)
+	if err != nil {
+		return InternalError("bad human id in event", err)
+	}
+	eventID := uuid.New()
+	event := models.HumanEvent{
+		ID:         eventID.String(),
+		Type:       eventType,
+		TenantID:   tenantID,
+		OccurredAt: time.Now().UTC(),
+		Human:      human,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return InternalError("failed to encode event", err)
+	}
+	_, err = humans.CreateOutboxEvent(ctx, database.CreateOutboxEventParams{
+		EventID:   eventID,
+		TenantID:  tenantID,
+		HumanID:   humanID,
+		EventType: eventType,
+		Payload:   payload,
+	})
+	if err != nil {
+		return storageError("failed to record event", err)
+	}
+
+	subs, err := humans.ListWebhookSubscriptionsForEvent(ctx, database.ListWebhookSubscriptionsForEventParams{
+		TenantID:  tenantID,
+		EventType: eventType,
+	})
+	if err != nil {
+		return InternalError("failed to get webhooks", err)
+	}
+	for _, sub := range subs {
+		err := humans.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+			TenantID:       tenantID,
+			SubscriptionID: sub.ID,
+			EventID:        eventID,
+			HumanID:        humanID,
+			EventType:      eventType,
+			Payload:        payload,
+		})
+		if err != nil {
+			return storageError("failed to schedule webhook delivery", err)
+		}
+	}
+	return nil
+}
+
+// SubscribeHumanEvents subscribes to the committed events of the tenant's
+// humans that pass the list filter, judged by the state the event carries.
+// With lastEventID set, the buffered events after it are replayed first.
+func (humanService *UserService) SubscribeHumanEvents(ctx context.Context, filter models.HumanFilter, lastEventID string) (*feed.Subscription, error) {
+	if humanService.ApiConfig.Events == nil {
+		return nil, InternalError("event stream is not configured", nil)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var lastID int64
+	resume := lastEventID != ""
+	if resume {
+		if lastID, err = strconv.ParseInt(lastEventID, 10, 64); err != nil {
+			return nil, ValidationError("bad Last-Event-ID", err)
+		}
+	}
+	params, err := humanService.listHumansParams(ctx, tenantID, filter)
+	if err != nil {
+		return nil, err
+	}
+	var attributes map[string]any
+	if err := json.Unmarshal(params.Attributes, &attributes); err != nil {
+		return nil, InternalError("bad attribute filter", err)
+	}
+
+	match := func(e feed.Event) bool {
+		if e.TenantID != tenantID {
+			return false
+		}
+		var event models.HumanEvent
+		if err := json.Unmarshal(e.Payload, &event); err != nil {
+			return false
+		}
+		return humanMatchesFilter(event.Human, params, attributes)
+	}
+	return humanService.ApiConfig.Events.Subscribe(lastID, resume, match), nil
+}
+
+// humanMatchesFilter mirrors the conditions of the ListHumans query.
+func humanMatchesFilter(human models.HumanResponse, params database.ListHumansParams, attributes map[string]any) bool {
+	if params.CreatedSince.Valid && human.CreatedAt.Before(params.CreatedSince.Time) {
+		return false
+	}
+	if params.CreatedUntil.Valid && !human.CreatedAt.Before(params.CreatedUntil.Time) {
+		return false
+	}
+	if params.UpdatedSince.Valid && human.UpdatedAt.Before(params.UpdatedSince.Time) {
+		return false
+	}
+	if params.UpdatedUntil.Valid && !human.UpdatedAt.Before(params.UpdatedUntil.Time) {
+		return false
+	}
+	hasTag := func(tag string) bool { return slices.Contains(human.Tags, tag) }
+	if !repository.TagsMatch(hasTag, params.Tags, params.MatchAllTags) {
+		return false
+	}
+	for name, value := range attributes {
+		if actual, ok := human.Attributes[name]; !ok || !reflect.DeepEqual(actual, value) {
+			return false
+		}
+	}
+	return true
+}
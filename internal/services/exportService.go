@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+)
+
+// ExportColumns are the columns an export may select, in their default
+// order.
+var ExportColumns = []string{
+	"id", "name", "surname", "patronymic", "age", "gender", "country", "created_at", "updated_at", "attributes",
+}
+
+// ParseExportColumns reads a comma separated column selection. An empty
+// selection means every column.
+func ParseExportColumns(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return ExportColumns, nil
+	}
+	var columns []string
+	for _, column := range strings.Split(raw, ",") {
+		column = strings.TrimSpace(column)
+		if !slices.Contains(ExportColumns, column) {
+			return nil, ValidationError(fmt.Sprintf("unknown export column %q", column), nil)
+		}
+		if slices.Contains(columns, column) {
+			return nil, ValidationError(fmt.Sprintf("export column %q is selected twice", column), nil)
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// ExportHumans streams the humans matching filter to fn, one row of values
+// per human in the order of columns. Rows are read from a database cursor
+// in a single snapshot, so fn sees a consistent table however long the
+// export takes.
+func (humanService *UserService) ExportHumans(ctx context.Context, filter models.HumanFilter, columns []string, fn func(values []any) error) error {
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return err
+	}
+	params, err := humanService.listHumansParams(ctx, tenantID, filter)
+	if err != nil {
+		return err
+	}
+
+	values := make([]any, len(columns))
+	opts := TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	// runTx rather than inTx: rows already handed to fn cannot be taken back
+	// by a retry.
+	return humanService.runTx(ctx, opts, func(tx repository.Tx) error {
+		var writeErr error
+		err := tx.Humans().StreamHumans(ctx, params, func(human database.Human) error {
+			for i, column := range columns {
+				values[i] = exportValue(human, column)
+			}
+			writeErr = fn(values)
+			return writeErr
+		})
+		if writeErr != nil {
+			return writeErr
+		}
+		if err != nil {
+			return InternalError("failed to export humans", err)
+		}
+		return nil
+	})
+}
+
+func exportValue(human database.Human, column string) any {
+	switch column {
+	case "id":
+		return human.ID.String()
+	case "name":
+		return human.Name
+	case "surname":
+		return human.Surname
+	case "patronymic":
+		return human.Patronymic.String
+	case "age":
+		return int(human.Age)
+	case "gender":
+		return human.Gender
+	case "country":
+		return human.Country
+	case "created_at":
+		return human.CreatedAt.UTC()
+	case "updated_at":
+		return human.UpdatedAt.UTC()
+	case "attributes":
+		return decodeAttributes(human.Attributes)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+)
+
+// enrichmentSources are the services the age, gender and country of a
+// human are inferred from.
+var enrichmentSources = []string{"https://api.agify.io", "https://api.genderize.io", "https://api.nationalize.io"}
+
+// ExportSubjectData bundles everything stored about the human: the record
+// with its contacts, tags and attributes, the relationships in both
+// directions, the inferred demographics and the change events and webhook
+// deliveries still kept, with their payloads.
+func (humanService *UserService) ExportSubjectData(ctx context.Context, id string) (models.SubjectDataExport, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return models.SubjectDataExport{}, ValidationError("bad uuid", err)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.SubjectDataExport{}, err
+	}
+
+	var export models.SubjectDataExport
+	opts := TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err = humanService.inTx(ctx, opts, func(tx repository.Tx) error {
+		human, err := getHuman(ctx, tx.Humans(), tenantID, uid)
+		if err != nil {
+			return err
+		}
+		response := []models.HumanResponse{toHumanResponse(human)}
+		if err := loadTags(ctx, tx.Humans(), tenantID, response); err != nil {
+			return err
+		}
+		if err := loadContacts(ctx, tx.Humans(), tenantID, response); err != nil {
+			return err
+		}
+		rels, err := tx.Humans().ListRelationshipsOfHuman(ctx, database.ListRelationshipsOfHumanParams{TenantID: tenantID, HumanID: uid})
+		if err != nil {
+			return InternalError("failed to get relationships", err)
+		}
+		events, err := tx.Humans().ListOutboxEventsOfHuman(ctx, database.ListOutboxEventsOfHumanParams{TenantID: tenantID, HumanID: uid})
+		if err != nil {
+			return InternalError("failed to get events", err)
+		}
+		deliveries, err := tx.Humans().ListWebhookDeliveriesOfHuman(ctx, database.ListWebhookDeliveriesOfHumanParams{TenantID: tenantID, HumanID: uid})
+		if err != nil {
+			return InternalError("failed to get webhook deliveries", err)
+		}
+
+		export = models.SubjectDataExport{
+			ExportedAt:    time.Now().UTC(),
+			Human:         response[0],
+			Relationships: make([]models.RelationshipResponse, len(rels)),
+			Enrichment: models.EnrichmentData{
+				Age:     int(human.Age),
+				Gender:  human.Gender,
+				Country: human.Country,
+				Sources: enrichmentSources,
+			},
+			Events:            make([]models.SubjectEvent, len(events)),
+			WebhookDeliveries: make([]models.WebhookDeliveryResponse, len(deliveries)),
+		}
+		for i, rel := range rels {
+			export.Relationships[i] = toRelationshipResponse(rel)
+		}
+		for i, event := range events {
+			export.Events[i] = toSubjectEvent(event)
+		}
+		for i, delivery := range deliveries {
+			export.WebhookDeliveries[i] = toWebhookDeliveryResponse(delivery, nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.SubjectDataExport{}, err
+	}
+	return export, nil
+}
+
+func toSubjectEvent(event database.Outbox) models.SubjectEvent {
+	response := models.SubjectEvent{
+		ID:        event.EventID.String(),
+		Type:      event.EventType,
+		CreatedAt: event.CreatedAt.UTC(),
+		Payload:   event.Payload,
+	}
+	if event.DeliveredAt.Valid {
+		deliveredAt := event.DeliveredAt.Time.UTC()
+		response.DeliveredAt = &deliveredAt
+	}
+	return response
+}
+
+// AnonymizeHuman replaces the name, surname and patronymic with random
+// tokens and erases the contacts and custom attributes, along with the
+// earlier change events and webhook deliveries of the human, delivered or
+// not, and the events the live feeds buffer for replay. Age, gender and
+// country are kept, so the statistics do not change. The tokens are not
+// derived from the original values and cannot be reversed.
+//
+// Events that already left the service cannot be erased: messages stored
+// in the JetStream stream stay until NATS_MAX_AGE expires them, and what
+// webhook receivers, the HTTP sink and live subscribers got is theirs.
+func (humanService *UserService) AnonymizeHuman(ctx context.Context, id string) (models.HumanResponse, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return models.HumanResponse{}, ValidationError("bad uuid", err)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.HumanResponse{}, err
+	}
+
+	var anonymized models.HumanResponse
+	err = humanService.inTx(ctx, TxOptions{Isolation: sql.LevelReadCommitted}, func(tx repository.Tx) error {
+		human, err := getHuman(ctx, tx.Humans(), tenantID, uid)
+		if err != nil {
+			return err
+		}
+		params := database.UpdateHumanParams{
+			TenantID:   tenantID,
+			ID:         uid,
+			Age:        human.Age,
+			Gender:     human.Gender,
+			Country:    human.Country,
+			Attributes: json.RawMessage("{}"),
+		}
+		if params.Name, err = anonymousToken(); err != nil {
+			return err
+		}
+		if params.Surname, err = anonymousToken(); err != nil {
+			return err
+		}
+		if human.Patronymic.Valid {
+			token, err := anonymousToken()
+			if err != nil {
+				return err
+			}
+			params.Patronymic = sql.NullString{String: token, Valid: true}
+		}
+
+		err = tx.Humans().DeleteContactsOfHuman(ctx, database.DeleteContactsOfHumanParams{TenantID: tenantID, HumanID: uid})
+		if err != nil {
+			return InternalError("failed to delete contacts", err)
+		}
+		// Earlier events carry the original names. They go before the
+		// event of the anonymization is recorded below.
+		_, err = tx.Humans().DeleteWebhookDeliveriesOfHuman(ctx, database.DeleteWebhookDeliveriesOfHumanParams{TenantID: tenantID, HumanID: uid})
+		if err != nil {
+			return InternalError("failed to delete webhook deliveries", err)
+		}
+		_, err = tx.Humans().DeleteOutboxEventsOfHuman(ctx, database.DeleteOutboxEventsOfHumanParams{TenantID: tenantID, HumanID: uid})
+		if err != nil {
+			return InternalError("failed to delete events", err)
+		}
+		err = tx.Humans().NotifyHumanErased(ctx, database.NotifyHumanErasedParams{TenantID: tenantID, HumanID: uid})
+		if err != nil {
+			return InternalError("failed to erase live events", err)
+		}
+		human, err = tx.Humans().UpdateHuman(ctx, params)
+		if err != nil {
+			return storageError("error anonymizing human", err)
+		}
+		response := []models.HumanResponse{toHumanResponse(human)}
+		if err := loadTags(ctx, tx.Humans(), tenantID, response); err != nil {
+			return err
+		}
+		anonymized = response[0]
+		return recordHumanEvent(ctx, tx.Humans(), tenantID, models.EventHumanUpdated, anonymized)
+	})
+	if err != nil {
+		return models.HumanResponse{}, err
+	}
+	return anonymized, nil
+}
+
+// anonymousToken returns a random value that is unique enough to satisfy
+// the unique name constraint.
+func anonymousToken() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", InternalError("failed to generate anonymous token", err)
+	}
+	return "anon-" + hex.EncodeToString(b), nil
+}
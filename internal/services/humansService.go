@@ -6,169 +6,260 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/kiriksik/TestTaskEffectiveMobile/config"
 	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
 	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
 )
 
 type UserService struct {
 	ApiConfig *config.ApiConfig
 }
 
-func (humanService *UserService) CreateHuman(ctx context.Context, req *models.HumanRequest) (models.HumanResponse, int, error) {
+func (humanService *UserService) CreateHuman(ctx context.Context, req *models.HumanRequest) (models.HumanResponse, error) {
 	if req == nil {
-		return models.HumanResponse{}, http.StatusBadRequest, fmt.Errorf("bad request")
+		return models.HumanResponse{}, ValidationError("bad request", nil)
 	}
-
-	patronymicValid := true
-	if req.Patronymic == "" {
-		patronymicValid = false
+	if err := validateHumanRequest(req); err != nil {
+		return models.HumanResponse{}, err
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.HumanResponse{}, err
 	}
 
-	params, status, err := GetParamsFromAPI(req.Name)
+	params, err := GetParamsFromAPI(ctx, req.Name)
 	if err != nil {
-		return models.HumanResponse{}, status, err
+		return models.HumanResponse{}, err
 	}
 
-	human, err := humanService.ApiConfig.Queries.CreateHuman(ctx,
-		database.CreateHumanParams{
-			Name:       req.Name,
-			Surname:    req.Surname,
-			Patronymic: sql.NullString{String: req.Patronymic, Valid: patronymicValid},
-			Age:        int32(params.Age),
-			Gender:     params.Gender,
-			Country:    params.Country,
-		})
+	var human models.HumanResponse
+	err = humanService.inTx(ctx, TxOptions{Isolation: sql.LevelReadCommitted}, func(tx repository.Tx) error {
+		var err error
+		human, err = createHuman(ctx, tx.Humans(), tenantID, req, params)
+		return err
+	})
 	if err != nil {
-		return models.HumanResponse{}, http.StatusInternalServerError, fmt.Errorf("error saving human: %s", err)
+		return models.HumanResponse{}, err
 	}
-	fmt.Println("saved human:", human)
-	return models.HumanResponse{
-		ID:         human.ID.String(),
-		Name:       human.Name,
-		Surname:    human.Surname,
-		Patronymic: &human.Patronymic.String,
-		Age:        int(human.Age),
-		Country:    human.Country,
-		Gender:     human.Gender,
-	}, http.StatusCreated, nil
+	return human, nil
 }
 
-func (humanService *UserService) GetHumanByID(ctx context.Context, id string) (models.HumanResponse, int, error) {
+func (humanService *UserService) GetHumanByID(ctx context.Context, id string, expand models.HumanExpand) (models.HumanResponse, error) {
 	uid, err := uuid.Parse(id)
 	if err != nil {
-		return models.HumanResponse{}, http.StatusBadRequest, fmt.Errorf("bad uuid: %s", err)
+		return models.HumanResponse{}, ValidationError("bad uuid", err)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.HumanResponse{}, err
 	}
 
-	human, err := humanService.ApiConfig.Queries.GetHumanByID(ctx, uid)
+	human, err := humanService.ApiConfig.Humans.GetHumanByID(ctx, database.GetHumanByIDParams{TenantID: tenantID, ID: uid})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return models.HumanResponse{}, http.StatusNotFound, fmt.Errorf("human does not exists")
+			return models.HumanResponse{}, NotFoundError("human does not exists")
 		}
-		return models.HumanResponse{}, http.StatusInternalServerError, fmt.Errorf("failed to get human: %s", err)
+		return models.HumanResponse{}, InternalError("failed to get human", err)
 	}
-	fmt.Println("request for get human:", human)
-	return models.HumanResponse{
-		ID:         human.ID.String(),
-		Name:       human.Name,
-		Surname:    human.Surname,
-		Patronymic: &human.Patronymic.String,
-		Age:        int(human.Age),
-		Country:    human.Country,
-		Gender:     human.Gender,
-	}, http.StatusOK, nil
+	response := []models.HumanResponse{toHumanResponse(human)}
+	if err := humanService.expandHumans(ctx, tenantID, response, expand); err != nil {
+		return models.HumanResponse{}, err
+	}
+	return response[0], nil
 }
 
-func (humanService *UserService) GetHumans(ctx context.Context) ([]models.HumanResponse, int, error) {
+func (humanService *UserService) GetHumans(ctx context.Context, filter models.HumanFilter, expand models.HumanExpand) ([]models.HumanResponse, error) {
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return []models.HumanResponse{}, err
+	}
+	params, err := humanService.listHumansParams(ctx, tenantID, filter)
+	if err != nil {
+		return []models.HumanResponse{}, err
+	}
 
-	humans, err := humanService.ApiConfig.Queries.GetHumans(ctx)
+	humans, err := humanService.ApiConfig.Humans.ListHumans(ctx, params)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return []models.HumanResponse{}, http.StatusNotFound, fmt.Errorf("humans does not exists")
+			return []models.HumanResponse{}, NotFoundError("humans does not exists")
 		}
-		return []models.HumanResponse{}, http.StatusInternalServerError, fmt.Errorf("failed to get humans: %s", err)
+		return []models.HumanResponse{}, InternalError("failed to get humans", err)
 	}
-	fmt.Println("request for get humans")
 
 	responseHumans := make([]models.HumanResponse, len(humans))
 	for i, human := range humans {
-		responseHumans[i] = models.HumanResponse{
-			ID:         human.ID.String(),
-			Name:       human.Name,
-			Surname:    human.Surname,
-			Patronymic: &human.Patronymic.String,
-			Age:        int(human.Age),
-			Country:    human.Country,
-			Gender:     human.Gender,
-		}
+		responseHumans[i] = toHumanResponse(human)
+	}
+	if err := humanService.expandHumans(ctx, tenantID, responseHumans, expand); err != nil {
+		return []models.HumanResponse{}, err
 	}
 
-	return responseHumans, http.StatusOK, nil
+	return responseHumans, nil
 }
 
-func (humanService *UserService) DeleteHuman(ctx context.Context, id string) (models.HumanResponse, int, error) {
+func (humanService *UserService) DeleteHuman(ctx context.Context, id string) (models.HumanResponse, error) {
 	uid, err := uuid.Parse(id)
 	if err != nil {
-		return models.HumanResponse{}, http.StatusBadRequest, fmt.Errorf("bad uuid: %s", err)
+		return models.HumanResponse{}, ValidationError("bad uuid", err)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.HumanResponse{}, err
 	}
 
-	human, err := humanService.ApiConfig.Queries.DeleteHuman(ctx, uid)
+	var human models.HumanResponse
+	err = humanService.inTx(ctx, TxOptions{Isolation: sql.LevelReadCommitted}, func(tx repository.Tx) error {
+		var err error
+		human, err = deleteHuman(ctx, tx.Humans(), tenantID, uid)
+		return err
+	})
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return models.HumanResponse{}, http.StatusNotFound, fmt.Errorf("human does not exists")
-		}
-		return models.HumanResponse{}, http.StatusInternalServerError, fmt.Errorf("failed to delete human: %s", err)
+		return models.HumanResponse{}, err
 	}
-	fmt.Println("deleted human:", human)
-	return models.HumanResponse{
-		ID:         human.ID.String(),
-		Name:       human.Name,
-		Surname:    human.Surname,
-		Patronymic: &human.Patronymic.String,
-		Age:        int(human.Age),
-		Country:    human.Country,
-		Gender:     human.Gender,
-	}, http.StatusOK, nil
+	return human, nil
 }
 
-func (humanService *UserService) UpdateHuman(ctx context.Context, req *models.HumanRequest, id string) (models.HumanResponse, int, error) {
+func (humanService *UserService) UpdateHuman(ctx context.Context, req *models.HumanRequest, id string) (models.HumanResponse, error) {
 	uid, err := uuid.Parse(id)
 	if err != nil {
-		return models.HumanResponse{}, http.StatusBadRequest, fmt.Errorf("bad uuid: %s", err)
+		return models.HumanResponse{}, ValidationError("bad uuid", err)
 	}
 
 	if req == nil {
-		return models.HumanResponse{}, http.StatusBadRequest, fmt.Errorf("bad request")
+		return models.HumanResponse{}, ValidationError("bad request", nil)
+	}
+	if err := validateHumanRequest(req); err != nil {
+		return models.HumanResponse{}, err
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.HumanResponse{}, err
+	}
+
+	params, err := GetParamsFromAPI(ctx, req.Name)
+	if err != nil {
+		return models.HumanResponse{}, err
 	}
 
-	patronymicValid := true
-	if req.Patronymic == "" {
-		patronymicValid = false
+	var human models.HumanResponse
+	err = humanService.inTx(ctx, TxOptions{Isolation: sql.LevelReadCommitted}, func(tx repository.Tx) error {
+		var err error
+		human, err = updateHuman(ctx, tx.Humans(), tenantID, uid, req, params)
+		return err
+	})
+	if err != nil {
+		return models.HumanResponse{}, err
 	}
+	return human, nil
+}
 
-	params, status, err := GetParamsFromAPI(req.Name)
+// createHuman stores an enriched human. It is the write half of
+// CreateHuman, shared with batches, and expects to run in a transaction.
+func createHuman(ctx context.Context, humans repository.HumanRepository, tenantID string, req *models.HumanRequest, params models.ExtraParamsResponse) (models.HumanResponse, error) {
+	defs, err := humans.ListAttributeDefinitions(ctx, tenantID)
+	if err != nil {
+		return models.HumanResponse{}, InternalError("failed to get attribute definitions", err)
+	}
+	attributes, err := validateAttributes(defs, req.Attributes)
+	if err != nil {
+		return models.HumanResponse{}, err
+	}
+	human, err := humans.CreateHuman(ctx,
+		database.CreateHumanParams{
+			TenantID:   tenantID,
+			Name:       req.Name,
+			Surname:    req.Surname,
+			Patronymic: sql.NullString{String: req.Patronymic, Valid: req.Patronymic != ""},
+			Age:        int32(params.Age),
+			Gender:     params.Gender,
+			Country:    params.Country,
+			Attributes: attributes,
+		})
 	if err != nil {
-		return models.HumanResponse{}, status, err
+		return models.HumanResponse{}, storageError("error saving human", err)
+	}
+	response := toHumanResponse(human)
+	if err := recordHumanEvent(ctx, humans, tenantID, models.EventHumanCreated, response); err != nil {
+		return models.HumanResponse{}, err
 	}
+	return response, nil
+}
 
-	human, err := humanService.ApiConfig.Queries.UpdateHuman(ctx,
+// updateHuman is the write half of UpdateHuman.
+func updateHuman(ctx context.Context, humans repository.HumanRepository, tenantID string, id uuid.UUID, req *models.HumanRequest, params models.ExtraParamsResponse) (models.HumanResponse, error) {
+	current, err := getHuman(ctx, humans, tenantID, id)
+	if err != nil {
+		return models.HumanResponse{}, err
+	}
+	attributes := current.Attributes
+	if req.Attributes != nil {
+		defs, err := humans.ListAttributeDefinitions(ctx, tenantID)
+		if err != nil {
+			return models.HumanResponse{}, InternalError("failed to get attribute definitions", err)
+		}
+		attributes, err = validateAttributes(defs, req.Attributes)
+		if err != nil {
+			return models.HumanResponse{}, err
+		}
+	}
+	human, err := humans.UpdateHuman(ctx,
 		database.UpdateHumanParams{
-			ID:         uid,
+			TenantID:   tenantID,
+			ID:         id,
 			Name:       req.Name,
 			Surname:    req.Surname,
-			Patronymic: sql.NullString{String: req.Patronymic, Valid: patronymicValid},
+			Patronymic: sql.NullString{String: req.Patronymic, Valid: req.Patronymic != ""},
 			Age:        int32(params.Age),
 			Gender:     params.Gender,
 			Country:    params.Country,
+			Attributes: attributes,
 		})
 	if err != nil {
-		return models.HumanResponse{}, http.StatusInternalServerError, fmt.Errorf("error updating human: %s", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.HumanResponse{}, NotFoundError("human does not exists")
+		}
+		return models.HumanResponse{}, storageError("error updating human", err)
+	}
+	response := []models.HumanResponse{toHumanResponse(human)}
+	if err := loadTags(ctx, humans, tenantID, response); err != nil {
+		return models.HumanResponse{}, err
+	}
+	if err := recordHumanEvent(ctx, humans, tenantID, models.EventHumanUpdated, response[0]); err != nil {
+		return models.HumanResponse{}, err
+	}
+	return response[0], nil
+}
+
+// deleteHuman is the write half of DeleteHuman. It returns the human as it
+// was, tags included.
+func deleteHuman(ctx context.Context, humans repository.HumanRepository, tenantID string, id uuid.UUID) (models.HumanResponse, error) {
+	human, err := getHuman(ctx, humans, tenantID, id)
+	if err != nil {
+		return models.HumanResponse{}, err
 	}
-	fmt.Println("updated human:", human)
+	// Tags are read first, their join rows go away with the human.
+	response := []models.HumanResponse{toHumanResponse(human)}
+	if err := loadTags(ctx, humans, tenantID, response); err != nil {
+		return models.HumanResponse{}, err
+	}
+	_, err = humans.DeleteHuman(ctx, database.DeleteHumanParams{TenantID: tenantID, ID: id})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.HumanResponse{}, NotFoundError("human does not exists")
+		}
+		return models.HumanResponse{}, InternalError("failed to delete human", err)
+	}
+	if err := recordHumanEvent(ctx, humans, tenantID, models.EventHumanDeleted, response[0]); err != nil {
+		return models.HumanResponse{}, err
+	}
+	return response[0], nil
+}
 
+func toHumanResponse(human database.Human) models.HumanResponse {
 	return models.HumanResponse{
 		ID:         human.ID.String(),
 		Name:       human.Name,
@@ -177,57 +268,72 @@ func (humanService *UserService) UpdateHuman(ctx context.Context, req *models.Hu
 		Age:        int(human.Age),
 		Country:    human.Country,
 		Gender:     human.Gender,
-	}, http.StatusOK, nil
+		CreatedAt:  human.CreatedAt.UTC(),
+		UpdatedAt:  human.UpdatedAt.UTC(),
+		Tags:       []string{},
+		Attributes: decodeAttributes(human.Attributes),
+	}
 }
 
-func GetParamsFromAPI(name string) (models.ExtraParamsResponse, int, error) {
-	if name == "" {
-		return models.ExtraParamsResponse{}, http.StatusBadRequest, fmt.Errorf("name cant be empty")
+func (humanService *UserService) listHumansParams(ctx context.Context, tenantID string, filter models.HumanFilter) (database.ListHumansParams, error) {
+	params := database.ListHumansParams{
+		TenantID:     tenantID,
+		CreatedSince: nullTime(filter.CreatedSince),
+		CreatedUntil: nullTime(filter.CreatedUntil),
+		UpdatedSince: nullTime(filter.UpdatedSince),
+		UpdatedUntil: nullTime(filter.UpdatedUntil),
+		Attributes:   json.RawMessage("{}"),
+		Sort:         filter.Sort,
 	}
-	var respBodyAge models.AgeResponse
-	var respBodyCountry models.CountryResponse
-	var respBodyGender models.GenderResponse
-
-	ageResp, err := http.Get(fmt.Sprintf("https://api.agify.io/?name=%s", name))
-	if err != nil {
-		return models.ExtraParamsResponse{}, http.StatusInternalServerError, fmt.Errorf("failed to get human age: %s", err)
+	if len(filter.Attributes) > 0 {
+		defs, err := humanService.ApiConfig.Humans.ListAttributeDefinitions(ctx, tenantID)
+		if err != nil {
+			return database.ListHumansParams{}, InternalError("failed to get attribute definitions", err)
+		}
+		params.Attributes, err = attributeFilter(defs, filter.Attributes)
+		if err != nil {
+			return database.ListHumansParams{}, err
+		}
 	}
-	err = json.NewDecoder(ageResp.Body).Decode(&respBodyAge)
-	defer ageResp.Body.Close()
+	tags, err := normalizeTags(filter.Tags)
 	if err != nil {
-		return models.ExtraParamsResponse{}, http.StatusInternalServerError, fmt.Errorf("failed to decode human age: %s", err)
+		return database.ListHumansParams{}, err
 	}
-
-	genderResp, err := http.Get(fmt.Sprintf("https://api.genderize.io/?name=%s", name))
-	if err != nil {
-		return models.ExtraParamsResponse{}, http.StatusInternalServerError, fmt.Errorf("failed to get human gender: %s", err)
+	// An empty array rather than nil: the query checks its cardinality.
+	params.Tags = tags
+	switch filter.TagMatch {
+	case "", "any":
+	case "all":
+		params.MatchAllTags = true
+	default:
+		return database.ListHumansParams{}, ValidationError(fmt.Sprintf("unsupported tag match %q, expected any or all", filter.TagMatch), nil)
 	}
-	err = json.NewDecoder(genderResp.Body).Decode(&respBodyGender)
-	defer genderResp.Body.Close()
-	if err != nil {
-		return models.ExtraParamsResponse{}, http.StatusInternalServerError, fmt.Errorf("failed to decode human gender: %s", err)
+	switch filter.Sort {
+	case "":
+		params.Sort = "created_at"
+	case "created_at", "-created_at", "updated_at", "-updated_at":
+	default:
+		return database.ListHumansParams{}, ValidationError(fmt.Sprintf("unsupported sort %q", filter.Sort), nil)
 	}
+	return params, nil
+}
 
-	countryResp, err := http.Get(fmt.Sprintf("https://api.nationalize.io/?name=%s", name))
-	if err != nil {
-		return models.ExtraParamsResponse{}, http.StatusInternalServerError, fmt.Errorf("failed to get human country: %s", err)
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
 	}
-	err = json.NewDecoder(countryResp.Body).Decode(&respBodyCountry)
-	defer countryResp.Body.Close()
-	if err != nil {
-		return models.ExtraParamsResponse{}, http.StatusInternalServerError, fmt.Errorf("failed to decode human country: %s", err)
-	}
-	var mostProbabilityCountry struct {
-		Name        string
-		Probability float64
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+// GetParamsFromAPI enriches a single name with the same requests that
+// enrich batches, so it honours ctx the same way.
+func GetParamsFromAPI(ctx context.Context, name string) (models.ExtraParamsResponse, error) {
+	if name == "" {
+		return models.ExtraParamsResponse{}, ValidationError("name cant be empty", nil)
 	}
-	for _, c := range respBodyCountry.Country {
-		if c.Probability > mostProbabilityCountry.Probability {
-			mostProbabilityCountry.Name = c.CountryID
-			mostProbabilityCountry.Probability = c.Probability
-		}
+	params, err := fetchParamsChunk(ctx, []string{name})
+	if err != nil {
+		return models.ExtraParamsResponse{}, err
 	}
-
-	return models.ExtraParamsResponse{Age: respBodyAge.Age, Gender: respBodyGender.Gender, Country: mostProbabilityCountry.Name}, http.StatusOK, nil
-
+	return params[0], nil
 }
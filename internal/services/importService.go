@@ -0,0 +1,383 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+)
+
+const (
+	MaxImportBytes = 32 << 20
+	MaxImportRows  = 100_000
+
+	// maxRunningImports bounds the imports a process runs at once; each
+	// keeps its parsed file in memory until it finishes.
+	maxRunningImports = 4
+	// importStaleAfter is how long an unfinished job may go without saving
+	// progress before it is taken for abandoned by a process that exited.
+	importStaleAfter = 5 * time.Minute
+	// importSweepInterval is how often abandoned jobs are looked for.
+	importSweepInterval = time.Minute
+	// importChunkSize is the rows enriched together before they are
+	// created one by one.
+	importChunkSize = 100
+	// importEnrichmentTimeout bounds the enrichment of a chunk, so a stuck
+	// upstream fails its rows instead of stalling the job until it is
+	// taken for abandoned.
+	importEnrichmentTimeout = time.Minute
+
+	importFormatCSV    = "csv"
+	importFormatNDJSON = "ndjson"
+)
+
+// importSlots holds a token for every import running in this process.
+var importSlots = make(chan struct{}, maxRunningImports)
+
+// importRow is a parsed data row. Rows that could not be parsed keep the
+// error and are reported without being created.
+type importRow struct {
+	number int
+	req    *models.HumanRequest
+	err    error
+}
+
+// StartImport parses the whole file, records an import job and creates the
+// humans in the background with the same checks as CreateHuman. The job
+// keeps running after the request that started it has finished. At most
+// maxRunningImports run at once; further imports are refused until one
+// finishes.
+func (humanService *UserService) StartImport(ctx context.Context, format string, body io.Reader) (models.ImportJobResponse, error) {
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.ImportJobResponse{}, err
+	}
+	select {
+	case importSlots <- struct{}{}:
+	default:
+		return models.ImportJobResponse{}, BusyError("too many imports are running, retry later")
+	}
+	started := false
+	defer func() {
+		if !started {
+			<-importSlots
+		}
+	}()
+	var rows []importRow
+	switch format {
+	case importFormatCSV:
+		rows, err = parseCSVImport(body)
+	case importFormatNDJSON:
+		rows, err = parseNDJSONImport(body)
+	default:
+		return models.ImportJobResponse{}, ValidationError(fmt.Sprintf("unsupported import format %q, expected csv or ndjson", format), nil)
+	}
+	if err != nil {
+		return models.ImportJobResponse{}, err
+	}
+	if len(rows) == 0 {
+		return models.ImportJobResponse{}, ValidationError("import file has no rows", nil)
+	}
+
+	job, err := humanService.ApiConfig.Humans.CreateImportJob(ctx, database.CreateImportJobParams{
+		TenantID: tenantID, Format: format, TotalRows: int32(len(rows)),
+	})
+	if err != nil {
+		return models.ImportJobResponse{}, storageError("error saving import job", err)
+	}
+	started = true
+	go func() {
+		defer func() { <-importSlots }()
+		humanService.runImport(context.WithoutCancel(ctx), job, rows)
+	}()
+	return toImportJobResponse(job, nil), nil
+}
+
+func (humanService *UserService) GetImport(ctx context.Context, id string) (models.ImportJobResponse, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return models.ImportJobResponse{}, ValidationError("bad uuid", err)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.ImportJobResponse{}, err
+	}
+
+	job, err := humanService.ApiConfig.Humans.GetImportJob(ctx, database.GetImportJobParams{TenantID: tenantID, ID: uid})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ImportJobResponse{}, NotFoundError("import does not exists")
+		}
+		return models.ImportJobResponse{}, InternalError("failed to get import", err)
+	}
+	rowErrors, err := humanService.ApiConfig.Humans.ListImportErrors(ctx, database.ListImportErrorsParams{TenantID: tenantID, JobID: uid})
+	if err != nil {
+		return models.ImportJobResponse{}, InternalError("failed to get import errors", err)
+	}
+	return toImportJobResponse(job, rowErrors), nil
+}
+
+// runImport enriches the rows a chunk at a time and creates them one by
+// one, recording progress after each. It stops early when the job was
+// failed as stale in the meantime.
+func (humanService *UserService) runImport(ctx context.Context, job database.ImportJob, rows []importRow) {
+	progress := database.UpdateImportJobProgressParams{TenantID: job.TenantID, ID: job.ID, Status: "running"}
+	stopped := false
+	defer func() {
+		if stopped {
+			return
+		}
+		if p := recover(); p != nil {
+			log.Printf("import %s panicked: %v", job.ID, p)
+			progress.Status = "failed"
+		} else {
+			progress.Status = "completed"
+		}
+		progress.FinishedAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+		humanService.saveImportProgress(ctx, progress)
+	}()
+	if stopped = !humanService.saveImportProgress(ctx, progress); stopped {
+		return
+	}
+
+	for start := 0; start < len(rows); start += importChunkSize {
+		chunk := rows[start:min(start+importChunkSize, len(rows))]
+		enriched := enrichImportRows(ctx, chunk)
+		for _, row := range chunk {
+			err := row.err
+			if err == nil {
+				err = humanService.importHuman(ctx, job.TenantID, row.req, enriched)
+			}
+			progress.ProcessedRows++
+			if err != nil {
+				progress.FailedRows++
+				message := PublicMessage(err)
+				if message != err.Error() {
+					log.Printf("import %s: row %d: %s", job.ID, row.number, err)
+				}
+				fields, _ := json.Marshal(importFieldErrors(err))
+				rowErr := humanService.ApiConfig.Humans.CreateImportError(ctx, database.CreateImportErrorParams{
+					TenantID:  job.TenantID,
+					JobID:     job.ID,
+					RowNumber: int32(row.number),
+					Code:      KindOf(err).String(),
+					Message:   message,
+					Fields:    fields,
+				})
+				if rowErr != nil {
+					log.Printf("import %s: failed to record error of row %d: %s", job.ID, row.number, rowErr)
+				}
+			} else {
+				progress.CreatedRows++
+			}
+			if stopped = !humanService.saveImportProgress(ctx, progress); stopped {
+				return
+			}
+		}
+	}
+}
+
+// importFieldErrors returns the rejected fields of a row error, never nil.
+func importFieldErrors(err error) []models.FieldError {
+	if fields := FieldErrorsOf(err); fields != nil {
+		return fields
+	}
+	return []models.FieldError{}
+}
+
+// enrichImportRows enriches the names of the valid rows of a chunk in
+// batches.
+func enrichImportRows(ctx context.Context, rows []importRow) map[string]enrichment {
+	var names []string
+	for _, row := range rows {
+		if row.err == nil && validateHumanRequest(row.req) == nil {
+			names = append(names, row.req.Name)
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, importEnrichmentTimeout)
+	defer cancel()
+	return getParamsFromAPIBatch(ctx, names)
+}
+
+// importHuman creates a row the way CreateHuman does, with the enrichment
+// fetched for its chunk.
+func (humanService *UserService) importHuman(ctx context.Context, tenantID string, req *models.HumanRequest, enriched map[string]enrichment) error {
+	if err := validateHumanRequest(req); err != nil {
+		return err
+	}
+	result := enriched[req.Name]
+	if result.err != nil {
+		return result.err
+	}
+	return humanService.inTx(ctx, TxOptions{Isolation: sql.LevelReadCommitted}, func(tx repository.Tx) error {
+		_, err := createHuman(ctx, tx.Humans(), tenantID, req, result.params)
+		return err
+	})
+}
+
+// saveImportProgress reports false when the job is no longer running, so
+// the import should stop. Other failures to save are only logged.
+func (humanService *UserService) saveImportProgress(ctx context.Context, progress database.UpdateImportJobProgressParams) bool {
+	_, err := humanService.ApiConfig.Humans.UpdateImportJobProgress(ctx, progress)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Printf("import %s: job is no longer running, stopping", progress.ID)
+		return false
+	}
+	if err != nil {
+		log.Printf("import %s: failed to save progress: %s", progress.ID, err)
+	}
+	return true
+}
+
+// SweepStaleImports fails imports abandoned by processes that exited
+// while running them, right away and then every importSweepInterval until
+// ctx is done. Jobs cannot be resumed, as their rows were only kept in
+// the memory of that process.
+func (humanService *UserService) SweepStaleImports(ctx context.Context) {
+	for {
+		var failed int64
+		err := humanService.inTx(ctx, TxOptions{Isolation: sql.LevelReadCommitted}, func(tx repository.Tx) error {
+			var err error
+			failed, err = tx.Imports().FailStaleImportJobs(ctx, time.Now().Add(-importStaleAfter))
+			return err
+		})
+		if err != nil {
+			log.Printf("failed to sweep stale imports: %s", err)
+		} else if failed > 0 {
+			log.Printf("failed %d stale imports", failed)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(importSweepInterval):
+		}
+	}
+}
+
+// parseCSVImport reads a CSV file whose header names the name, surname and
+// optional patronymic columns, in any order. Other columns are ignored.
+func parseCSVImport(body io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, importReadError(err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))] = i
+	}
+	for _, required := range []string{"name", "surname"} {
+		if _, ok := columns[required]; !ok {
+			return nil, ValidationError(fmt.Sprintf("csv header has no %q column", required), nil)
+		}
+	}
+	field := func(record []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []importRow
+	for number := 1; ; number++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if number > MaxImportRows {
+			return nil, ValidationError(fmt.Sprintf("import file has more than %d rows", MaxImportRows), nil)
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rows = append(rows, importRow{number: number, err: ValidationError(fmt.Sprintf("malformed csv row: %s", err), nil)})
+			continue
+		}
+		if err != nil {
+			return nil, importReadError(err)
+		}
+		rows = append(rows, importRow{number: number, req: &models.HumanRequest{
+			Name:       field(record, "name"),
+			Surname:    field(record, "surname"),
+			Patronymic: field(record, "patronymic"),
+		}})
+	}
+}
+
+// parseNDJSONImport reads one JSON object per line. Blank lines are skipped.
+func parseNDJSONImport(body io.Reader) ([]importRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	var rows []importRow
+	number := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		number++
+		if number > MaxImportRows {
+			return nil, ValidationError(fmt.Sprintf("import file has more than %d rows", MaxImportRows), nil)
+		}
+		var req models.HumanRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			rows = append(rows, importRow{number: number, err: ValidationError(fmt.Sprintf("malformed json row: %s", err), nil)})
+			continue
+		}
+		rows = append(rows, importRow{number: number, req: &req})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, importReadError(err)
+	}
+	return rows, nil
+}
+
+func importReadError(err error) error {
+	if err == io.EOF {
+		return ValidationError("import file is empty", nil)
+	}
+	if errors.Is(err, bufio.ErrTooLong) {
+		return ValidationError("import file has a line longer than 1 MiB", err)
+	}
+	return ValidationError("failed to read import file", err)
+}
+
+func toImportJobResponse(job database.ImportJob, rowErrors []database.ImportJobError) models.ImportJobResponse {
+	response := models.ImportJobResponse{
+		ID:            job.ID.String(),
+		Format:        job.Format,
+		Status:        job.Status,
+		TotalRows:     int(job.TotalRows),
+		ProcessedRows: int(job.ProcessedRows),
+		CreatedRows:   int(job.CreatedRows),
+		FailedRows:    int(job.FailedRows),
+		Errors:        make([]models.ImportRowError, len(rowErrors)),
+		CreatedAt:     job.CreatedAt.UTC(),
+		UpdatedAt:     job.UpdatedAt.UTC(),
+	}
+	for i, rowErr := range rowErrors {
+		response.Errors[i] = models.ImportRowError{Row: int(rowErr.RowNumber), Code: rowErr.Code, Message: rowErr.Message}
+		_ = json.Unmarshal(rowErr.Fields, &response.Errors[i].Errors)
+	}
+	if job.FinishedAt.Valid {
+		finishedAt := job.FinishedAt.Time.UTC()
+		response.FinishedAt = &finishedAt
+	}
+	return response
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+)
+
+const (
+	DefaultFamilyTreeDepth = 3
+	maxFamilyTreeDepth     = 10
+)
+
+// inverseRelations maps a relation to the one seen from the relative's side.
+var inverseRelations = map[string]string{
+	"parent":  "child",
+	"child":   "parent",
+	"spouse":  "spouse",
+	"sibling": "sibling",
+}
+
+// CreateRelationship records that the relative is req.Type of the human.
+// Parent and child links may not form a cycle.
+func (humanService *UserService) CreateRelationship(ctx context.Context, humanID string, req *models.RelationshipRequest) ([]models.RelationshipResponse, error) {
+	if req == nil {
+		return nil, ValidationError("bad request", nil)
+	}
+	inverse, ok := inverseRelations[req.Type]
+	if !ok {
+		return nil, ValidationError(fmt.Sprintf("unknown relationship type %q", req.Type), nil)
+	}
+	uid, err := uuid.Parse(humanID)
+	if err != nil {
+		return nil, ValidationError("bad uuid", err)
+	}
+	relativeID, err := uuid.Parse(req.RelativeID)
+	if err != nil {
+		return nil, ValidationError("bad relative uuid", err)
+	}
+	if uid == relativeID {
+		return nil, ValidationError("human cannot be related to themselves", nil)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	links := []database.CreateRelationshipParams{
+		{TenantID: tenantID, HumanID: uid, RelativeID: relativeID, Relation: req.Type},
+	}
+	if req.Bidirectional {
+		links = append(links, database.CreateRelationshipParams{
+			TenantID: tenantID, HumanID: relativeID, RelativeID: uid, Relation: inverse,
+		})
+	}
+
+	var created []database.HumanRelationship
+	// Serializable isolation keeps two concurrent inserts from closing a cycle
+	// that neither of them sees on its own.
+	err = humanService.inTx(ctx, TxOptions{Isolation: sql.LevelSerializable}, func(tx repository.Tx) error {
+		created = created[:0]
+		for _, id := range []uuid.UUID{uid, relativeID} {
+			if err := humanExists(ctx, tx.Humans(), tenantID, id); err != nil {
+				return err
+			}
+		}
+		if err := checkNoAncestryCycle(ctx, tx.Humans(), tenantID, uid, relativeID, req.Type); err != nil {
+			return err
+		}
+		for _, link := range links {
+			rel, err := tx.Humans().CreateRelationship(ctx, link)
+			if err != nil {
+				return storageError("error saving relationship", err)
+			}
+			created = append(created, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]models.RelationshipResponse, len(created))
+	for i, rel := range created {
+		response[i] = toRelationshipResponse(rel)
+	}
+	return response, nil
+}
+
+// DeleteRelationship removes the relation of the given type between the
+// human and the relative, and its inverse when bidirectional is set.
+func (humanService *UserService) DeleteRelationship(ctx context.Context, humanID, relativeID, relationType string, bidirectional bool) ([]models.RelationshipResponse, error) {
+	inverse, ok := inverseRelations[relationType]
+	if !ok {
+		return nil, ValidationError(fmt.Sprintf("unknown relationship type %q", relationType), nil)
+	}
+	uid, err := uuid.Parse(humanID)
+	if err != nil {
+		return nil, ValidationError("bad uuid", err)
+	}
+	rid, err := uuid.Parse(relativeID)
+	if err != nil {
+		return nil, ValidationError("bad relative uuid", err)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []database.HumanRelationship
+	err = humanService.inTx(ctx, TxOptions{Isolation: sql.LevelReadCommitted}, func(tx repository.Tx) error {
+		deleted = deleted[:0]
+		rel, err := tx.Humans().DeleteRelationship(ctx, database.DeleteRelationshipParams{
+			TenantID: tenantID, HumanID: uid, RelativeID: rid, Relation: relationType,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return NotFoundError("relationship does not exists")
+			}
+			return InternalError("failed to delete relationship", err)
+		}
+		deleted = append(deleted, rel)
+		if !bidirectional {
+			return nil
+		}
+		rel, err = tx.Humans().DeleteRelationship(ctx, database.DeleteRelationshipParams{
+			TenantID: tenantID, HumanID: rid, RelativeID: uid, Relation: inverse,
+		})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return InternalError("failed to delete relationship", err)
+		}
+		if err == nil {
+			deleted = append(deleted, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]models.RelationshipResponse, len(deleted))
+	for i, rel := range deleted {
+		response[i] = toRelationshipResponse(rel)
+	}
+	return response, nil
+}
+
+func (humanService *UserService) GetRelatives(ctx context.Context, humanID string) ([]models.RelativeResponse, error) {
+	uid, err := uuid.Parse(humanID)
+	if err != nil {
+		return nil, ValidationError("bad uuid", err)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := humanExists(ctx, humanService.ApiConfig.Humans, tenantID, uid); err != nil {
+		return nil, err
+	}
+
+	rows, err := humanService.ApiConfig.Humans.ListRelatives(ctx, database.ListRelativesParams{TenantID: tenantID, HumanID: uid})
+	if err != nil {
+		return nil, InternalError("failed to get relatives", err)
+	}
+	humans := make([]models.HumanResponse, len(rows))
+	for i, row := range rows {
+		humans[i] = toHumanResponse(row.Human)
+	}
+	if err := loadTags(ctx, humanService.ApiConfig.Humans, tenantID, humans); err != nil {
+		return nil, err
+	}
+	relatives := make([]models.RelativeResponse, len(rows))
+	for i, row := range rows {
+		relatives[i] = models.RelativeResponse{
+			RelationshipID: row.HumanRelationship.ID.String(),
+			Type:           row.HumanRelationship.Relation,
+			Human:          humans[i],
+		}
+	}
+	return relatives, nil
+}
+
+// GetFamilyTree returns everyone connected to the human by at most depth
+// relationships, in either direction, with the relationships between them.
+func (humanService *UserService) GetFamilyTree(ctx context.Context, humanID string, depth int) (models.FamilyTreeResponse, error) {
+	if depth < 1 || depth > maxFamilyTreeDepth {
+		return models.FamilyTreeResponse{}, ValidationError(fmt.Sprintf("depth must be between 1 and %d", maxFamilyTreeDepth), nil)
+	}
+	uid, err := uuid.Parse(humanID)
+	if err != nil {
+		return models.FamilyTreeResponse{}, ValidationError("bad uuid", err)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.FamilyTreeResponse{}, err
+	}
+
+	var (
+		reached []database.WalkFamilyTreeRow
+		humans  []database.Human
+		members []models.HumanResponse
+		links   []database.HumanRelationship
+	)
+	opts := TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err = humanService.inTx(ctx, opts, func(tx repository.Tx) error {
+		if err := humanExists(ctx, tx.Humans(), tenantID, uid); err != nil {
+			return err
+		}
+		var err error
+		reached, err = tx.Humans().WalkFamilyTree(ctx, database.WalkFamilyTreeParams{
+			HumanID: uid, TenantID: tenantID, MaxDepth: int32(depth),
+		})
+		if err != nil {
+			return InternalError("failed to walk family tree", err)
+		}
+		ids := make([]uuid.UUID, len(reached))
+		for i, row := range reached {
+			ids[i] = row.HumanID
+		}
+		humans, err = tx.Humans().ListHumansByIDs(ctx, database.ListHumansByIDsParams{TenantID: tenantID, Ids: ids})
+		if err != nil {
+			return InternalError("failed to get family members", err)
+		}
+		links, err = tx.Humans().ListRelationshipsAmong(ctx, database.ListRelationshipsAmongParams{TenantID: tenantID, Ids: ids})
+		if err != nil {
+			return InternalError("failed to get family relationships", err)
+		}
+		members = make([]models.HumanResponse, len(humans))
+		for i, human := range humans {
+			members[i] = toHumanResponse(human)
+		}
+		return loadTags(ctx, tx.Humans(), tenantID, members)
+	})
+	if err != nil {
+		return models.FamilyTreeResponse{}, err
+	}
+
+	byID := make(map[uuid.UUID]models.HumanResponse, len(members))
+	for i, human := range humans {
+		byID[human.ID] = members[i]
+	}
+	tree := models.FamilyTreeResponse{
+		RootID:        uid.String(),
+		Depth:         depth,
+		Members:       make([]models.FamilyMember, 0, len(reached)),
+		Relationships: make([]models.RelationshipResponse, len(links)),
+	}
+	for _, row := range reached {
+		human, ok := byID[row.HumanID]
+		if !ok {
+			continue
+		}
+		tree.Members = append(tree.Members, models.FamilyMember{Depth: int(row.Depth), Human: human})
+	}
+	for i, rel := range links {
+		tree.Relationships[i] = toRelationshipResponse(rel)
+	}
+	return tree, nil
+}
+
+func humanExists(ctx context.Context, humans repository.HumanRepository, tenantID string, id uuid.UUID) error {
+	_, err := getHuman(ctx, humans, tenantID, id)
+	return err
+}
+
+func getHuman(ctx context.Context, humans repository.HumanRepository, tenantID string, id uuid.UUID) (database.Human, error) {
+	human, err := humans.GetHumanByID(ctx, database.GetHumanByIDParams{TenantID: tenantID, ID: id})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.Human{}, NotFoundError(fmt.Sprintf("human %s does not exists", id))
+		}
+		return database.Human{}, InternalError("failed to get human", err)
+	}
+	return human, nil
+}
+
+// checkNoAncestryCycle rejects a parent or child link when the would-be
+// child is already an ancestor of the would-be parent.
+func checkNoAncestryCycle(ctx context.Context, humans repository.HumanRepository, tenantID string, humanID, relativeID uuid.UUID, relation string) error {
+	var parentID, childID uuid.UUID
+	switch relation {
+	case "parent":
+		parentID, childID = relativeID, humanID
+	case "child":
+		parentID, childID = humanID, relativeID
+	default:
+		return nil
+	}
+	ancestors, err := humans.ListAncestorIDs(ctx, database.ListAncestorIDsParams{TenantID: tenantID, HumanID: parentID})
+	if err != nil {
+		return InternalError("failed to check ancestry", err)
+	}
+	if slices.Contains(ancestors, childID) {
+		return ConflictError("relationship would create an ancestry cycle", nil)
+	}
+	return nil
+}
+
+func toRelationshipResponse(rel database.HumanRelationship) models.RelationshipResponse {
+	return models.RelationshipResponse{
+		ID:         rel.ID.String(),
+		HumanID:    rel.HumanID.String(),
+		RelativeID: rel.RelativeID.String(),
+		Type:       rel.Relation,
+		CreatedAt:  rel.CreatedAt.UTC(),
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+)
+
+const (
+	DefaultAgeBucketWidth = 10
+	maxAgeBucketWidth     = 150
+)
+
+// GetHumanStats aggregates the humans matching filter. All aggregates are
+// read from one snapshot so their totals agree.
+func (humanService *UserService) GetHumanStats(ctx context.Context, filter models.HumanFilter, bucketWidth int) (models.HumanStatsResponse, error) {
+	if bucketWidth < 1 || bucketWidth > maxAgeBucketWidth {
+		return models.HumanStatsResponse{}, ValidationError(fmt.Sprintf("bucket width must be between 1 and %d", maxAgeBucketWidth), nil)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.HumanStatsResponse{}, err
+	}
+	params, err := humanService.listHumansParams(ctx, tenantID, filter)
+	if err != nil {
+		return models.HumanStatsResponse{}, err
+	}
+
+	var (
+		byGender  []database.CountHumansByGenderRow
+		byCountry []database.CountHumansByCountryRow
+		byAge     []database.CountHumansByAgeBucketRow
+	)
+	opts := TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err = humanService.inTx(ctx, opts, func(tx repository.Tx) error {
+		var err error
+		byGender, err = tx.Humans().CountHumansByGender(ctx, database.CountHumansByGenderParams{
+			TenantID:     params.TenantID,
+			CreatedSince: params.CreatedSince,
+			CreatedUntil: params.CreatedUntil,
+			UpdatedSince: params.UpdatedSince,
+			UpdatedUntil: params.UpdatedUntil,
+			Tags:         params.Tags,
+			MatchAllTags: params.MatchAllTags,
+			Attributes:   params.Attributes,
+		})
+		if err != nil {
+			return InternalError("failed to count humans by gender", err)
+		}
+		byCountry, err = tx.Humans().CountHumansByCountry(ctx, database.CountHumansByCountryParams{
+			TenantID:     params.TenantID,
+			CreatedSince: params.CreatedSince,
+			CreatedUntil: params.CreatedUntil,
+			UpdatedSince: params.UpdatedSince,
+			UpdatedUntil: params.UpdatedUntil,
+			Tags:         params.Tags,
+			MatchAllTags: params.MatchAllTags,
+			Attributes:   params.Attributes,
+		})
+		if err != nil {
+			return InternalError("failed to count humans by country", err)
+		}
+		byAge, err = tx.Humans().CountHumansByAgeBucket(ctx, database.CountHumansByAgeBucketParams{
+			BucketWidth:  int32(bucketWidth),
+			TenantID:     params.TenantID,
+			CreatedSince: params.CreatedSince,
+			CreatedUntil: params.CreatedUntil,
+			UpdatedSince: params.UpdatedSince,
+			UpdatedUntil: params.UpdatedUntil,
+			Tags:         params.Tags,
+			MatchAllTags: params.MatchAllTags,
+			Attributes:   params.Attributes,
+		})
+		if err != nil {
+			return InternalError("failed to count humans by age", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.HumanStatsResponse{}, err
+	}
+
+	stats := models.HumanStatsResponse{
+		ByGender:    make([]models.GenderCount, len(byGender)),
+		ByCountry:   make([]models.CountryStats, len(byCountry)),
+		BucketWidth: bucketWidth,
+		AgeBuckets:  make([]models.AgeBucket, len(byAge)),
+	}
+	for i, row := range byGender {
+		stats.Total += row.Total
+		stats.ByGender[i] = models.GenderCount{Gender: row.Gender, Count: row.Total}
+	}
+	for i, row := range byCountry {
+		stats.ByCountry[i] = models.CountryStats{
+			Country:    row.Country,
+			Count:      row.Total,
+			AverageAge: math.Round(row.AverageAge*100) / 100,
+		}
+	}
+	for i, row := range byAge {
+		stats.AgeBuckets[i] = models.AgeBucket{
+			From:  int(row.BucketStart),
+			To:    int(row.BucketStart) + bucketWidth,
+			Count: row.Total,
+		}
+	}
+	return stats, nil
+}
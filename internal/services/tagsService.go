@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+)
+
+var tagPattern = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N}._:-]{0,63}$`)
+
+// AttachTags adds the tags to the human, creating tags the tenant has not
+// used before. Tags the human already carries are left as they are.
+func (humanService *UserService) AttachTags(ctx context.Context, humanID string, req *models.TagsRequest) (models.HumanResponse, error) {
+	if req == nil {
+		return models.HumanResponse{}, ValidationError("bad request", nil)
+	}
+	uid, err := uuid.Parse(humanID)
+	if err != nil {
+		return models.HumanResponse{}, ValidationError("bad uuid", err)
+	}
+	names, err := normalizeTags(req.Tags)
+	if err != nil {
+		return models.HumanResponse{}, err
+	}
+	if len(names) == 0 {
+		return models.HumanResponse{}, ValidationError("at least one tag is required", nil)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.HumanResponse{}, err
+	}
+
+	var response []models.HumanResponse
+	err = humanService.inTx(ctx, TxOptions{Isolation: sql.LevelReadCommitted}, func(tx repository.Tx) error {
+		human, err := getHuman(ctx, tx.Humans(), tenantID, uid)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			tag, err := tx.Humans().UpsertTag(ctx, database.UpsertTagParams{TenantID: tenantID, Name: name})
+			if err != nil {
+				return storageError("error saving tag", err)
+			}
+			err = tx.Humans().AttachTag(ctx, database.AttachTagParams{TenantID: tenantID, HumanID: uid, TagID: tag.ID})
+			if err != nil {
+				return storageError("error attaching tag", err)
+			}
+		}
+		response = []models.HumanResponse{toHumanResponse(human)}
+		return loadTags(ctx, tx.Humans(), tenantID, response)
+	})
+	if err != nil {
+		return models.HumanResponse{}, err
+	}
+	return response[0], nil
+}
+
+// DetachTag removes the tag from the human. The tag itself is kept for
+// other humans of the tenant.
+func (humanService *UserService) DetachTag(ctx context.Context, humanID, tag string) (models.HumanResponse, error) {
+	uid, err := uuid.Parse(humanID)
+	if err != nil {
+		return models.HumanResponse{}, ValidationError("bad uuid", err)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.HumanResponse{}, err
+	}
+	name := strings.ToLower(strings.TrimSpace(tag))
+
+	var response []models.HumanResponse
+	err = humanService.inTx(ctx, TxOptions{Isolation: sql.LevelReadCommitted}, func(tx repository.Tx) error {
+		human, err := getHuman(ctx, tx.Humans(), tenantID, uid)
+		if err != nil {
+			return err
+		}
+		removed, err := tx.Humans().DetachTag(ctx, database.DetachTagParams{TenantID: tenantID, HumanID: uid, Name: name})
+		if err != nil {
+			return InternalError("failed to detach tag", err)
+		}
+		if removed == 0 {
+			return NotFoundError(fmt.Sprintf("human has no tag %q", name))
+		}
+		response = []models.HumanResponse{toHumanResponse(human)}
+		return loadTags(ctx, tx.Humans(), tenantID, response)
+	})
+	if err != nil {
+		return models.HumanResponse{}, err
+	}
+	return response[0], nil
+}
+
+// normalizeTags lowercases and deduplicates tag names, keeping the order
+// they were given in.
+func normalizeTags(tags []string) ([]string, error) {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		name := strings.ToLower(strings.TrimSpace(tag))
+		if !tagPattern.MatchString(name) {
+			return nil, ValidationError(fmt.Sprintf("invalid tag %q", tag), nil)
+		}
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// loadTags fills in the tags of humans with a single query.
+func loadTags(ctx context.Context, repo repository.HumanRepository, tenantID string, humans []models.HumanResponse) error {
+	if len(humans) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(humans))
+	for i, human := range humans {
+		ids[i] = uuid.MustParse(human.ID)
+	}
+	rows, err := repo.ListTagsByHumanIDs(ctx, database.ListTagsByHumanIDsParams{TenantID: tenantID, HumanIds: ids})
+	if err != nil {
+		return InternalError("failed to get tags", err)
+	}
+	byHuman := make(map[string][]string)
+	for _, row := range rows {
+		humanID := row.HumanID.String()
+		byHuman[humanID] = append(byHuman[humanID], row.Name)
+	}
+	for i := range humans {
+		if tags, ok := byHuman[humans[i].ID]; ok {
+			humans[i].Tags = tags
+		}
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/tenant"
+)
+
+// tenantID returns the tenant the request was resolved to. Every query is
+// scoped by it, so a missing tenant is a programming error.
+func tenantID(ctx context.Context) (string, error) {
+	id, ok := tenant.FromContext(ctx)
+	if !ok {
+		return "", InternalError("tenant is not resolved", errors.New("missing tenant in context"))
+	}
+	return id, nil
+}
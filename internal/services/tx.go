@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+	"github.com/lib/pq"
+)
+
+const (
+	pqSerializationFailure = "40001"
+	pqDeadlockDetected     = "40P01"
+
+	maxTxAttempts = 3
+	txRetryDelay  = 20 * time.Millisecond
+)
+
+// TxOptions configures a unit of work run by inTx.
+type TxOptions struct {
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+}
+
+// inTx runs fn with repositories bound to a single transaction. The
+// transaction is committed when fn returns nil and rolled back otherwise.
+// Serialization failures and deadlocks are retried with a growing delay, so
+// fn must be safe to run more than once.
+func (humanService *UserService) inTx(ctx context.Context, opts TxOptions, fn func(tx repository.Tx) error) error {
+	delay := txRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := humanService.runTx(ctx, opts, fn)
+		if err == nil || !isRetryableTxError(err) || attempt == maxTxAttempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return InternalError("transaction aborted", ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (humanService *UserService) runTx(ctx context.Context, opts TxOptions, fn func(tx repository.Tx) error) (err error) {
+	tx, err := humanService.ApiConfig.Transactor.BeginTx(ctx, &sql.TxOptions{
+		Isolation: opts.Isolation,
+		ReadOnly:  opts.ReadOnly,
+	})
+	if err != nil {
+		return InternalError("failed to begin transaction", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %s)", err, rbErr)
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return storageError("failed to commit transaction", err)
+	}
+	return nil
+}
+
+func isRetryableTxError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == pqSerializationFailure || pqErr.Code == pqDeadlockDetected
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+)
+
+const (
+	MaxNameLength = 100
+
+	FieldRequired          = "required"
+	FieldTooLong           = "too_long"
+	FieldInvalidCharacters = "invalid_characters"
+	FieldInvalidFormat     = "invalid_format"
+)
+
+// validateHumanRequest checks the names of a human. Name and surname are
+// required, patronymic is optional, and each is at most MaxNameLength
+// letters long. A name consists of Latin or Cyrillic letters, where single
+// hyphens and apostrophes may join letters, as in "Салтыков-Щедрин" or
+// "O'Brien". Every invalid field is reported.
+func validateHumanRequest(req *models.HumanRequest) error {
+	var fields []models.FieldError
+	check := func(field, value string, required bool) {
+		if value == "" {
+			if required {
+				fields = append(fields, models.FieldError{
+					Field: field, Code: FieldRequired, Message: fmt.Sprintf("%s is required", field),
+				})
+			}
+			return
+		}
+		if fieldErr, ok := checkPersonName(field, value); !ok {
+			fields = append(fields, fieldErr)
+		}
+	}
+	check("name", req.Name, true)
+	check("surname", req.Surname, true)
+	check("patronymic", req.Patronymic, false)
+	if len(fields) > 0 {
+		return InvalidFieldsError("invalid human", fields)
+	}
+	return nil
+}
+
+func checkPersonName(field, value string) (models.FieldError, bool) {
+	if utf8.RuneCountInString(value) > MaxNameLength {
+		return models.FieldError{
+			Field: field, Code: FieldTooLong,
+			Message: fmt.Sprintf("%s must be at most %d characters long", field, MaxNameLength),
+		}, false
+	}
+	for _, r := range value {
+		if !isNameLetter(r) && !isNameSeparator(r) {
+			return models.FieldError{
+				Field: field, Code: FieldInvalidCharacters,
+				Message: fmt.Sprintf("%s may only contain Latin or Cyrillic letters, hyphens and apostrophes, got %q", field, r),
+			}, false
+		}
+	}
+	first, _ := utf8.DecodeRuneInString(value)
+	last, _ := utf8.DecodeLastRuneInString(value)
+	if isNameSeparator(first) || isNameSeparator(last) || hasAdjacentSeparators(value) {
+		return models.FieldError{
+			Field: field, Code: FieldInvalidFormat,
+			Message: fmt.Sprintf("%s must start and end with a letter and separate parts with a single hyphen or apostrophe", field),
+		}, false
+	}
+	return models.FieldError{}, true
+}
+
+func isNameLetter(r rune) bool {
+	return unicode.IsLetter(r) && (unicode.Is(unicode.Latin, r) || unicode.Is(unicode.Cyrillic, r))
+}
+
+// isNameSeparator reports hyphens and the ASCII and typographic
+// apostrophes.
+func isNameSeparator(r rune) bool {
+	return r == '-' || r == '\'' || r == '’'
+}
+
+func hasAdjacentSeparators(value string) bool {
+	previous := false
+	for _, r := range value {
+		separator := isNameSeparator(r)
+		if separator && previous {
+			return true
+		}
+		previous = separator
+	}
+	return false
+}
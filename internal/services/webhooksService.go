@@ -0,0 +1,440 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/models"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/webhooks"
+)
+
+const (
+	DefaultWebhookDeliveryLimit = 50
+	MaxWebhookDeliveryLimit     = 500
+
+	maxWebhookURLLength   = 2048
+	webhookResolveTimeout = 5 * time.Second
+	minWebhookSecretLen   = 16
+	maxWebhookSecretLen   = 256
+)
+
+var webhookEventTypes = []string{models.EventHumanCreated, models.EventHumanUpdated, models.EventHumanDeleted}
+
+// CreateWebhook subscribes a URL to events of the tenant. The secret is
+// returned only here.
+func (humanService *UserService) CreateWebhook(ctx context.Context, req *models.WebhookRequest) (models.WebhookResponse, error) {
+	if req == nil {
+		return models.WebhookResponse{}, ValidationError("bad request", nil)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.WebhookResponse{}, err
+	}
+	if err := humanService.validateWebhookURL(ctx, req.URL); err != nil {
+		return models.WebhookResponse{}, err
+	}
+	eventTypes, err := normalizeWebhookEventTypes(req.EventTypes)
+	if err != nil {
+		return models.WebhookResponse{}, err
+	}
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = webhookSecret(); err != nil {
+			return models.WebhookResponse{}, err
+		}
+	} else if err := validateWebhookSecret(secret); err != nil {
+		return models.WebhookResponse{}, err
+	}
+
+	sub, err := humanService.ApiConfig.Humans.CreateWebhookSubscription(ctx, database.CreateWebhookSubscriptionParams{
+		TenantID:   tenantID,
+		Url:        req.URL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+	})
+	if err != nil {
+		return models.WebhookResponse{}, storageError("error saving webhook", err)
+	}
+	response := toWebhookResponse(sub)
+	response.Secret = sub.Secret
+	return response, nil
+}
+
+func (humanService *UserService) GetWebhooks(ctx context.Context) ([]models.WebhookResponse, error) {
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	subs, err := humanService.ApiConfig.Humans.ListWebhookSubscriptions(ctx, tenantID)
+	if err != nil {
+		return nil, InternalError("failed to get webhooks", err)
+	}
+	response := make([]models.WebhookResponse, len(subs))
+	for i, sub := range subs {
+		response[i] = toWebhookResponse(sub)
+	}
+	return response, nil
+}
+
+func (humanService *UserService) GetWebhook(ctx context.Context, id string) (models.WebhookResponse, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return models.WebhookResponse{}, ValidationError("bad uuid", err)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.WebhookResponse{}, err
+	}
+	sub, err := getWebhook(ctx, humanService.ApiConfig.Humans, tenantID, uid)
+	if err != nil {
+		return models.WebhookResponse{}, err
+	}
+	return toWebhookResponse(sub), nil
+}
+
+// UpdateWebhook changes the fields set in req. Enabling a subscription
+// that was disabled after failures resets its failure count; deliveries
+// still pending resume.
+func (humanService *UserService) UpdateWebhook(ctx context.Context, id string, req *models.WebhookUpdateRequest) (models.WebhookResponse, error) {
+	if req == nil {
+		return models.WebhookResponse{}, ValidationError("bad request", nil)
+	}
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return models.WebhookResponse{}, ValidationError("bad uuid", err)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.WebhookResponse{}, err
+	}
+	if req.URL != nil {
+		if err := humanService.validateWebhookURL(ctx, *req.URL); err != nil {
+			return models.WebhookResponse{}, err
+		}
+	}
+	var eventTypes []string
+	if req.EventTypes != nil {
+		if eventTypes, err = normalizeWebhookEventTypes(*req.EventTypes); err != nil {
+			return models.WebhookResponse{}, err
+		}
+	}
+	if req.Secret != nil {
+		if err := validateWebhookSecret(*req.Secret); err != nil {
+			return models.WebhookResponse{}, err
+		}
+	}
+
+	var sub database.WebhookSubscription
+	err = humanService.inTx(ctx, TxOptions{Isolation: sql.LevelReadCommitted}, func(tx repository.Tx) error {
+		current, err := getWebhook(ctx, tx.Humans(), tenantID, uid)
+		if err != nil {
+			return err
+		}
+		params := database.UpdateWebhookSubscriptionParams{
+			TenantID:   tenantID,
+			ID:         uid,
+			Url:        current.Url,
+			EventTypes: current.EventTypes,
+			Secret:     current.Secret,
+			Enabled:    current.Enabled,
+		}
+		if req.URL != nil {
+			params.Url = *req.URL
+		}
+		if req.EventTypes != nil {
+			params.EventTypes = eventTypes
+		}
+		if req.Secret != nil {
+			params.Secret = *req.Secret
+		}
+		if req.Enabled != nil {
+			params.Enabled = *req.Enabled
+		}
+		sub, err = tx.Humans().UpdateWebhookSubscription(ctx, params)
+		if err != nil {
+			return storageError("error saving webhook", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.WebhookResponse{}, err
+	}
+	return toWebhookResponse(sub), nil
+}
+
+// DeleteWebhook removes the subscription together with its deliveries.
+func (humanService *UserService) DeleteWebhook(ctx context.Context, id string) (models.WebhookResponse, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return models.WebhookResponse{}, ValidationError("bad uuid", err)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.WebhookResponse{}, err
+	}
+	sub, err := humanService.ApiConfig.Humans.DeleteWebhookSubscription(ctx, database.DeleteWebhookSubscriptionParams{TenantID: tenantID, ID: uid})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WebhookResponse{}, NotFoundError("webhook does not exists")
+		}
+		return models.WebhookResponse{}, InternalError("failed to delete webhook", err)
+	}
+	return toWebhookResponse(sub), nil
+}
+
+// GetWebhookDeliveries returns the latest deliveries of a subscription,
+// optionally only those in status.
+func (humanService *UserService) GetWebhookDeliveries(ctx context.Context, id, status string, limit int) ([]models.WebhookDeliveryResponse, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ValidationError("bad uuid", err)
+	}
+	if status != "" && status != "pending" && status != "succeeded" && status != "failed" {
+		return nil, ValidationError(fmt.Sprintf("unknown status %q", status), nil)
+	}
+	if limit < 1 || limit > MaxWebhookDeliveryLimit {
+		return nil, ValidationError(fmt.Sprintf("limit must be between 1 and %d", MaxWebhookDeliveryLimit), nil)
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []database.WebhookDelivery
+	err = humanService.inTx(ctx, TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, func(tx repository.Tx) error {
+		if _, err := getWebhook(ctx, tx.Humans(), tenantID, uid); err != nil {
+			return err
+		}
+		var err error
+		deliveries, err = tx.Humans().ListWebhookDeliveries(ctx, database.ListWebhookDeliveriesParams{
+			TenantID:       tenantID,
+			SubscriptionID: uid,
+			Status:         status,
+			MaxRows:        int32(limit),
+		})
+		if err != nil {
+			return InternalError("failed to get webhook deliveries", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	response := make([]models.WebhookDeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		response[i] = toWebhookDeliveryResponse(delivery, nil)
+	}
+	return response, nil
+}
+
+// GetWebhookDelivery returns a delivery with the log of its attempts.
+func (humanService *UserService) GetWebhookDelivery(ctx context.Context, id, deliveryID string) (models.WebhookDeliveryResponse, error) {
+	uid, did, err := parseWebhookDeliveryIDs(id, deliveryID)
+	if err != nil {
+		return models.WebhookDeliveryResponse{}, err
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.WebhookDeliveryResponse{}, err
+	}
+
+	var response models.WebhookDeliveryResponse
+	err = humanService.inTx(ctx, TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, func(tx repository.Tx) error {
+		delivery, err := getWebhookDelivery(ctx, tx.Humans(), tenantID, uid, did)
+		if err != nil {
+			return err
+		}
+		attempts, err := tx.Humans().ListWebhookDeliveryAttempts(ctx, database.ListWebhookDeliveryAttemptsParams{TenantID: tenantID, DeliveryID: did})
+		if err != nil {
+			return InternalError("failed to get webhook delivery attempts", err)
+		}
+		response = toWebhookDeliveryResponse(delivery, attempts)
+		return nil
+	})
+	if err != nil {
+		return models.WebhookDeliveryResponse{}, err
+	}
+	return response, nil
+}
+
+// RedeliverWebhook schedules a delivery to be sent again right away, with
+// a fresh retry budget, whatever its status.
+func (humanService *UserService) RedeliverWebhook(ctx context.Context, id, deliveryID string) (models.WebhookDeliveryResponse, error) {
+	uid, did, err := parseWebhookDeliveryIDs(id, deliveryID)
+	if err != nil {
+		return models.WebhookDeliveryResponse{}, err
+	}
+	tenantID, err := tenantID(ctx)
+	if err != nil {
+		return models.WebhookDeliveryResponse{}, err
+	}
+	delivery, err := humanService.ApiConfig.Humans.RedeliverWebhookDelivery(ctx, database.RedeliverWebhookDeliveryParams{
+		TenantID:       tenantID,
+		SubscriptionID: uid,
+		ID:             did,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WebhookDeliveryResponse{}, NotFoundError("webhook delivery does not exists")
+		}
+		return models.WebhookDeliveryResponse{}, InternalError("failed to redeliver webhook", err)
+	}
+	return toWebhookDeliveryResponse(delivery, nil), nil
+}
+
+func getWebhook(ctx context.Context, repo repository.HumanRepository, tenantID string, id uuid.UUID) (database.WebhookSubscription, error) {
+	sub, err := repo.GetWebhookSubscription(ctx, database.GetWebhookSubscriptionParams{TenantID: tenantID, ID: id})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.WebhookSubscription{}, NotFoundError("webhook does not exists")
+		}
+		return database.WebhookSubscription{}, InternalError("failed to get webhook", err)
+	}
+	return sub, nil
+}
+
+func getWebhookDelivery(ctx context.Context, repo repository.HumanRepository, tenantID string, id, deliveryID uuid.UUID) (database.WebhookDelivery, error) {
+	delivery, err := repo.GetWebhookDelivery(ctx, database.GetWebhookDeliveryParams{TenantID: tenantID, SubscriptionID: id, ID: deliveryID})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.WebhookDelivery{}, NotFoundError("webhook delivery does not exists")
+		}
+		return database.WebhookDelivery{}, InternalError("failed to get webhook delivery", err)
+	}
+	return delivery, nil
+}
+
+func parseWebhookDeliveryIDs(id, deliveryID string) (uuid.UUID, uuid.UUID, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, ValidationError("bad uuid", err)
+	}
+	did, err := uuid.Parse(deliveryID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, ValidationError("bad delivery uuid", err)
+	}
+	return uid, did, nil
+}
+
+// validateWebhookURL also resolves the host of the URL, rejecting hosts
+// that point into private networks unless the configuration allows them.
+func (humanService *UserService) validateWebhookURL(ctx context.Context, raw string) error {
+	if raw == "" {
+		return ValidationError("url is required", nil)
+	}
+	if len(raw) > maxWebhookURLLength {
+		return ValidationError(fmt.Sprintf("url must be at most %d characters", maxWebhookURLLength), nil)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ValidationError("url must be an absolute http or https URL", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, webhookResolveTimeout)
+	defer cancel()
+	err = humanService.ApiConfig.WebhookTargets.CheckURL(ctx, raw)
+	if errors.Is(err, webhooks.ErrForbiddenTarget) {
+		return ValidationError("url must point to a public address", err)
+	}
+	if err != nil {
+		return ValidationError("url host cannot be resolved", err)
+	}
+	return nil
+}
+
+func validateWebhookSecret(secret string) error {
+	if len(secret) < minWebhookSecretLen || len(secret) > maxWebhookSecretLen {
+		return ValidationError(fmt.Sprintf("secret must be %d to %d characters", minWebhookSecretLen, maxWebhookSecretLen), nil)
+	}
+	return nil
+}
+
+// normalizeWebhookEventTypes checks and deduplicates the event filter.
+func normalizeWebhookEventTypes(eventTypes []string) ([]string, error) {
+	normalized := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		if !slices.Contains(webhookEventTypes, eventType) {
+			return nil, ValidationError(fmt.Sprintf("unknown event type %q", eventType), nil)
+		}
+		if !slices.Contains(normalized, eventType) {
+			normalized = append(normalized, eventType)
+		}
+	}
+	return normalized, nil
+}
+
+func webhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", InternalError("failed to generate webhook secret", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func toWebhookResponse(sub database.WebhookSubscription) models.WebhookResponse {
+	response := models.WebhookResponse{
+		ID:                  sub.ID.String(),
+		URL:                 sub.Url,
+		EventTypes:          sub.EventTypes,
+		Enabled:             sub.Enabled,
+		ConsecutiveFailures: int(sub.ConsecutiveFailures),
+		CreatedAt:           sub.CreatedAt.UTC(),
+		UpdatedAt:           sub.UpdatedAt.UTC(),
+	}
+	if response.EventTypes == nil {
+		response.EventTypes = []string{}
+	}
+	if sub.DisabledAt.Valid {
+		disabledAt := sub.DisabledAt.Time.UTC()
+		response.DisabledAt = &disabledAt
+	}
+	return response
+}
+
+func toWebhookDeliveryResponse(delivery database.WebhookDelivery, attempts []database.WebhookDeliveryAttempt) models.WebhookDeliveryResponse {
+	response := models.WebhookDeliveryResponse{
+		ID:        delivery.ID.String(),
+		EventID:   delivery.EventID.String(),
+		EventType: delivery.EventType,
+		Status:    delivery.Status,
+		Attempts:  int(delivery.Attempts),
+		Payload:   delivery.Payload,
+		CreatedAt: delivery.CreatedAt.UTC(),
+		UpdatedAt: delivery.UpdatedAt.UTC(),
+	}
+	if delivery.Status == "pending" {
+		nextAttemptAt := delivery.NextAttemptAt.UTC()
+		response.NextAttemptAt = &nextAttemptAt
+	}
+	if delivery.LastResponseStatus.Valid {
+		status := int(delivery.LastResponseStatus.Int32)
+		response.LastResponseStatus = &status
+	}
+	if delivery.LastError.Valid {
+		response.LastError = &delivery.LastError.String
+	}
+	for _, attempt := range attempts {
+		logEntry := models.WebhookAttemptResponse{
+			AttemptedAt: attempt.AttemptedAt.UTC(),
+			DurationMs:  int(attempt.DurationMs),
+		}
+		if attempt.ResponseStatus.Valid {
+			status := int(attempt.ResponseStatus.Int32)
+			logEntry.ResponseStatus = &status
+		}
+		if attempt.Error.Valid {
+			logEntry.Error = &attempt.Error.String
+		}
+		response.Log = append(response.Log, logEntry)
+	}
+	return response
+}
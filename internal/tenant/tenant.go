@@ -0,0 +1,30 @@
+package tenant
+
+import (
+	"context"
+	"regexp"
+)
+
+// Default is the tenant used when the deployment does not authenticate
+// tenants and the request names none.
+const Default = "default"
+
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+type contextKey struct{}
+
+// ValidID reports whether id can be used as a tenant identifier.
+func ValidID(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+// WithID returns a copy of ctx carrying the tenant id.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant id stored in ctx by WithID.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKey{}).(string)
+	return id, ok && id != ""
+}
@@ -0,0 +1,319 @@
+// Package webhooks sends scheduled webhook deliveries to subscribers.
+//
+// Every request carries the event as its body and these headers:
+//
+//	X-Webhook-ID         delivery id, the same on every retry
+//	X-Webhook-Event      event type
+//	X-Webhook-Timestamp  unix time the request was signed at
+//	X-Webhook-Signature  "sha256=" and the hex HMAC-SHA256 of
+//	                     "<timestamp>.<body>" keyed with the secret
+//
+// Receivers should recompute the signature, reject stale timestamps and
+// drop events whose id they have already seen: delivery is at least once.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/database"
+	"github.com/kiriksik/TestTaskEffectiveMobile/internal/repository"
+)
+
+const (
+	HeaderID        = "X-Webhook-ID"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	DefaultBatchSize     = 20
+	DefaultPollInterval  = time.Second
+	DefaultTimeout       = 10 * time.Second
+	DefaultMaxAttempts   = 8
+	DefaultRetryBase     = 30 * time.Second
+	DefaultMaxRetryDelay = time.Hour
+	DefaultDisableAfter  = 5
+	DefaultRetention     = 7 * 24 * time.Hour
+
+	cleanupInterval = time.Minute
+	maxErrorLength  = 1000
+)
+
+// Sign returns the X-Webhook-Signature value for a body signed at
+// timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher sends due deliveries. A failed delivery is retried after
+// RetryBase, doubling up to MaxRetryDelay, until MaxAttempts attempts were
+// made. A subscription whose deliveries ran out of attempts DisableAfter
+// times in a row is disabled. Several dispatchers may run against one
+// database.
+type Dispatcher struct {
+	transactor repository.Transactor
+	// Client connects only to addresses Targets allows.
+	Client        *http.Client
+	Targets       *TargetPolicy
+	BatchSize     int
+	PollInterval  time.Duration
+	MaxAttempts   int
+	RetryBase     time.Duration
+	MaxRetryDelay time.Duration
+	DisableAfter  int
+	// Retention is how long finished deliveries and their logs are kept.
+	Retention time.Duration
+}
+
+func NewDispatcher(transactor repository.Transactor) *Dispatcher {
+	d := &Dispatcher{
+		transactor:    transactor,
+		BatchSize:     DefaultBatchSize,
+		PollInterval:  DefaultPollInterval,
+		MaxAttempts:   DefaultMaxAttempts,
+		RetryBase:     DefaultRetryBase,
+		MaxRetryDelay: DefaultMaxRetryDelay,
+		DisableAfter:  DefaultDisableAfter,
+		Retention:     DefaultRetention,
+	}
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		// The address is checked after DNS resolution, so a host that
+		// resolves differently than when it was registered is caught.
+		Control: func(network, address string, conn syscall.RawConn) error {
+			return d.Targets.control(network, address, conn)
+		},
+	}
+	// Unlike the default transport it ignores proxy settings: a proxy
+	// would be dialed instead of the target and defeat the check.
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+	d.Client = &http.Client{
+		Transport: transport,
+		Timeout:   DefaultTimeout,
+		// A redirect is an answer of its own, not a success.
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	return d
+}
+
+// Run sends deliveries until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var lastCleanup time.Time
+	for {
+		wait := d.PollInterval
+		claimed, err := d.dispatchBatch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("webhooks: %s", err)
+		} else if claimed == d.BatchSize {
+			wait = 0
+		}
+
+		if time.Since(lastCleanup) >= cleanupInterval {
+			d.cleanup(ctx)
+			lastCleanup = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// dispatchBatch claims due deliveries, sends them concurrently and records
+// the outcomes. It returns how many deliveries were claimed.
+func (d *Dispatcher) dispatchBatch(ctx context.Context) (int, error) {
+	deliveries, err := d.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			outcome := d.send(ctx, delivery)
+			if err := d.record(ctx, delivery, outcome); err != nil {
+				log.Printf("webhooks: failed to record delivery %s: %s", delivery.ID, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+// claim leases due deliveries for longer than sending them may take, so a
+// dispatcher that dies mid-batch only delays them.
+func (d *Dispatcher) claim(ctx context.Context) ([]database.ClaimWebhookDeliveriesRow, error) {
+	tx, err := d.transactor.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	deliveries, err := tx.Webhooks().ClaimWebhookDeliveries(ctx, database.ClaimWebhookDeliveriesParams{
+		LeaseUntil: time.Now().Add(d.Client.Timeout + time.Minute),
+		BatchSize:  int32(d.BatchSize),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, tx.Commit()
+}
+
+type outcome struct {
+	status   int
+	err      error
+	duration time.Duration
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery database.ClaimWebhookDeliveriesRow) outcome {
+	start := time.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return outcome{err: err}
+	}
+	timestamp := start.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "humans-webhooks/1.0")
+	req.Header.Set(HeaderID, delivery.ID.String())
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return outcome{err: err, duration: time.Since(start)}
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	result := outcome{status: resp.StatusCode, duration: time.Since(start)}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		result.err = fmt.Errorf("subscriber responded with %s", resp.Status)
+	}
+	return result
+}
+
+func (d *Dispatcher) record(ctx context.Context, delivery database.ClaimWebhookDeliveriesRow, result outcome) error {
+	var responseStatus sql.NullInt32
+	if result.status != 0 {
+		responseStatus = sql.NullInt32{Int32: int32(result.status), Valid: true}
+	}
+	var lastError sql.NullString
+	if result.err != nil {
+		message := result.err.Error()
+		if len(message) > maxErrorLength {
+			message = message[:maxErrorLength]
+		}
+		lastError = sql.NullString{String: message, Valid: true}
+	}
+
+	attempts := int(delivery.Attempts) + 1
+	update := database.UpdateWebhookDeliveryResultParams{
+		ID:                 delivery.ID,
+		LastResponseStatus: responseStatus,
+		LastError:          lastError,
+		NextAttemptAt:      time.Now(),
+	}
+	switch {
+	case result.err == nil:
+		update.Status = "succeeded"
+	case attempts >= d.MaxAttempts:
+		update.Status = "failed"
+	default:
+		update.Status = "pending"
+		update.NextAttemptAt = time.Now().Add(d.retryDelay(attempts))
+	}
+
+	tx, err := d.transactor.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	err = tx.Webhooks().CreateWebhookDeliveryAttempt(ctx, database.CreateWebhookDeliveryAttemptParams{
+		DeliveryID:     delivery.ID,
+		ResponseStatus: responseStatus,
+		Error:          lastError,
+		DurationMs:     int32(result.duration.Milliseconds()),
+	})
+	if err != nil {
+		return err
+	}
+	if err := tx.Webhooks().UpdateWebhookDeliveryResult(ctx, update); err != nil {
+		return err
+	}
+	switch update.Status {
+	case "succeeded":
+		err = tx.Webhooks().ResetWebhookSubscriptionFailures(ctx, delivery.SubscriptionID)
+	case "failed":
+		var sub database.WebhookSubscription
+		sub, err = tx.Webhooks().RecordWebhookSubscriptionFailure(ctx, database.RecordWebhookSubscriptionFailureParams{
+			ID:          delivery.SubscriptionID,
+			MaxFailures: int32(d.DisableAfter),
+		})
+		if err == nil && !sub.Enabled && int(sub.ConsecutiveFailures) == d.DisableAfter {
+			log.Printf("webhooks: disabled subscription %s after %d failed deliveries", sub.ID, sub.ConsecutiveFailures)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// retryDelay is the wait before the next attempt after attempts failed
+// ones.
+func (d *Dispatcher) retryDelay(attempts int) time.Duration {
+	delay := d.RetryBase
+	for i := 1; i < attempts && delay < d.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, d.MaxRetryDelay)
+}
+
+func (d *Dispatcher) cleanup(ctx context.Context) {
+	tx, err := d.transactor.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		log.Printf("webhooks: cleanup failed: %s", err)
+		return
+	}
+	defer tx.Rollback()
+	deleted, err := tx.Webhooks().DeleteFinishedWebhookDeliveries(ctx, time.Now().Add(-d.Retention))
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		log.Printf("webhooks: cleanup failed: %s", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("webhooks: deleted %d finished deliveries", deleted)
+	}
+}
@@ -0,0 +1,114 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"syscall"
+)
+
+// ErrForbiddenTarget is returned for webhook URLs that resolve to an
+// address webhooks may not be sent to.
+var ErrForbiddenTarget = errors.New("webhook target is not a public address")
+
+// reservedPrefixes are the ranges beyond loopback, private, link-local,
+// multicast and unspecified addresses that are not reachable on the
+// public internet, or that reach IPv4 ranges through IPv6.
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+}
+
+// TargetPolicy decides which addresses webhooks may be sent to. Webhook
+// URLs are chosen by tenants, so by default only public addresses are
+// allowed: otherwise a subscription could make the service probe its own
+// network, such as the cloud metadata endpoint at 169.254.169.254. A nil
+// policy is the default one.
+type TargetPolicy struct {
+	// AllowPrivateNetworks also allows loopback, private and link-local
+	// addresses, for development against local receivers.
+	AllowPrivateNetworks bool
+	// Resolver resolves webhook hosts; nil uses net.DefaultResolver.
+	Resolver *net.Resolver
+}
+
+// Allowed reports whether webhooks may be sent to addr.
+func (p *TargetPolicy) Allowed(addr netip.Addr) bool {
+	if p != nil && p.AllowPrivateNetworks {
+		return true
+	}
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL resolves the host of a webhook URL and fails with
+// ErrForbiddenTarget when any of its addresses is not allowed. Since DNS
+// answers may change, the dispatcher checks the address it connects to
+// again.
+func (p *TargetPolicy) CheckURL(ctx context.Context, rawURL string) error {
+	if p != nil && p.AllowPrivateNetworks {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := u.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !p.Allowed(addr) {
+			return ErrForbiddenTarget
+		}
+		return nil
+	}
+	resolver := net.DefaultResolver
+	if p != nil && p.Resolver != nil {
+		resolver = p.Resolver
+	}
+	addrs, err := resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if !p.Allowed(addr) {
+			return ErrForbiddenTarget
+		}
+	}
+	return nil
+}
+
+// control is a net.Dialer Control function refusing connections to
+// addresses the policy does not allow.
+func (p *TargetPolicy) control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !p.Allowed(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenTarget, addrPort.Addr())
+	}
+	return nil
+}
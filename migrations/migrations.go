@@ -0,0 +1,88 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+//go:embed schemas/*.sql
+var embedded embed.FS
+
+// Commands lists the supported migrate subcommands.
+var Commands = []string{"up", "down", "status", "redo"}
+
+// NewProvider returns a goose provider over the embedded schema migrations.
+// Every operation holds a Postgres advisory lock, so concurrent replicas
+// apply migrations one at a time.
+func NewProvider(db *sql.DB) (*goose.Provider, error) {
+	schemas, err := fs.Sub(embedded, "schemas")
+	if err != nil {
+		return nil, err
+	}
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, err
+	}
+	return goose.NewProvider(goose.DialectPostgres, db, schemas, goose.WithSessionLocker(locker))
+}
+
+// Run executes a migrate subcommand and writes its report to out.
+func Run(ctx context.Context, db *sql.DB, command string, out io.Writer) error {
+	provider, err := NewProvider(db)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	switch command {
+	case "up":
+		results, err := provider.Up(ctx)
+		for _, result := range results {
+			fmt.Fprintln(out, result)
+		}
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			fmt.Fprintln(out, "no migrations to apply")
+		}
+	case "down":
+		result, err := provider.Down(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, result)
+	case "redo":
+		result, err := provider.Down(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, result)
+		result, err = provider.UpByOne(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, result)
+	case "status":
+		statuses, err := provider.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.State == goose.StateApplied {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(out, "%-20s %s\n", appliedAt, status.Source.Path)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected one of %v", command, Commands)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+-- name: ListAttributeDefinitions :many
+SELECT * FROM attribute_definitions
+WHERE tenant_id = $1
+ORDER BY name;
+
+-- name: UpsertAttributeDefinition :one
+INSERT INTO attribute_definitions (tenant_id, name, type, required, enum_values, pattern, max_length, minimum, maximum, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+ON CONFLICT (tenant_id, name) DO UPDATE
+SET type = EXCLUDED.type,
+    required = EXCLUDED.required,
+    enum_values = EXCLUDED.enum_values,
+    pattern = EXCLUDED.pattern,
+    max_length = EXCLUDED.max_length,
+    minimum = EXCLUDED.minimum,
+    maximum = EXCLUDED.maximum,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteAttributeDefinition :one
+DELETE FROM attribute_definitions
+WHERE tenant_id = $1 AND name = $2
+RETURNING *;
+
+-- name: RemoveHumanAttribute :exec
+UPDATE humans
+SET attributes = attributes - sqlc.arg('name')::text
+WHERE tenant_id = sqlc.arg('tenant_id') AND attributes ? sqlc.arg('name')::text;
@@ -0,0 +1,55 @@
+-- name: CreateContact :one
+INSERT INTO human_contacts (
+    id, tenant_id, human_id, type, value, is_primary,
+    street, city, region, postal_code, country, created_at, updated_at
+)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    CURRENT_TIMESTAMP,
+    CURRENT_TIMESTAMP
+) RETURNING *;
+
+-- name: GetContact :one
+SELECT * FROM human_contacts
+WHERE tenant_id = $1 AND human_id = $2 AND id = $3;
+
+-- name: ListContacts :many
+SELECT * FROM human_contacts
+WHERE tenant_id = $1 AND human_id = $2
+ORDER BY type, is_primary DESC, created_at, id;
+
+-- name: ListContactsByHumanIDs :many
+SELECT * FROM human_contacts
+WHERE tenant_id = sqlc.arg('tenant_id') AND human_id = ANY(sqlc.arg('human_ids')::uuid[])
+ORDER BY human_id, type, is_primary DESC, created_at, id;
+
+-- name: UpdateContact :one
+UPDATE human_contacts
+SET type = $4, value = $5, is_primary = $6, street = $7, city = $8, region = $9,
+    postal_code = $10, country = $11, updated_at = CURRENT_TIMESTAMP
+WHERE tenant_id = $1 AND human_id = $2 AND id = $3
+RETURNING *;
+
+-- name: DeleteContact :one
+DELETE FROM human_contacts
+WHERE tenant_id = $1 AND human_id = $2 AND id = $3
+RETURNING *;
+
+-- name: ClearPrimaryContact :exec
+UPDATE human_contacts
+SET is_primary = false, updated_at = CURRENT_TIMESTAMP
+WHERE tenant_id = $1 AND human_id = $2 AND type = $3 AND is_primary AND id <> $4;
+
+-- name: DeleteContactsOfHuman :exec
+DELETE FROM human_contacts
+WHERE tenant_id = $1 AND human_id = $2;
@@ -0,0 +1,57 @@
+-- name: ListDuplicateCandidates :many
+-- Pairs whose full names pass the pg_trgm similarity threshold, each pair
+-- once with the smaller id first. Encrypted rows are compared in process
+-- instead.
+SELECT
+    a.id AS human_id,
+    b.id AS candidate_id,
+    similarity(lower(a.name || ' ' || a.surname), lower(b.name || ' ' || b.surname))::float8 AS name_similarity
+FROM humans a
+JOIN humans b ON b.tenant_id = a.tenant_id AND b.id > a.id
+WHERE a.tenant_id = sqlc.arg('tenant_id')
+    AND a.pii_key_id IS NULL AND b.pii_key_id IS NULL
+    AND lower(a.name || ' ' || a.surname) % lower(b.name || ' ' || b.surname)
+ORDER BY name_similarity DESC, a.id, b.id
+LIMIT sqlc.arg('max_pairs');
+
+-- name: MoveContacts :exec
+-- Contacts the target already has stay behind and go away with the source.
+-- A moved contact stays primary only if the target has no primary of its
+-- type.
+UPDATE human_contacts c
+SET human_id = sqlc.arg('target_id'),
+    is_primary = c.is_primary AND NOT EXISTS (
+        SELECT 1 FROM human_contacts t
+        WHERE t.tenant_id = c.tenant_id AND t.human_id = sqlc.arg('target_id')
+            AND t.type = c.type AND t.is_primary
+    ),
+    updated_at = CURRENT_TIMESTAMP
+WHERE c.tenant_id = sqlc.arg('tenant_id') AND c.human_id = sqlc.arg('source_id')
+    AND NOT EXISTS (
+        SELECT 1 FROM human_contacts t
+        WHERE t.tenant_id = c.tenant_id AND t.human_id = sqlc.arg('target_id')
+            AND t.type = c.type AND t.value = c.value
+    );
+
+-- name: CopyHumanTags :exec
+INSERT INTO human_tags (tenant_id, human_id, tag_id, created_at)
+SELECT human_tags.tenant_id, sqlc.arg('target_id'), human_tags.tag_id, human_tags.created_at
+FROM human_tags
+WHERE human_tags.tenant_id = sqlc.arg('tenant_id') AND human_tags.human_id = sqlc.arg('source_id')
+ON CONFLICT DO NOTHING;
+
+-- name: MoveRelationships :exec
+-- Links between the source and the target, and links the target already
+-- has, stay behind and go away with the source.
+UPDATE human_relationships r
+SET human_id = CASE WHEN r.human_id = sqlc.arg('source_id') THEN sqlc.arg('target_id') ELSE r.human_id END,
+    relative_id = CASE WHEN r.relative_id = sqlc.arg('source_id') THEN sqlc.arg('target_id') ELSE r.relative_id END
+WHERE r.tenant_id = sqlc.arg('tenant_id')
+    AND (r.human_id = sqlc.arg('source_id') OR r.relative_id = sqlc.arg('source_id'))
+    AND r.human_id <> sqlc.arg('target_id') AND r.relative_id <> sqlc.arg('target_id')
+    AND NOT EXISTS (
+        SELECT 1 FROM human_relationships t
+        WHERE t.tenant_id = r.tenant_id AND t.relation = r.relation
+            AND t.human_id = CASE WHEN r.human_id = sqlc.arg('source_id') THEN sqlc.arg('target_id') ELSE r.human_id END
+            AND t.relative_id = CASE WHEN r.relative_id = sqlc.arg('source_id') THEN sqlc.arg('target_id') ELSE r.relative_id END
+    );
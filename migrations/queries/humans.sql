@@ -1,5 +1,8 @@
 -- name: CreateHuman :one
-INSERT INTO humans (id, name, surname, patronymic, age, gender, country, created_at)
+INSERT INTO humans (
+    id, tenant_id, name, surname, patronymic, age, gender, country, attributes,
+    name_index, pii_key_id, pii_dek, created_at, updated_at
+)
 VALUES (
     gen_random_uuid(),
     $1,
@@ -8,25 +11,86 @@ VALUES (
     $4,
     $5,
     $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11,
+    CURRENT_TIMESTAMP,
     CURRENT_TIMESTAMP
 ) RETURNING *;
 
 -- name: GetHumanByID :one
-SELECT * FROM humans 
-WHERE id = $1;
+SELECT * FROM humans
+WHERE tenant_id = $1 AND id = $2;
 
 
--- name: GetHumans :many
-SELECT * FROM humans;
+-- name: ListHumans :many
+SELECT * FROM humans
+WHERE humans.tenant_id = sqlc.arg('tenant_id')
+    AND (sqlc.narg('created_since')::timestamptz IS NULL OR humans.created_at >= sqlc.narg('created_since'))
+    AND (sqlc.narg('created_until')::timestamptz IS NULL OR humans.created_at < sqlc.narg('created_until'))
+    AND (sqlc.narg('updated_since')::timestamptz IS NULL OR humans.updated_at >= sqlc.narg('updated_since'))
+    AND (sqlc.narg('updated_until')::timestamptz IS NULL OR humans.updated_at < sqlc.narg('updated_until'))
+    AND human_tags_match(humans.tenant_id, humans.id, sqlc.arg('tags')::text[], sqlc.arg('match_all_tags')::bool)
+    AND humans.attributes @> sqlc.arg('attributes')::jsonb
+ORDER BY
+    CASE WHEN sqlc.arg('sort')::text = 'created_at' THEN humans.created_at END ASC,
+    CASE WHEN sqlc.arg('sort')::text = '-created_at' THEN humans.created_at END DESC,
+    CASE WHEN sqlc.arg('sort')::text = 'updated_at' THEN humans.updated_at END ASC,
+    CASE WHEN sqlc.arg('sort')::text = '-updated_at' THEN humans.updated_at END DESC,
+    humans.id;
 
 -- name: UpdateHuman :one
 UPDATE humans
-SET name = $2, surname = $3, patronymic = $4, age = $5, gender = $6, country = $7
-WHERE id = $1
+SET name = $3, surname = $4, patronymic = $5, age = $6, gender = $7, country = $8, attributes = $9,
+    name_index = $10, pii_key_id = $11, pii_dek = $12, updated_at = CURRENT_TIMESTAMP
+WHERE tenant_id = $1 AND id = $2
 RETURNING *;
 
 
 -- name: DeleteHuman :one
 DELETE FROM humans
-WHERE id = $1
-RETURNING *;
\ No newline at end of file
+WHERE tenant_id = $1 AND id = $2
+RETURNING *;
+
+-- name: CountHumansByGender :many
+SELECT humans.gender, count(*) AS total FROM humans
+WHERE humans.tenant_id = sqlc.arg('tenant_id')
+    AND (sqlc.narg('created_since')::timestamptz IS NULL OR humans.created_at >= sqlc.narg('created_since'))
+    AND (sqlc.narg('created_until')::timestamptz IS NULL OR humans.created_at < sqlc.narg('created_until'))
+    AND (sqlc.narg('updated_since')::timestamptz IS NULL OR humans.updated_at >= sqlc.narg('updated_since'))
+    AND (sqlc.narg('updated_until')::timestamptz IS NULL OR humans.updated_at < sqlc.narg('updated_until'))
+    AND human_tags_match(humans.tenant_id, humans.id, sqlc.arg('tags')::text[], sqlc.arg('match_all_tags')::bool)
+    AND humans.attributes @> sqlc.arg('attributes')::jsonb
+GROUP BY humans.gender
+ORDER BY humans.gender;
+
+-- name: CountHumansByCountry :many
+SELECT humans.country, count(*) AS total, avg(humans.age)::float8 AS average_age FROM humans
+WHERE humans.tenant_id = sqlc.arg('tenant_id')
+    AND (sqlc.narg('created_since')::timestamptz IS NULL OR humans.created_at >= sqlc.narg('created_since'))
+    AND (sqlc.narg('created_until')::timestamptz IS NULL OR humans.created_at < sqlc.narg('created_until'))
+    AND (sqlc.narg('updated_since')::timestamptz IS NULL OR humans.updated_at >= sqlc.narg('updated_since'))
+    AND (sqlc.narg('updated_until')::timestamptz IS NULL OR humans.updated_at < sqlc.narg('updated_until'))
+    AND human_tags_match(humans.tenant_id, humans.id, sqlc.arg('tags')::text[], sqlc.arg('match_all_tags')::bool)
+    AND humans.attributes @> sqlc.arg('attributes')::jsonb
+GROUP BY humans.country
+ORDER BY total DESC, humans.country;
+
+-- name: CountHumansByAgeBucket :many
+SELECT ((humans.age / sqlc.arg('bucket_width')::int) * sqlc.arg('bucket_width')::int)::int AS bucket_start, count(*) AS total FROM humans
+WHERE humans.tenant_id = sqlc.arg('tenant_id')
+    AND (sqlc.narg('created_since')::timestamptz IS NULL OR humans.created_at >= sqlc.narg('created_since'))
+    AND (sqlc.narg('created_until')::timestamptz IS NULL OR humans.created_at < sqlc.narg('created_until'))
+    AND (sqlc.narg('updated_since')::timestamptz IS NULL OR humans.updated_at >= sqlc.narg('updated_since'))
+    AND (sqlc.narg('updated_until')::timestamptz IS NULL OR humans.updated_at < sqlc.narg('updated_until'))
+    AND human_tags_match(humans.tenant_id, humans.id, sqlc.arg('tags')::text[], sqlc.arg('match_all_tags')::bool)
+    AND humans.attributes @> sqlc.arg('attributes')::jsonb
+GROUP BY bucket_start
+ORDER BY bucket_start;
+
+-- name: ListHumansByIDs :many
+SELECT * FROM humans
+WHERE tenant_id = sqlc.arg('tenant_id') AND id = ANY(sqlc.arg('ids')::uuid[])
+ORDER BY id;
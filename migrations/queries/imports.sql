@@ -0,0 +1,36 @@
+-- name: CreateImportJob :one
+INSERT INTO import_jobs (id, tenant_id, format, status, total_rows, created_at, updated_at)
+VALUES (gen_random_uuid(), $1, $2, 'pending', $3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+RETURNING *;
+
+-- name: GetImportJob :one
+SELECT * FROM import_jobs
+WHERE tenant_id = $1 AND id = $2;
+
+-- name: UpdateImportJobProgress :one
+-- Finished jobs are left alone, so a job failed as stale stays failed.
+UPDATE import_jobs
+SET status = $3,
+    processed_rows = $4,
+    created_rows = $5,
+    failed_rows = $6,
+    finished_at = $7,
+    updated_at = CURRENT_TIMESTAMP
+WHERE tenant_id = $1 AND id = $2 AND status IN ('pending', 'running')
+RETURNING *;
+
+-- name: CreateImportError :exec
+INSERT INTO import_job_errors (tenant_id, job_id, row_number, code, message, fields)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: ListImportErrors :many
+SELECT * FROM import_job_errors
+WHERE tenant_id = $1 AND job_id = $2
+ORDER BY row_number;
+
+-- name: FailStaleImportJobs :execrows
+-- Jobs of every tenant that stopped saving progress, because the process
+-- running them exited.
+UPDATE import_jobs
+SET status = 'failed', finished_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE status IN ('pending', 'running') AND updated_at < sqlc.arg('updated_before')::timestamptz;
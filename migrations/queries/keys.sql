@@ -0,0 +1,49 @@
+-- name: ListHumansForKeyRotation :many
+-- Rows of every tenant not yet under the current key, in id order from
+-- after. They stay locked until the batch commits.
+SELECT * FROM humans
+WHERE id > sqlc.arg('after') AND (pii_key_id IS NULL OR pii_key_id <> sqlc.arg('current_key_id')::text)
+ORDER BY id
+LIMIT sqlc.arg('batch_size')
+FOR UPDATE;
+
+-- name: UpdateHumanPII :exec
+UPDATE humans
+SET name = $2, surname = $3, patronymic = $4, name_index = $5, pii_key_id = $6, pii_dek = $7
+WHERE id = $1;
+
+-- name: ListUnindexedHumans :many
+-- Plaintext rows of every tenant without a name index, in id order from
+-- after. They stay locked until the batch commits.
+SELECT * FROM humans
+WHERE id > sqlc.arg('after') AND pii_key_id IS NULL AND name_index IS NULL
+ORDER BY id
+LIMIT sqlc.arg('batch_size')
+FOR UPDATE;
+
+-- name: SetHumanNameIndex :exec
+UPDATE humans SET name_index = $2 WHERE id = $1;
+
+-- name: ListOutboxEventsForKeyRotation :many
+-- Events of every tenant whose payload is not sealed under the current
+-- key, in id order from after. They stay locked until the batch commits.
+SELECT * FROM outbox
+WHERE id > sqlc.arg('after') AND (payload->>'pii_key_id') IS DISTINCT FROM sqlc.arg('current_key_id')::text
+ORDER BY id
+LIMIT sqlc.arg('batch_size')
+FOR UPDATE;
+
+-- name: UpdateOutboxEventPayload :exec
+UPDATE outbox SET payload = $2 WHERE id = $1;
+
+-- name: ListWebhookDeliveriesForKeyRotation :many
+-- Deliveries of every tenant whose payload is not sealed under the current
+-- key, in id order from after. They stay locked until the batch commits.
+SELECT * FROM webhook_deliveries
+WHERE id > sqlc.arg('after') AND (payload->>'pii_key_id') IS DISTINCT FROM sqlc.arg('current_key_id')::text
+ORDER BY id
+LIMIT sqlc.arg('batch_size')
+FOR UPDATE;
+
+-- name: UpdateWebhookDeliveryPayload :exec
+UPDATE webhook_deliveries SET payload = $2 WHERE id = $1;
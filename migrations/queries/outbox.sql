@@ -0,0 +1,60 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox (event_id, tenant_id, human_id, event_type, payload, created_at)
+VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+RETURNING *;
+
+-- name: ClaimOutboxEvents :many
+-- The oldest undelivered events of every tenant. Rows claimed by another
+-- relay are skipped, claimed rows stay locked until the transaction ends.
+SELECT * FROM outbox
+WHERE delivered_at IS NULL
+ORDER BY id
+LIMIT sqlc.arg('batch_size')
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxEventDelivered :exec
+UPDATE outbox
+SET attempts = attempts + 1, last_error = NULL, delivered_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id');
+
+-- name: RecordOutboxFailure :exec
+UPDATE outbox
+SET attempts = attempts + 1, last_error = sqlc.arg('last_error')::text
+WHERE id = sqlc.arg('id');
+
+-- name: DeleteDeliveredOutboxEvents :execrows
+DELETE FROM outbox
+WHERE delivered_at < sqlc.arg('delivered_before')::timestamptz;
+
+-- name: GetOutboxEvent :one
+SELECT * FROM outbox
+WHERE id = $1;
+
+-- name: ListOutboxEventsAfter :many
+SELECT * FROM outbox
+WHERE id > sqlc.arg('after')
+ORDER BY id
+LIMIT sqlc.arg('max_rows');
+
+-- name: DeleteOutboxEventsCreatedBefore :execrows
+-- Used when no sink publishes events: they are only kept for the event
+-- stream and expire whether delivered or not.
+DELETE FROM outbox
+WHERE created_at < sqlc.arg('created_before')::timestamptz;
+
+-- name: ListOutboxEventsOfHuman :many
+SELECT * FROM outbox
+WHERE tenant_id = $1 AND human_id = $2
+ORDER BY id;
+
+-- name: DeleteOutboxEventsOfHuman :execrows
+DELETE FROM outbox
+WHERE tenant_id = $1 AND human_id = $2;
+
+-- name: NotifyHumanErased :exec
+-- Tells the API replicas, once the transaction commits, to drop the
+-- human's events from their replay buffers.
+SELECT pg_notify('human_erasures', json_build_object(
+    'tenant_id', sqlc.arg(tenant_id)::text,
+    'human_id', sqlc.arg(human_id)::uuid
+)::text);
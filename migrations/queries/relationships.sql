@@ -0,0 +1,70 @@
+-- name: CreateRelationship :one
+INSERT INTO human_relationships (id, tenant_id, human_id, relative_id, relation, created_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    CURRENT_TIMESTAMP
+) RETURNING *;
+
+-- name: DeleteRelationship :one
+DELETE FROM human_relationships
+WHERE tenant_id = $1 AND human_id = $2 AND relative_id = $3 AND relation = $4
+RETURNING *;
+
+-- name: ListRelatives :many
+SELECT sqlc.embed(human_relationships), sqlc.embed(humans)
+FROM human_relationships
+JOIN humans ON humans.tenant_id = human_relationships.tenant_id
+    AND humans.id = human_relationships.relative_id
+WHERE human_relationships.tenant_id = $1 AND human_relationships.human_id = $2
+ORDER BY human_relationships.created_at, human_relationships.id;
+
+-- name: ListAncestorIDs :many
+WITH RECURSIVE parent_links AS (
+    SELECT r.human_id AS child_id, r.relative_id AS parent_id
+    FROM human_relationships r
+    WHERE r.tenant_id = sqlc.arg('tenant_id')::text AND r.relation = 'parent'
+    UNION
+    SELECT r.relative_id AS child_id, r.human_id AS parent_id
+    FROM human_relationships r
+    WHERE r.tenant_id = sqlc.arg('tenant_id')::text AND r.relation = 'child'
+), ancestors AS (
+    SELECT parent_id FROM parent_links WHERE child_id = sqlc.arg('human_id')::uuid
+    UNION
+    SELECT parent_links.parent_id
+    FROM parent_links
+    JOIN ancestors ON parent_links.child_id = ancestors.parent_id
+)
+SELECT parent_id::uuid AS ancestor_id FROM ancestors;
+
+-- name: WalkFamilyTree :many
+WITH RECURSIVE tree AS (
+    SELECT sqlc.arg('human_id')::uuid AS human_id, 0 AS depth
+    UNION
+    SELECT
+        CASE WHEN r.human_id = tree.human_id THEN r.relative_id ELSE r.human_id END,
+        tree.depth + 1
+    FROM human_relationships r
+    JOIN tree ON r.human_id = tree.human_id OR r.relative_id = tree.human_id
+    WHERE r.tenant_id = sqlc.arg('tenant_id')::text AND tree.depth < sqlc.arg('max_depth')::int
+)
+SELECT tree.human_id::uuid AS human_id, min(tree.depth)::int AS depth
+FROM tree
+GROUP BY tree.human_id
+ORDER BY depth, tree.human_id;
+
+-- name: ListRelationshipsAmong :many
+SELECT * FROM human_relationships
+WHERE tenant_id = sqlc.arg('tenant_id')
+    AND human_id = ANY(sqlc.arg('ids')::uuid[])
+    AND relative_id = ANY(sqlc.arg('ids')::uuid[])
+ORDER BY created_at, id;
+
+-- name: ListRelationshipsOfHuman :many
+SELECT * FROM human_relationships
+WHERE tenant_id = sqlc.arg('tenant_id')
+    AND (human_id = sqlc.arg('human_id') OR relative_id = sqlc.arg('human_id'))
+ORDER BY created_at, id;
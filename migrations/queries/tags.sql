@@ -0,0 +1,26 @@
+-- name: UpsertTag :one
+INSERT INTO tags (id, tenant_id, name, created_at)
+VALUES (gen_random_uuid(), $1, $2, CURRENT_TIMESTAMP)
+ON CONFLICT (tenant_id, name) DO UPDATE SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: AttachTag :exec
+INSERT INTO human_tags (tenant_id, human_id, tag_id, created_at)
+VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+ON CONFLICT DO NOTHING;
+
+-- name: DetachTag :execrows
+DELETE FROM human_tags
+USING tags
+WHERE human_tags.tenant_id = $1
+    AND human_tags.human_id = $2
+    AND tags.tenant_id = human_tags.tenant_id
+    AND tags.id = human_tags.tag_id
+    AND tags.name = $3;
+
+-- name: ListTagsByHumanIDs :many
+SELECT human_tags.human_id, tags.name
+FROM human_tags
+JOIN tags ON tags.tenant_id = human_tags.tenant_id AND tags.id = human_tags.tag_id
+WHERE human_tags.tenant_id = sqlc.arg('tenant_id') AND human_tags.human_id = ANY(sqlc.arg('human_ids')::uuid[])
+ORDER BY human_tags.human_id, tags.name;
@@ -0,0 +1,142 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (id, tenant_id, url, event_types, secret, created_at, updated_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+RETURNING *;
+
+-- name: GetWebhookSubscription :one
+SELECT * FROM webhook_subscriptions
+WHERE tenant_id = $1 AND id = $2;
+
+-- name: ListWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE tenant_id = $1
+ORDER BY created_at, id;
+
+-- name: UpdateWebhookSubscription :one
+-- Enabling a disabled subscription clears its failure count.
+UPDATE webhook_subscriptions
+SET url = $3,
+    event_types = $4,
+    secret = $5,
+    enabled = sqlc.arg('enabled'),
+    consecutive_failures = CASE WHEN sqlc.arg('enabled') THEN 0 ELSE consecutive_failures END,
+    disabled_at = CASE WHEN sqlc.arg('enabled') THEN NULL ELSE COALESCE(disabled_at, CURRENT_TIMESTAMP) END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE tenant_id = $1 AND id = $2
+RETURNING *;
+
+-- name: DeleteWebhookSubscription :one
+DELETE FROM webhook_subscriptions
+WHERE tenant_id = $1 AND id = $2
+RETURNING *;
+
+-- name: ListWebhookSubscriptionsForEvent :many
+-- Enabled subscriptions of the tenant whose filter accepts the event type.
+-- An empty filter accepts every type.
+SELECT * FROM webhook_subscriptions
+WHERE tenant_id = $1
+    AND enabled
+    AND (cardinality(event_types) = 0 OR sqlc.arg('event_type')::text = ANY(event_types))
+ORDER BY created_at, id;
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (id, tenant_id, subscription_id, event_id, human_id, event_type, payload, created_at, updated_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+ON CONFLICT (subscription_id, event_id) DO NOTHING;
+
+-- name: ListWebhookDeliveriesOfHuman :many
+SELECT * FROM webhook_deliveries
+WHERE tenant_id = $1 AND human_id = $2
+ORDER BY created_at, id;
+
+-- name: DeleteWebhookDeliveriesOfHuman :execrows
+-- Pending and finished deliveries alike, with their attempt logs.
+DELETE FROM webhook_deliveries
+WHERE tenant_id = $1 AND human_id = $2;
+
+-- name: ListWebhookDeliveries :many
+-- The latest deliveries of a subscription, optionally only those in status.
+SELECT * FROM webhook_deliveries
+WHERE tenant_id = $1
+    AND subscription_id = $2
+    AND (sqlc.arg('status')::text = '' OR status = sqlc.arg('status')::text)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('max_rows');
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE tenant_id = $1 AND subscription_id = $2 AND id = $3;
+
+-- name: ListWebhookDeliveryAttempts :many
+SELECT webhook_delivery_attempts.* FROM webhook_delivery_attempts
+JOIN webhook_deliveries ON webhook_deliveries.id = webhook_delivery_attempts.delivery_id
+WHERE webhook_deliveries.tenant_id = $1 AND webhook_delivery_attempts.delivery_id = $2
+ORDER BY webhook_delivery_attempts.id;
+
+-- name: RedeliverWebhookDelivery :one
+-- Schedules the delivery again right away with a fresh retry budget. Its
+-- attempt log is kept.
+UPDATE webhook_deliveries
+SET status = 'pending',
+    attempts = 0,
+    next_attempt_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE tenant_id = $1 AND subscription_id = $2 AND id = $3
+RETURNING *;
+
+-- name: ClaimWebhookDeliveries :many
+-- Due deliveries of enabled subscriptions, across tenants. Claimed rows are
+-- leased by moving their next attempt to lease_until, so other dispatchers
+-- skip them while the request is in flight.
+UPDATE webhook_deliveries
+SET next_attempt_at = sqlc.arg('lease_until')::timestamptz
+FROM webhook_subscriptions
+WHERE webhook_subscriptions.id = webhook_deliveries.subscription_id
+    AND webhook_deliveries.id IN (
+        SELECT d.id FROM webhook_deliveries d
+        JOIN webhook_subscriptions s ON s.id = d.subscription_id
+        WHERE d.status = 'pending' AND d.next_attempt_at <= CURRENT_TIMESTAMP AND s.enabled
+        ORDER BY d.next_attempt_at
+        LIMIT sqlc.arg('batch_size')
+        FOR UPDATE OF d SKIP LOCKED
+    )
+RETURNING webhook_deliveries.id, webhook_deliveries.tenant_id, webhook_deliveries.subscription_id, webhook_deliveries.event_id,
+    webhook_deliveries.event_type, webhook_deliveries.payload, webhook_deliveries.attempts,
+    webhook_subscriptions.url, webhook_subscriptions.secret;
+
+-- name: CreateWebhookDeliveryAttempt :exec
+INSERT INTO webhook_delivery_attempts (delivery_id, response_status, error, duration_ms, attempted_at)
+VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP);
+
+-- name: UpdateWebhookDeliveryResult :exec
+UPDATE webhook_deliveries
+SET status = $2,
+    attempts = attempts + 1,
+    next_attempt_at = sqlc.arg('next_attempt_at')::timestamptz,
+    last_response_status = $3,
+    last_error = $4,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: ResetWebhookSubscriptionFailures :exec
+UPDATE webhook_subscriptions
+SET consecutive_failures = 0
+WHERE id = $1 AND consecutive_failures > 0;
+
+-- name: RecordWebhookSubscriptionFailure :one
+-- Counts a delivery that ran out of attempts and disables the subscription
+-- once max_failures of them happened in a row.
+UPDATE webhook_subscriptions
+SET consecutive_failures = consecutive_failures + 1,
+    enabled = enabled AND consecutive_failures + 1 < sqlc.arg('max_failures')::int,
+    disabled_at = CASE
+        WHEN enabled AND consecutive_failures + 1 >= sqlc.arg('max_failures')::int THEN CURRENT_TIMESTAMP
+        ELSE disabled_at
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteFinishedWebhookDeliveries :execrows
+DELETE FROM webhook_deliveries
+WHERE status <> 'pending' AND updated_at < sqlc.arg('updated_before')::timestamptz;
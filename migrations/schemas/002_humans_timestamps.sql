@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE humans
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC';
+ALTER TABLE humans
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ DEFAULT now() NOT NULL;
+UPDATE humans SET updated_at = created_at;
+
+CREATE INDEX IF NOT EXISTS humans_created_at_idx ON humans (created_at, id);
+CREATE INDEX IF NOT EXISTS humans_updated_at_idx ON humans (updated_at, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS humans_updated_at_idx;
+DROP INDEX IF EXISTS humans_created_at_idx;
+ALTER TABLE humans DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE humans
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC';
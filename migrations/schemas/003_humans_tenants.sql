@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE humans
+    ADD COLUMN IF NOT EXISTS tenant_id TEXT DEFAULT 'default' NOT NULL;
+ALTER TABLE humans ALTER COLUMN tenant_id DROP DEFAULT;
+
+ALTER TABLE humans DROP CONSTRAINT IF EXISTS humans_name_key;
+ALTER TABLE humans ADD CONSTRAINT humans_tenant_name_key UNIQUE (tenant_id, name);
+
+DROP INDEX IF EXISTS humans_created_at_idx;
+DROP INDEX IF EXISTS humans_updated_at_idx;
+CREATE INDEX IF NOT EXISTS humans_tenant_created_at_idx ON humans (tenant_id, created_at, id);
+CREATE INDEX IF NOT EXISTS humans_tenant_updated_at_idx ON humans (tenant_id, updated_at, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS humans_tenant_updated_at_idx;
+DROP INDEX IF EXISTS humans_tenant_created_at_idx;
+CREATE INDEX IF NOT EXISTS humans_created_at_idx ON humans (created_at, id);
+CREATE INDEX IF NOT EXISTS humans_updated_at_idx ON humans (updated_at, id);
+
+ALTER TABLE humans DROP CONSTRAINT IF EXISTS humans_tenant_name_key;
+ALTER TABLE humans ADD CONSTRAINT humans_name_key UNIQUE (name);
+ALTER TABLE humans DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Statistics count the humans of one tenant grouped by gender, country or
+-- age. Age trails the country so the average age per country is read from
+-- the index alone.
+CREATE INDEX IF NOT EXISTS humans_tenant_gender_idx ON humans (tenant_id, gender);
+CREATE INDEX IF NOT EXISTS humans_tenant_country_age_idx ON humans (tenant_id, country, age);
+CREATE INDEX IF NOT EXISTS humans_tenant_age_idx ON humans (tenant_id, age);
+
+-- +goose Down
+DROP INDEX IF EXISTS humans_tenant_age_idx;
+DROP INDEX IF EXISTS humans_tenant_country_age_idx;
+DROP INDEX IF EXISTS humans_tenant_gender_idx;
@@ -0,0 +1,26 @@
+-- +goose Up
+ALTER TABLE humans ADD CONSTRAINT humans_tenant_id_id_key UNIQUE (tenant_id, id);
+
+CREATE TABLE IF NOT EXISTS human_relationships (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id TEXT NOT NULL,
+    human_id UUID NOT NULL,
+    relative_id UUID NOT NULL,
+    relation TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now() NOT NULL,
+    CONSTRAINT human_relationships_human_fk FOREIGN KEY (tenant_id, human_id)
+        REFERENCES humans (tenant_id, id) ON DELETE CASCADE,
+    CONSTRAINT human_relationships_relative_fk FOREIGN KEY (tenant_id, relative_id)
+        REFERENCES humans (tenant_id, id) ON DELETE CASCADE,
+    CONSTRAINT human_relationships_relation_check
+        CHECK (relation IN ('parent', 'child', 'spouse', 'sibling')),
+    CONSTRAINT human_relationships_no_self_link CHECK (human_id <> relative_id),
+    CONSTRAINT human_relationships_unique UNIQUE (tenant_id, human_id, relative_id, relation)
+);
+
+CREATE INDEX IF NOT EXISTS human_relationships_relative_idx
+    ON human_relationships (tenant_id, relative_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS human_relationships;
+ALTER TABLE humans DROP CONSTRAINT IF EXISTS humans_tenant_id_id_key;
@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS human_contacts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id TEXT NOT NULL,
+    human_id UUID NOT NULL,
+    type TEXT NOT NULL,
+    value TEXT NOT NULL,
+    is_primary BOOLEAN DEFAULT false NOT NULL,
+    street TEXT,
+    city TEXT,
+    region TEXT,
+    postal_code TEXT,
+    country TEXT,
+    created_at TIMESTAMPTZ DEFAULT now() NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT now() NOT NULL,
+    CONSTRAINT human_contacts_human_fk FOREIGN KEY (tenant_id, human_id)
+        REFERENCES humans (tenant_id, id) ON DELETE CASCADE,
+    CONSTRAINT human_contacts_type_check CHECK (type IN ('email', 'phone', 'address')),
+    CONSTRAINT human_contacts_unique UNIQUE (tenant_id, human_id, type, value)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS human_contacts_one_primary_idx
+    ON human_contacts (tenant_id, human_id, type) WHERE is_primary;
+
+-- +goose Down
+DROP TABLE IF EXISTS human_contacts;
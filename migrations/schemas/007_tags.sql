@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now() NOT NULL,
+    CONSTRAINT tags_tenant_name_key UNIQUE (tenant_id, name),
+    CONSTRAINT tags_tenant_id_id_key UNIQUE (tenant_id, id)
+);
+
+CREATE TABLE IF NOT EXISTS human_tags (
+    tenant_id TEXT NOT NULL,
+    human_id UUID NOT NULL,
+    tag_id UUID NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now() NOT NULL,
+    PRIMARY KEY (tenant_id, human_id, tag_id),
+    CONSTRAINT human_tags_human_fk FOREIGN KEY (tenant_id, human_id)
+        REFERENCES humans (tenant_id, id) ON DELETE CASCADE,
+    CONSTRAINT human_tags_tag_fk FOREIGN KEY (tenant_id, tag_id)
+        REFERENCES tags (tenant_id, id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS human_tags_tag_idx ON human_tags (tenant_id, tag_id, human_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS human_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,28 @@
+-- +goose Up
+ALTER TABLE humans ADD COLUMN IF NOT EXISTS attributes JSONB DEFAULT '{}'::jsonb NOT NULL;
+
+CREATE INDEX IF NOT EXISTS humans_attributes_idx ON humans USING GIN (attributes jsonb_path_ops);
+
+CREATE TABLE IF NOT EXISTS attribute_definitions (
+    tenant_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL,
+    required BOOLEAN DEFAULT false NOT NULL,
+    enum_values JSONB DEFAULT '[]'::jsonb NOT NULL,
+    pattern TEXT,
+    max_length INTEGER,
+    minimum DOUBLE PRECISION,
+    maximum DOUBLE PRECISION,
+    created_at TIMESTAMPTZ DEFAULT now() NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT now() NOT NULL,
+    PRIMARY KEY (tenant_id, name),
+    CONSTRAINT attribute_definitions_type_check CHECK (type IN ('string', 'number', 'integer', 'boolean', 'date')),
+    CONSTRAINT attribute_definitions_enum_check CHECK (jsonb_typeof(enum_values) = 'array'),
+    CONSTRAINT attribute_definitions_max_length_check CHECK (max_length IS NULL OR max_length > 0),
+    CONSTRAINT attribute_definitions_range_check CHECK (minimum IS NULL OR maximum IS NULL OR minimum <= maximum)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS attribute_definitions;
+DROP INDEX IF EXISTS humans_attributes_idx;
+ALTER TABLE humans DROP COLUMN IF EXISTS attributes;
@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id TEXT NOT NULL,
+    format TEXT NOT NULL,
+    status TEXT NOT NULL,
+    total_rows INTEGER NOT NULL,
+    processed_rows INTEGER DEFAULT 0 NOT NULL,
+    created_rows INTEGER DEFAULT 0 NOT NULL,
+    failed_rows INTEGER DEFAULT 0 NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now() NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT now() NOT NULL,
+    finished_at TIMESTAMPTZ,
+    CONSTRAINT import_jobs_tenant_id_id_key UNIQUE (tenant_id, id),
+    CONSTRAINT import_jobs_format_check CHECK (format IN ('csv', 'ndjson')),
+    CONSTRAINT import_jobs_status_check CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+CREATE TABLE IF NOT EXISTS import_job_errors (
+    tenant_id TEXT NOT NULL,
+    job_id UUID NOT NULL,
+    row_number INTEGER NOT NULL,
+    code TEXT NOT NULL,
+    message TEXT NOT NULL,
+    PRIMARY KEY (tenant_id, job_id, row_number),
+    CONSTRAINT import_job_errors_job_fk FOREIGN KEY (tenant_id, job_id)
+        REFERENCES import_jobs (tenant_id, id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS import_job_errors;
+DROP TABLE IF EXISTS import_jobs;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- The trigram index on names is created by 011, which can limit it to
+-- plaintext rows.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- +goose Down
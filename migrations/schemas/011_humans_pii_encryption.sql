@@ -0,0 +1,27 @@
+-- +goose Up
+-- Encrypted rows keep ciphertext in name, surname and patronymic. The data
+-- key that encrypts them is stored wrapped by the key named in pii_key_id;
+-- rows without one are still in plaintext. name_index is a keyed hash of
+-- the name that takes over enforcing unique names for encrypted rows; the
+-- server fills it in for plaintext rows on start when keys are enabled.
+ALTER TABLE humans
+    ADD COLUMN IF NOT EXISTS name_index BYTEA,
+    ADD COLUMN IF NOT EXISTS pii_key_id TEXT,
+    ADD COLUMN IF NOT EXISTS pii_dek BYTEA;
+
+ALTER TABLE humans ADD CONSTRAINT humans_tenant_name_index_key UNIQUE (tenant_id, name_index);
+
+-- Duplicate search compares names with pg_trgm only while they are in
+-- plaintext; trigrams of ciphertext are useless, so encrypted rows stay
+-- out of the index.
+CREATE INDEX IF NOT EXISTS humans_full_name_trgm_idx
+    ON humans USING GIN ((lower(name || ' ' || surname)) gin_trgm_ops)
+    WHERE pii_key_id IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS humans_full_name_trgm_idx;
+ALTER TABLE humans DROP CONSTRAINT IF EXISTS humans_tenant_name_index_key;
+ALTER TABLE humans
+    DROP COLUMN IF EXISTS pii_dek,
+    DROP COLUMN IF EXISTS pii_key_id,
+    DROP COLUMN IF EXISTS name_index;
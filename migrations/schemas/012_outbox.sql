@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL,
+    tenant_id TEXT NOT NULL,
+    human_id UUID NOT NULL,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INT DEFAULT 0 NOT NULL,
+    last_error TEXT,
+    created_at TIMESTAMPTZ DEFAULT now() NOT NULL,
+    delivered_at TIMESTAMPTZ,
+    CONSTRAINT outbox_event_id_key UNIQUE (event_id),
+    CONSTRAINT outbox_event_type_check
+        CHECK (event_type IN ('HumanCreated', 'HumanUpdated', 'HumanDeleted'))
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (id) WHERE delivered_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_delivered_idx ON outbox (delivered_at) WHERE delivered_at IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS outbox;
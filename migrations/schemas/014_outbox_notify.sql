@@ -0,0 +1,19 @@
+-- +goose Up
+-- Announces every outbox event to the API replicas on commit. The payload
+-- is the event's id; listeners read the event from the outbox.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_outbox_event() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('human_events', NEW.id::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER outbox_notify
+    AFTER INSERT ON outbox
+    FOR EACH ROW EXECUTE FUNCTION notify_outbox_event();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS outbox_notify ON outbox;
+DROP FUNCTION IF EXISTS notify_outbox_event();
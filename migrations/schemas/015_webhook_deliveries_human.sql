@@ -0,0 +1,14 @@
+-- +goose Up
+-- Deliveries remember their human, so that anonymizing a human can erase
+-- the payloads that still carry the original names.
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS human_id UUID;
+UPDATE webhook_deliveries SET human_id = (payload -> 'human' ->> 'id')::uuid WHERE human_id IS NULL;
+ALTER TABLE webhook_deliveries ALTER COLUMN human_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_human_idx ON webhook_deliveries (tenant_id, human_id);
+CREATE INDEX IF NOT EXISTS outbox_human_idx ON outbox (tenant_id, human_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS outbox_human_idx;
+DROP INDEX IF EXISTS webhook_deliveries_human_idx;
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS human_id;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Rejected fields of rows that failed validation, as {field, code, message}
+-- objects.
+ALTER TABLE import_job_errors ADD COLUMN IF NOT EXISTS fields JSONB NOT NULL DEFAULT '[]';
+
+-- +goose Down
+ALTER TABLE import_job_errors DROP COLUMN IF EXISTS fields;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- The tag filter shared by the humans list, export and statistics queries:
+-- a human passes when it has any of the wanted tags, or all of them when
+-- match_all is set. An empty filter passes everyone.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION human_tags_match(tenant_id TEXT, human_id UUID, wanted TEXT[], match_all BOOLEAN)
+RETURNS BOOLEAN AS $$
+    SELECT cardinality(wanted) = 0 OR (
+        SELECT count(DISTINCT tags.name)
+        FROM human_tags
+        JOIN tags ON tags.tenant_id = human_tags.tenant_id AND tags.id = human_tags.tag_id
+        WHERE human_tags.tenant_id = human_tags_match.tenant_id
+            AND human_tags.human_id = human_tags_match.human_id
+            AND tags.name = ANY(wanted)
+    ) >= CASE WHEN match_all THEN cardinality(wanted) ELSE 1 END
+$$ LANGUAGE sql STABLE PARALLEL SAFE;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION IF EXISTS human_tags_match(TEXT, UUID, TEXT[], BOOLEAN);